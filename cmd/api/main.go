@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
@@ -14,10 +15,21 @@ import (
 
 	"go-template/internal/adapters/primary/http/handlers"
 	"go-template/internal/adapters/primary/http/middleware"
+	"go-template/internal/adapters/secondary/dhl"
+	"go-template/internal/adapters/secondary/fakecarrier"
+	"go-template/internal/adapters/secondary/shipping"
+	"go-template/internal/modules/example/example_auth"
+	"go-template/internal/modules/example/example_order"
 	"go-template/internal/modules/example/example_user"
+	"go-template/internal/modules/example/example_webhook"
+	"go-template/pkg/auth"
 	"go-template/pkg/config"
 	"go-template/pkg/custom_errors"
+	"go-template/pkg/httpclient"
 	"go-template/pkg/logger"
+	"go-template/pkg/logsink"
+	"go-template/pkg/metrics"
+	"go-template/pkg/platform"
 	"go-template/pkg/platform/postgres"
 	"go-template/pkg/response"
 	"go-template/pkg/validator"
@@ -58,41 +70,116 @@ func main() {
 	}
 
 	// --- 2. สร้าง Logger ---
-	var appLogger logger.Logger
-	if cfg.Server.Mode == "development" {
-		appLogger = logger.NewPrettyLogger()
-	} else {
-		appLogger = logger.NewSlogLogger()
+	// ถ้าไม่ได้ระบุ format ไว้ ให้เลือกตาม server.mode เหมือนเดิม
+	if cfg.Logging.Format == "" {
+		if cfg.Server.Mode == "development" {
+			cfg.Logging.Format = logger.FormatPretty
+		} else {
+			cfg.Logging.Format = logger.FormatJSON
+		}
+	}
+	appLogger, logLevel, logCloser, err := logger.New(cfg.Logging)
+	if err != nil {
+		log.Fatalf("❌ Failed to initialize logger: %v", err)
 	}
-	appLogger.Info("Logger initialized", "mode", cfg.Server.Mode)
+	logger.SetDefault(appLogger)
+	appLogger.Info("Logger initialized", "mode", cfg.Server.Mode, "level", logger.LevelName(logLevel.Level()), "format", cfg.Logging.Format, "outputs", cfg.Logging.Outputs)
 
 	appValidator := validator.New()
 
+	if err := cfg.CORS.Validate(); err != nil {
+		appLogger.Error("Invalid CORS configuration", err)
+		os.Exit(1)
+	}
+
 	// --- 3. เชื่อมต่อ Platforms (Databases) ---
-	primaryDB, err := postgres.NewConnection(cfg.Postgres.Primary, appLogger)
+	// Registry เปิด connection ให้ตอนถูกขอครั้งแรก (database อื่นใน config ใช้ databases.Get("<ชื่อ>") ได้เลย)
+	databases := platform.NewRegistry(cfg.Postgres, cfg.Logging, appLogger)
+	primaryDB, err := databases.Get(config.PostgresPrimary)
 	if err != nil {
 		appLogger.Error("Failed to connect to primary database", err)
 		os.Exit(1)
 	}
 	// เชื่อมต่อ Database สำหรับเก็บ Logs (ถ้ามีการตั้งค่า)
 	var logsDB *gorm.DB
-	if cfg.Postgres.Logs.Host != "" {
-		logsDB, err = postgres.NewConnection(cfg.Postgres.Logs, appLogger)
+	if databases.Has(config.PostgresLogs) {
+		logsDB, err = databases.Get(config.PostgresLogs)
 		if err != nil {
 			appLogger.Warn("Logs database configured but unavailable", "error", err)
 			logsDB = nil
 		}
 	}
 
+	// สำเนา log และ HTTP access record ลง Logs Database (ถ้าเปิดไว้และเชื่อมต่อได้)
+	// ⭐️ Sink ใช้ Logger ตัวเดิม (ก่อนห่อ) รายงานปัญหาของตัวเอง เพื่อไม่ให้ error วนกลับเข้า Sink
+	var logSink *logsink.Sink
+	if cfg.Logging.Database.Enabled {
+		if logsDB == nil {
+			appLogger.Warn("Log database sink is enabled but the logs database is not available, skipping")
+		} else {
+			logSink, err = logsink.New(logsDB, cfg.Logging.Database, logger.NewRedactor(cfg.Logging.Redaction), appLogger)
+			if err != nil {
+				appLogger.Error("Failed to initialize log database sink", err)
+				os.Exit(1)
+			}
+			appLogger = logSink.Logger(appLogger)
+			logger.SetDefault(appLogger)
+			appLogger.Info("Log database sink enabled", "minLevel", cfg.Logging.Database.MinLevel, "accessLog", cfg.Logging.Database.AccessLog)
+		}
+	}
+
 	// --- 4. ประกอบร่าง Modules (Dependency Injection) ---
-	_ = logsDB // ป้องกัน unused variable
 
-	healthHandler := handlers.NewHealthHandler(primaryDB)
+	healthHandler := handlers.NewHealthHandler(databases, config.PostgresPrimary)
+
+	authService, err := auth.NewAuthService(authOptions(cfg.Auth))
+	if err != nil {
+		appLogger.Error("Failed to initialize auth service", err)
+		os.Exit(1)
+	}
+	rbac := auth.NewRBAC(cfg.Auth.Permissions)
+	jwksHandler := handlers.NewJWKSHandler(authService)
+	loggingHandler := handlers.NewLoggingHandler(logLevel, appLogger)
+
+	// ขนส่ง: ลงทะเบียนทุกเจ้าที่เปิดใช้งานไว้ใน Registry (Order เลือกเจ้าไหนก็ได้ตามชื่อ)
+	var shippingProviders []shipping.Provider
+	if cfg.Shipping.DHL.Enabled {
+		dhlAdapter := dhl.NewDHLAdapter(cfg.Shipping.DHL.BaseURL, cfg.Shipping.DHL.APIKey, httpclient.New(dhl.ProviderName, cfg.Shipping.DHL.HTTP, appLogger))
+		shippingProviders = append(shippingProviders, dhl.NewShippingProvider(dhlAdapter))
+	}
+	if cfg.Shipping.Fake.Enabled {
+		shippingProviders = append(shippingProviders, fakecarrier.New(cfg.Shipping.Fake))
+	}
+	shippingRegistry, err := shipping.NewRegistry(cfg.Shipping.DefaultProvider, shippingProviders...)
+	if err != nil {
+		appLogger.Error("Failed to initialize shipping providers", err)
+		os.Exit(1)
+	}
+
+	// Unit of Work: Service ใช้ครอบการเรียกหลาย Repository ให้อยู่ใน Transaction เดียวกัน
+	txManager := postgres.NewTxManager(primaryDB)
 
 	exampleUserRepo := example_user.NewExampleRepository(primaryDB, appLogger)
-	exampleUserService := example_user.NewExampleUserService(exampleUserRepo, cfg.Auth.JWTSecret, appLogger)
+
+	exampleAuthRepo := example_auth.NewExampleAuthRepository(primaryDB, appLogger)
+	exampleAuthService := example_auth.NewExampleAuthService(exampleAuthRepo, authService, example_user.NewSubjectProvider(exampleUserRepo), cfg.Auth.RefreshTokenTTL, appLogger)
+	exampleAuthHandler := example_auth.NewExampleAuthHandler(exampleAuthService, appLogger, bangkokLocation, appValidator)
+
+	exampleUserService := example_user.NewExampleUserService(exampleUserRepo, txManager, exampleAuthService, rbac, appLogger)
 	exampleUserHandler := example_user.NewExampleUserHandler(exampleUserService, appLogger, bangkokLocation, appValidator)
 
+	exampleOrderRepo := example_order.NewExampleOrderRepository(primaryDB, appLogger)
+	exampleOrderService := example_order.NewExampleOrderService(exampleOrderRepo, rbac, example_order.NewOrderNumberGenerator(bangkokLocation), shippingRegistry, appLogger)
+	exampleOrderHandler := example_order.NewExampleOrderHandler(exampleOrderService, appLogger, bangkokLocation, appValidator)
+
+	webhookSecrets := map[string]string{
+		dhl.ProviderName:         cfg.Shipping.DHL.WebhookSecret,
+		fakecarrier.ProviderName: cfg.Shipping.Fake.WebhookSecret,
+	}
+	exampleWebhookRepo := example_webhook.NewExampleWebhookRepository(primaryDB, appLogger)
+	exampleWebhookService := example_webhook.NewExampleWebhookService(exampleWebhookRepo, shippingRegistry, exampleOrderService, webhookSecrets, cfg.Shipping.WebhookTolerance, rbac, appLogger)
+	exampleWebhookHandler := example_webhook.NewExampleWebhookHandler(exampleWebhookService, appLogger, bangkokLocation, appValidator)
+
 	// --- 5. ตั้งค่า Web Server (Fiber) ---
 	app := fiber.New(fiber.Config{
 		AppName: fmt.Sprintf("%s %s", cfg.App.Name, AppVersion),
@@ -101,20 +188,37 @@ func main() {
 				return response.Error(c, appErr)
 			}
 			systemErr := custom_errors.SystemErrorWithDetails("An unexpected error occurred", err.Error())
-			appLogger.Error("Unhandled error has occurred", systemErr)
+			appLogger.WithContext(c).Error("Unhandled error has occurred", systemErr)
 			return response.Error(c, systemErr)
 		},
 	})
 
 	// --- 6. ติดตั้ง Middlewares & Routes ---
+	app.Use(middleware.RequestID())
+	app.Use(middleware.DBSession())
 	app.Use(middleware.Logger(appLogger))
-	app.Use(middleware.CORS())
+	if logSink != nil && logSink.AccessLogEnabled() {
+		app.Use(middleware.AccessLog(logSink))
+	}
+	app.Use(middleware.CORS(cfg.CORS))
 
 	healthHandler.RegisterRoutes(app)
+	jwksHandler.RegisterRoutes(app)
+	if cfg.Metrics.Enabled {
+		metricsPath := cfg.Metrics.Path
+		if metricsPath == "" {
+			metricsPath = metrics.DefaultPath
+		}
+		app.Get(metricsPath, metrics.Handler())
+	}
 
 	apiV1 := app.Group("/api/v1")
+	loggingHandler.RegisterRoutes(apiV1, middleware.JWTAuth(authService))
 	example := apiV1.Group("/example")
-	exampleUserHandler.RegisterRoutes(example)
+	exampleUserHandler.RegisterRoutes(example, middleware.JWTAuth(authService), rbac)
+	exampleAuthHandler.RegisterRoutes(example)
+	exampleOrderHandler.RegisterRoutes(example, middleware.JWTAuth(authService))
+	exampleWebhookHandler.RegisterRoutes(example, middleware.JWTAuth(authService), rbac)
 
 	// --- 7. เริ่มและปิดการทำงานของ Server ---
 	go func() {
@@ -150,4 +254,38 @@ func main() {
 	}
 
 	appLogger.Info("Server gracefully stopped")
+
+	// เขียน log ที่ค้างอยู่ใน buffer ลง DB ให้หมดก่อนปิดโปรแกรม
+	if logSink != nil {
+		flushCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		if err := logSink.Close(flushCtx); err != nil {
+			log.Printf("Failed to flush log database sink: %v", err)
+		}
+		cancel()
+	}
+	if err := databases.Close(); err != nil {
+		log.Printf("Failed to close database connections: %v", err)
+	}
+	if err := logCloser.Close(); err != nil {
+		log.Printf("Failed to close log output: %v", err)
+	}
+}
+
+// authOptions แปลงค่า auth ใน config เป็น auth.Options (pkg/auth ไม่ขึ้นกับ pkg/config)
+func authOptions(cfg config.AuthConfig) auth.Options {
+	signingKeys := make([]auth.SigningKeyOptions, 0, len(cfg.SigningKeys))
+	for _, key := range cfg.SigningKeys {
+		signingKeys = append(signingKeys, auth.SigningKeyOptions{
+			KeyID:          key.KeyID,
+			Algorithm:      key.Algorithm,
+			PrivateKeyFile: key.PrivateKeyFile,
+			PublicKeyFile:  key.PublicKeyFile,
+		})
+	}
+	return auth.Options{
+		JWTSecret:      cfg.JWTSecret,
+		AccessTokenTTL: cfg.AccessTokenTTL,
+		ActiveKeyID:    cfg.ActiveKeyID,
+		SigningKeys:    signingKeys,
+	}
 }
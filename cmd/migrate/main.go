@@ -5,6 +5,7 @@ import (
 	"flag"
 	"fmt"
 	"log"
+	"strings"
 
 	"github.com/golang-migrate/migrate/v4"
 	_ "github.com/golang-migrate/migrate/v4/database/postgres" // Driver สำหรับ PostgreSQL
@@ -23,7 +24,7 @@ func main() {
 
 	// 2. รับคำสั่งจาก Command Line (Flags)
 	var dbName, migrationPath, action string
-	flag.StringVar(&dbName, "db", "", "Name of the database to migrate (e.g., primary, logs)")
+	flag.StringVar(&dbName, "db", "", "Name of the database to migrate, as configured under postgres.<name> (e.g., primary, logs)")
 	flag.StringVar(&migrationPath, "path", "", "Path to the migration files (e.g., db/migrations/primary)")
 	flag.StringVar(&action, "action", "up", "Migration action: up or down")
 	flag.Parse()
@@ -41,16 +42,10 @@ func main() {
 		log.Fatalf("❌ Could not load config: %v", err)
 	}
 
-	// 4. เลือก Connection String (DSN) ที่ถูกต้องตาม Flag ที่ได้รับมา
-	var dsn string
-	switch dbName {
-	case "primary":
-		dsn = cfg.Postgres.Primary.BuildDSN()
-	case "logs":
-		dsn = cfg.Postgres.Logs.BuildDSN()
-	// ในอนาคตถ้ามี DB อื่นๆ ก็มาเพิ่ม case ที่นี่
-	default:
-		log.Fatalf("❌ Unknown database name: '%s'. Must be one of 'primary', 'logs'", dbName)
+	// 4. เลือก Connection String (DSN) ตามชื่อ database ใน config (postgres.<ชื่อ>)
+	dsn, err := databaseDSN(cfg.Postgres, dbName)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
 	}
 
 	log.Printf("📁 Using migration files from: '%s'", migrationPath)
@@ -85,3 +80,13 @@ func main() {
 		log.Println("✅ Database migration completed successfully!")
 	}
 }
+
+// databaseDSN คืน DSN ของ database ที่ชื่อตรงกับ postgres.<ชื่อ> ใน config (ไม่สนตัวพิมพ์)
+// เพิ่ม DB ใหม่แค่ประกาศไว้ใน config.yml ไม่ต้องแก้ไฟล์นี้
+func databaseDSN(dbs config.PostgresDbs, name string) (string, error) {
+	dbConfig, ok := dbs[strings.ToLower(name)]
+	if !ok || !dbConfig.IsConfigured() {
+		return "", fmt.Errorf("unknown database name: '%s'. Must be one of: %s", name, strings.Join(dbs.Configured(), ", "))
+	}
+	return dbConfig.BuildDSN(), nil
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"go-template/pkg/config"
+)
+
+func TestDatabaseDSN(t *testing.T) {
+	dbs := config.PostgresDbs{
+		"primary":   {Host: "db-primary", Port: "5432", User: "app", DBName: "app", SSLMode: "disable"},
+		"logs":      {Host: "db-logs", Port: "5432", User: "app", DBName: "logs", SSLMode: "disable"},
+		"analytics": {Host: "db-analytics", Port: "5432", User: "app", DBName: "analytics", SSLMode: "disable"},
+		"archive":   {}, // ไม่มี host = ไม่ได้ตั้งค่า
+	}
+
+	tests := []struct {
+		name     string
+		wantHost string
+		wantErr  bool
+	}{
+		{name: "primary", wantHost: "db-primary"},
+		{name: "logs", wantHost: "db-logs"},
+		{name: "analytics", wantHost: "db-analytics"}, // ชื่อที่โค้ดไม่ได้รู้จักล่วงหน้าก็ใช้ได้
+		{name: "Analytics", wantHost: "db-analytics"},
+		{name: "archive", wantErr: true},
+		{name: "unknown", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dsn, err := databaseDSN(dbs, tt.name)
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "analytics, logs, primary") {
+					t.Errorf("databaseDSN(%q) error = %v, want one listing the configured names", tt.name, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("databaseDSN(%q) = %v", tt.name, err)
+			}
+			if !strings.Contains(dsn, "@"+tt.wantHost+":5432/") {
+				t.Errorf("databaseDSN(%q) = %q, want host %s", tt.name, dsn, tt.wantHost)
+			}
+		})
+	}
+}
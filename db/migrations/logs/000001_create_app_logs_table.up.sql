@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS "app_logs" (
+    "id" BIGSERIAL PRIMARY KEY,
+    "logged_at" TIMESTAMPTZ NOT NULL, -- เวลาที่เกิด log จริง (ไม่ใช่เวลาที่ batch ถูกเขียนลง DB)
+    "created_at" TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    "level" VARCHAR(10) NOT NULL,
+    "message" TEXT NOT NULL,
+    "request_id" VARCHAR(128),
+    "user_id" BIGINT,
+    "error" TEXT,
+    "attrs" JSONB NOT NULL DEFAULT '{}'::jsonb,
+
+    CONSTRAINT check_app_log_level CHECK (level IN ('DEBUG', 'INFO', 'WARN', 'ERROR'))
+);
+
+CREATE INDEX IF NOT EXISTS "idx_app_logs_logged_at" ON "app_logs" ("logged_at");
+CREATE INDEX IF NOT EXISTS "idx_app_logs_request_id" ON "app_logs" ("request_id");
+CREATE INDEX IF NOT EXISTS "idx_app_logs_level_logged_at" ON "app_logs" ("level", "logged_at");
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS "http_access_logs" (
+    "id" BIGSERIAL PRIMARY KEY,
+    "logged_at" TIMESTAMPTZ NOT NULL,
+    "created_at" TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    "request_id" VARCHAR(128),
+    "user_id" BIGINT,
+    "method" VARCHAR(10) NOT NULL,
+    "path" TEXT NOT NULL,
+    "status" INT NOT NULL,
+    "latency_ms" DOUBLE PRECISION NOT NULL,
+    "ip" VARCHAR(45),
+    "user_agent" TEXT
+);
+
+CREATE INDEX IF NOT EXISTS "idx_http_access_logs_logged_at" ON "http_access_logs" ("logged_at");
+CREATE INDEX IF NOT EXISTS "idx_http_access_logs_request_id" ON "http_access_logs" ("request_id");
+CREATE INDEX IF NOT EXISTS "idx_http_access_logs_user_id" ON "http_access_logs" ("user_id");
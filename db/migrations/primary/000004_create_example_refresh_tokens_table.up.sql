@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS "example_refresh_tokens" (
+    "id" BIGSERIAL PRIMARY KEY,
+    "created_at" TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    "updated_at" TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    "deleted_at" TIMESTAMPTZ,
+    "user_id" BIGINT NOT NULL,
+    "family_id" VARCHAR(64) NOT NULL,
+    "token_hash" VARCHAR(64) UNIQUE NOT NULL, -- เก็บแค่ SHA-256 ของ token ไม่เก็บ token ดิบ
+    "expires_at" TIMESTAMPTZ NOT NULL,
+    "revoked_at" TIMESTAMPTZ,
+    "replaced_by_id" BIGINT, -- token ใหม่ที่มาแทนตอน rotate (ใช้ไล่ดูประวัติของ family)
+
+    CONSTRAINT fk_user
+        FOREIGN KEY(user_id)
+        REFERENCES example_users(id)
+        ON DELETE CASCADE -- ถ้า User ถูกลบ ให้ลบ Token ทั้งหมดของเขาไปด้วย
+);
+
+CREATE INDEX IF NOT EXISTS "idx_example_refresh_tokens_user_id" ON "example_refresh_tokens" ("user_id");
+CREATE INDEX IF NOT EXISTS "idx_example_refresh_tokens_family_id" ON "example_refresh_tokens" ("family_id");
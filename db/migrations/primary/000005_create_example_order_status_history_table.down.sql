@@ -0,0 +1 @@
+DROP TABLE IF EXISTS "example_order_status_history";
\ No newline at end of file
@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS "example_order_status_history" (
+    "id" BIGSERIAL PRIMARY KEY,
+    "created_at" TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    "order_id" BIGINT NOT NULL,
+    "from_status" VARCHAR(50), -- NULL = ตอนสร้าง Order
+    "to_status" VARCHAR(50) NOT NULL,
+    "actor_id" BIGINT, -- NULL = ระบบเป็นคนเปลี่ยน (เช่น webhook จากขนส่ง)
+    "actor_role" VARCHAR(50),
+    "reason" VARCHAR(500),
+
+    CONSTRAINT fk_order
+        FOREIGN KEY(order_id)
+        REFERENCES example_orders(id)
+        ON DELETE CASCADE, -- ประวัติไม่มีความหมายถ้าไม่มี Order แล้ว
+
+    CONSTRAINT check_history_to_status CHECK (to_status IN ('pending', 'processing', 'shipped', 'completed', 'cancelled', 'refunded'))
+);
+
+-- ประวัติเป็นแบบ append-only: ไม่มี updated_at / deleted_at
+CREATE INDEX IF NOT EXISTS "idx_example_order_status_history_order_id" ON "example_order_status_history" ("order_id", "created_at");
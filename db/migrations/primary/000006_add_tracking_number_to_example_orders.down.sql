@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS "idx_example_orders_tracking_number";
+ALTER TABLE "example_orders" DROP CONSTRAINT IF EXISTS check_shipped_has_tracking_number;
+ALTER TABLE "example_orders" DROP COLUMN IF EXISTS "tracking_number";
@@ -0,0 +1,8 @@
+ALTER TABLE "example_orders" ADD COLUMN IF NOT EXISTS "tracking_number" VARCHAR(100);
+
+-- Order ที่ถูกส่งออกไปแล้ว (shipped/completed) ต้องมีเลขพัสดุเสมอ
+-- ใช้ NOT VALID เพื่อไม่ให้ข้อมูลเก่าที่ยังไม่มีเลขพัสดุทำให้ migration ล้ม
+ALTER TABLE "example_orders" ADD CONSTRAINT check_shipped_has_tracking_number
+    CHECK (status NOT IN ('shipped', 'completed') OR tracking_number IS NOT NULL) NOT VALID;
+
+CREATE INDEX IF NOT EXISTS "idx_example_orders_tracking_number" ON "example_orders" ("tracking_number");
@@ -0,0 +1 @@
+ALTER TABLE "example_orders" DROP COLUMN IF EXISTS "shipping_provider";
@@ -0,0 +1,3 @@
+-- ขนส่งที่รับผิดชอบ Order นี้ (ชื่อเดียวกับที่ลงทะเบียนใน shipping.Registry เช่น 'dhl', 'fake')
+-- NULL = Order เก่าก่อนมีคอลัมน์นี้ ระบบจะใช้ขนส่งเริ่มต้นจาก config แทน
+ALTER TABLE "example_orders" ADD COLUMN IF NOT EXISTS "shipping_provider" VARCHAR(50);
@@ -0,0 +1,2 @@
+ALTER TABLE "example_orders" DROP COLUMN IF EXISTS "shipment_updated_at";
+ALTER TABLE "example_orders" DROP COLUMN IF EXISTS "shipment_status";
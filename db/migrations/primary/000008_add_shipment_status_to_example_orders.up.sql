@@ -0,0 +1,4 @@
+-- สถานะพัสดุล่าสุดที่ได้จากขนส่ง (pre_transit, in_transit, delivered, ...) แยกจาก status ของ Order
+ALTER TABLE "example_orders" ADD COLUMN IF NOT EXISTS "shipment_status" VARCHAR(50);
+-- เวลาที่เหตุการณ์ล่าสุดเกิดขึ้นฝั่งขนส่ง (ใช้ทิ้งเหตุการณ์ที่มาถึงช้ากว่าเหตุการณ์ที่ใหม่กว่า)
+ALTER TABLE "example_orders" ADD COLUMN IF NOT EXISTS "shipment_updated_at" TIMESTAMPTZ;
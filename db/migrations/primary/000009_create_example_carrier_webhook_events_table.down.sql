@@ -0,0 +1 @@
+DROP TABLE IF EXISTS "example_carrier_webhook_events";
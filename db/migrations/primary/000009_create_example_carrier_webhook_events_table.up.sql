@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS "example_carrier_webhook_events" (
+    "id" BIGSERIAL PRIMARY KEY,
+    "created_at" TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    "updated_at" TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    "provider" VARCHAR(50) NOT NULL,
+    "event_id" VARCHAR(255) NOT NULL,
+    "tracking_number" VARCHAR(100),
+    "payload" TEXT NOT NULL, -- เก็บ body ดิบตามที่ได้รับทุกไบต์ เพื่อให้ replay ได้เหมือนเดิม
+    "status" VARCHAR(20) NOT NULL DEFAULT 'received', -- processing = มีคำขอหนึ่ง "จอง" event นี้ไปประมวลผลอยู่
+    "attempts" INT NOT NULL DEFAULT 0,
+    "last_error" TEXT,
+    "processed_at" TIMESTAMPTZ,
+
+    CONSTRAINT unique_provider_event UNIQUE (provider, event_id), -- กันประมวลผลเหตุการณ์ซ้ำ
+    CONSTRAINT check_webhook_event_status CHECK (status IN ('received', 'processing', 'processed', 'failed'))
+);
+
+CREATE INDEX IF NOT EXISTS "idx_example_carrier_webhook_events_status" ON "example_carrier_webhook_events" ("status");
+CREATE INDEX IF NOT EXISTS "idx_example_carrier_webhook_events_tracking_number" ON "example_carrier_webhook_events" ("tracking_number");
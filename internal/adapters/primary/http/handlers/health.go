@@ -2,12 +2,11 @@ package handlers
 
 import (
 	"context"
-	"time"
 
+	"go-template/pkg/platform"
 	"go-template/pkg/response"
 
 	"github.com/gofiber/fiber/v3"
-	"gorm.io/gorm"
 )
 
 // ⭐️ 1. สร้าง "พิมพ์เขียว" (Structs) สำหรับ Health Response โดยเฉพาะ ⭐️
@@ -18,32 +17,46 @@ type HealthResponse struct {
 }
 
 type DependencyStatus struct {
+	// Database คือสถานะของ database หลัก (ตัวที่ถ้าล่มแล้วระบบใช้งานไม่ได้)
 	Database string `json:"database"`
+	// Databases คือสถานะของทุก connection ใน config (not_connected = ยังไม่เคยถูกใช้)
+	Databases []platform.DatabaseHealth `json:"databases"`
 }
 
 // HealthHandler handles health check endpoints
 type HealthHandler struct {
-	db *gorm.DB
+	databases *platform.Registry
+	critical  map[string]bool
+	primary   string
 }
 
 // NewHealthHandler creates a new instance of HealthHandler
-func NewHealthHandler(db *gorm.DB) *HealthHandler {
-	return &HealthHandler{db: db}
+// critical คือชื่อ database ที่ถ้าล่มจะตอบ 503 (ตัวอื่นล่มจะแค่แสดงสถานะ error ไว้)
+// ตัวแรกใน critical จะถูกแสดงเป็น field "database" ด้วย
+func NewHealthHandler(databases *platform.Registry, critical ...string) *HealthHandler {
+	h := &HealthHandler{databases: databases, critical: make(map[string]bool, len(critical))}
+	for _, name := range critical {
+		h.critical[name] = true
+	}
+	if len(critical) > 0 {
+		h.primary = critical[0]
+	}
+	return h
 }
 
 // HealthCheck handles GET /health
 func (h *HealthHandler) HealthCheck(c fiber.Ctx) error {
-	dbStatus := "ok"
+	// Ping ทุก DB ที่เปิดอยู่พร้อมกัน (ตัวละไม่เกิน 1 วินาที)
+	databases := h.databases.Health(context.Background())
 
-	// พยายาม Ping DB
-	sqlDB, err := h.db.DB()
-	if err != nil {
-		dbStatus = "error"
-	} else {
-		ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
-		defer cancel()
-		if err := sqlDB.PingContext(ctx); err != nil {
-			dbStatus = "error"
+	dbStatus := platform.StatusNotConnected
+	healthy := true
+	for _, db := range databases {
+		if db.Name == h.primary {
+			dbStatus = db.Status
+		}
+		if h.critical[db.Name] && db.Status != platform.StatusOK {
+			healthy = false
 		}
 	}
 
@@ -52,12 +65,13 @@ func (h *HealthHandler) HealthCheck(c fiber.Ctx) error {
 		Status:  "ok",
 		Service: "go-template-api", // (อาจจะดึงมาจาก config ก็ได้นะ)
 		Dependencies: DependencyStatus{
-			Database: dbStatus,
+			Database:  dbStatus,
+			Databases: databases,
 		},
 	}
 
-	// ถ้า DB มีปัญหา ให้ตอบกลับด้วย Status 503
-	if dbStatus == "error" {
+	// ถ้า DB หลักมีปัญหา ให้ตอบกลับด้วย Status 503
+	if !healthy {
 		healthData.Status = "error"
 		// ⭐️ 3. เรียกใช้ response.Success แม้กระทั่งตอน Error! ⭐️
 		// เพราะเรายังอยากให้โครงสร้างเป็น {"data": ...} แต่บอกสถานะว่า error
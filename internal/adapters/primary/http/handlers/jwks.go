@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"go-template/pkg/auth"
+	"go-template/pkg/custom_errors"
+	"go-template/pkg/response"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// JWKSHandler publishes the public keys used to verify our access tokens
+type JWKSHandler struct {
+	authService *auth.AuthService
+}
+
+// NewJWKSHandler creates a new instance of JWKSHandler
+func NewJWKSHandler(authService *auth.AuthService) *JWKSHandler {
+	return &JWKSHandler{authService: authService}
+}
+
+// JWKS handles GET /.well-known/jwks.json
+// ⭐️ endpoint นี้ "ไม่" ห่อด้วย response.Success เพราะไลบรารี JWT ของ service อื่น
+// คาดหวังรูปแบบมาตรฐาน {"keys": [...]} ตาม RFC 7517 เป๊ะๆ
+func (h *JWKSHandler) JWKS(c fiber.Ctx) error {
+	set, err := h.authService.JWKS()
+	if err != nil {
+		return response.Error(c, custom_errors.SystemErrorWithDetails("ไม่สามารถสร้าง JWKS ได้", err.Error()))
+	}
+
+	// ให้ Client cache ได้สั้นๆ เพื่อให้กุญแจใหม่กระจายออกไปเร็วตอน rotate
+	c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	return c.Status(fiber.StatusOK).JSON(set)
+}
+
+// RegisterRoutes registers JWKS routes
+func (h *JWKSHandler) RegisterRoutes(app fiber.Router) {
+	app.Get("/.well-known/jwks.json", h.JWKS)
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"log/slog"
+
+	"go-template/internal/adapters/primary/http/middleware"
+	"go-template/pkg/auth"
+	"go-template/pkg/custom_errors"
+	"go-template/pkg/logger"
+	"go-template/pkg/response"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// LogLevelRequest คือ body ของ PUT /admin/logging/level
+type LogLevelRequest struct {
+	Level string `json:"level"`
+}
+
+// LogLevelResponse คือระดับ log ปัจจุบันของระบบ
+type LogLevelResponse struct {
+	Level string `json:"level"`
+}
+
+// LoggingHandler lets admins inspect and change the log level at runtime
+type LoggingHandler struct {
+	level *slog.LevelVar
+	log   logger.Logger
+}
+
+// NewLoggingHandler creates a new instance of LoggingHandler
+// level ต้องเป็นตัวเดียวกับที่ logger.New คืนมา ไม่งั้นการเปลี่ยนระดับจะไม่มีผล
+func NewLoggingHandler(level *slog.LevelVar, log logger.Logger) *LoggingHandler {
+	return &LoggingHandler{level: level, log: log}
+}
+
+// GetLevel handles GET /admin/logging/level
+func (h *LoggingHandler) GetLevel(c fiber.Ctx) error {
+	return response.Success(c, fiber.StatusOK, "Log level retrieved", LogLevelResponse{Level: logger.LevelName(h.level.Level())}, nil)
+}
+
+// SetLevel handles PUT /admin/logging/level
+// มีผลทันทีกับ Logger ทุกตัว (รวม Logger ลูกที่สร้างผ่าน With/WithContext) และกลับเป็นค่าใน config เมื่อ restart
+func (h *LoggingHandler) SetLevel(c fiber.Ctx) error {
+	req := new(LogLevelRequest)
+	if err := c.Bind().Body(req); err != nil {
+		return response.Error(c, custom_errors.InvalidFormatError("Request body is not valid JSON", err.Error()))
+	}
+	if req.Level == "" {
+		return response.Error(c, custom_errors.ValidationError("ข้อมูลที่ส่งมาไม่ถูกต้อง", "level is required (debug, info, warn, error)"))
+	}
+	level, err := logger.ParseLevel(req.Level)
+	if err != nil {
+		return response.Error(c, custom_errors.ValidationError("ระดับ log ไม่ถูกต้อง", err.Error()))
+	}
+
+	previous := h.level.Level()
+	h.level.Set(level)
+	// ⭐️ ใช้ Warn เพื่อให้การเปลี่ยนนี้ถูกบันทึกไว้ แม้ production จะตั้งระดับไว้สูงกว่า info
+	h.log.WithContext(c).Warn("Log level changed", "from", logger.LevelName(previous), "to", logger.LevelName(level))
+
+	return response.Success(c, fiber.StatusOK, "Log level updated", LogLevelResponse{Level: logger.LevelName(level)}, nil)
+}
+
+// RegisterRoutes registers admin logging routes (admin เท่านั้น)
+func (h *LoggingHandler) RegisterRoutes(router fiber.Router, authMiddleware fiber.Handler) {
+	admin := router.Group("/admin/logging", authMiddleware, middleware.RequireRole(auth.RoleAdmin))
+	admin.Get("/level", h.GetLevel)
+	admin.Put("/level", h.SetLevel)
+}
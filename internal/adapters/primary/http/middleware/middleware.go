@@ -1,13 +1,54 @@
 package middleware
 
 import (
+	"errors"
+	"sort"
+	"strings"
 	"time"
 
+	"go-template/pkg/auth"
+	"go-template/pkg/config"
+	"go-template/pkg/custom_errors"
 	"go-template/pkg/logger"
+	"go-template/pkg/logsink"
+	"go-template/pkg/platform/postgres"
+	"go-template/pkg/requestid"
+	"go-template/pkg/response"
 
 	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/middleware/cors"
+	"github.com/golang-jwt/jwt/v5"
 )
 
+// RequestID is a middleware that accepts or creates an X-Request-ID for every request.
+// ถ้า client ส่ง X-Request-ID ที่ถูกรูปแบบมาจะใช้ค่านั้นต่อ ไม่งั้นจะสร้างใหม่
+// แล้วฝาก Request ID ไว้กับ request พร้อมเป็น field "request_id" ของ log
+// (ดึงใช้ได้ผ่าน requestid.FromContext และ log.WithContext(ctx)) พร้อมส่ง header กลับไปให้ client
+// ต้องติดตั้งเป็นตัวแรกสุด เพื่อให้ log และ error response ทุกตัวมี request_id
+func RequestID() fiber.Handler {
+	return func(c fiber.Ctx) error {
+		id := c.Get(requestid.Header)
+		if !requestid.IsValid(id) {
+			id = requestid.New()
+		}
+
+		c.Locals(requestid.ContextKey, id)
+		c.Locals(logger.FieldsContextKey, logger.AppendFields(c, "request_id", id))
+		c.Set(requestid.Header, id)
+		return c.Next()
+	}
+}
+
+// DBSession is a middleware that gives every request its own postgres.Session.
+// เมื่อ request เขียนลง DB แล้ว การอ่านที่ตามมาใน request เดียวกันจะใช้ primary แทน read replica
+// (ได้ผลเฉพาะ query ที่ส่ง fiber.Ctx ต่อลงไปเป็น ctx ของ db.WithContext)
+func DBSession() fiber.Handler {
+	return func(c fiber.Ctx) error {
+		c.Locals(postgres.SessionContextKey, postgres.NewSession())
+		return c.Next()
+	}
+}
+
 // Logger is a middleware that logs HTTP requests.
 // ✨ 2. แก้ไขให้รับ "นักข่าว" (Logger) เข้ามา ✨
 func Logger(log logger.Logger) fiber.Handler {
@@ -21,10 +62,11 @@ func Logger(log logger.Logger) fiber.Handler {
 		latency := stop.Sub(start)
 
 		// ✨ 3. ใช้ "นักข่าว" ของเราบันทึก Log! ✨
-		log.Info("Request handled",
+		// ⭐️ WithContext อ่านหลัง c.Next() เพื่อให้ได้ทั้ง request_id และ user_id (ถ้าผ่าน JWTAuth มา)
+		log.WithContext(c).Info("Request handled",
 			"method", c.Method(),
 			"path", c.Path(),
-			"status", c.Response().StatusCode(),
+			"status", responseStatus(c, err),
 			"latency", latency.String(),
 			"ip", c.IP(),
 		)
@@ -33,11 +75,162 @@ func Logger(log logger.Logger) fiber.Handler {
 	}
 }
 
-// CORS is a middleware for Cross-Origin Resource Sharing
-func CORS() fiber.Handler {
-	// ... (โค้ดส่วนนี้เหมือนเดิม) ...
+// AccessRecorder คือปลายทางที่เก็บ HTTP access record (เช่น logsink.Sink ที่เขียนลง Logs Database)
+type AccessRecorder interface {
+	RecordAccess(rec *logsink.AccessRecord)
+}
+
+// AccessLog is a middleware that hands every HTTP request to an AccessRecorder.
+// recorder ต้องไม่รอ I/O (Sink แค่ใส่ลง buffer) เพื่อไม่ให้ request ช้าลง
+func AccessLog(recorder AccessRecorder) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		start := time.Now()
+		err := c.Next()
+
+		rec := &logsink.AccessRecord{
+			Time:      start,
+			RequestID: requestid.FromContext(c),
+			Method:    c.Method(),
+			Path:      c.Path(),
+			Status:    responseStatus(c, err),
+			Latency:   time.Since(start),
+			IP:        c.IP(),
+			UserAgent: c.Get(fiber.HeaderUserAgent),
+		}
+		if claims, ok := auth.ClaimsFromContext(c); ok {
+			rec.UserID = claims.UserID
+		}
+		recorder.RecordAccess(rec)
+
+		return err
+	}
+}
+
+// responseStatus คืน HTTP status ที่ client จะได้รับจริง
+// (ถ้า handler คืน error มา ErrorHandler ของ app จะตั้ง status ทีหลัง ตอนนี้จึงต้องเดาจาก error เอง)
+func responseStatus(c fiber.Ctx, err error) int {
+	if err == nil {
+		return c.Response().StatusCode()
+	}
+	var appErr *custom_errors.AppError
+	if errors.As(err, &appErr) {
+		return appErr.HTTPStatus
+	}
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		return fiberErr.Code
+	}
+	return fiber.StatusInternalServerError
+}
+
+// JWTAuth is a middleware that requires a valid "Authorization: Bearer <token>" header.
+// เมื่อ Token ถูกต้อง มันจะฝาก JWTClaims ไว้ใน context ของ request
+// ให้ Handler/Service ดึงไปใช้ต่อผ่าน GetClaims หรือ auth.ClaimsFromContext
+func JWTAuth(authService *auth.AuthService) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		header := c.Get(fiber.HeaderAuthorization)
+		if header == "" {
+			return response.Error(c, custom_errors.UnauthorizedError("กรุณาเข้าสู่ระบบก่อนใช้งาน"))
+		}
+
+		scheme, tokenString, found := strings.Cut(header, " ")
+		tokenString = strings.TrimSpace(tokenString)
+		if !found || !strings.EqualFold(scheme, "Bearer") || tokenString == "" {
+			return response.Error(c, custom_errors.InvalidTokenError("รูปแบบ Authorization header ไม่ถูกต้อง ต้องเป็น 'Bearer <token>'"))
+		}
+
+		claims, err := authService.ValidateToken(tokenString)
+		if err != nil {
+			// ⭐️ แยก "Token หมดอายุ" ออกจาก "Token ปลอม/เสีย" เพื่อให้ Client รู้ว่าควร refresh หรือ login ใหม่
+			if errors.Is(err, jwt.ErrTokenExpired) {
+				return response.Error(c, custom_errors.TokenExpiredError("Token หมดอายุแล้ว"))
+			}
+			return response.Error(c, custom_errors.InvalidTokenError("Token ไม่ถูกต้อง"))
+		}
+
+		c.Locals(auth.ClaimsContextKey, claims)
+		c.Locals(logger.FieldsContextKey, logger.AppendFields(c, "user_id", claims.UserID))
+		return c.Next()
+	}
+}
+
+// GetClaims ดึง JWTClaims ที่ JWTAuth ฝากไว้ออกมาจาก request
+func GetClaims(c fiber.Ctx) (*auth.JWTClaims, bool) {
+	return auth.ClaimsFromContext(c)
+}
+
+// RequireRole is a middleware that allows only users with one of the given roles.
+// ต้องใช้ต่อจาก JWTAuth เสมอ เช่น
+//
+//	admin := api.Group("/admin", middleware.JWTAuth(authService), middleware.RequireRole(auth.RoleAdmin))
+func RequireRole(roles ...string) fiber.Handler {
 	return func(c fiber.Ctx) error {
-		// ...
+		if err := auth.RequireRole(c, roles...); err != nil {
+			return response.Error(c, err.(*custom_errors.AppError))
+		}
 		return c.Next()
 	}
 }
+
+// RequirePermission is a middleware that checks the permission matrix (RBAC) for the current user.
+// ต้องใช้ต่อจาก JWTAuth เสมอ
+func RequirePermission(rbac *auth.RBAC, permissions ...string) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		if err := rbac.RequirePermission(c, permissions...); err != nil {
+			return response.Error(c, err.(*custom_errors.AppError))
+		}
+		return c.Next()
+	}
+}
+
+// CORS is a middleware for Cross-Origin Resource Sharing
+// ใช้ policy ตาม path ของ request: กลุ่มที่ pathPrefix ยาวที่สุดและตรงกันจะถูกใช้ ถ้าไม่ตรงกลุ่มไหนเลยจะใช้ Default
+// (ติดตั้งครั้งเดียวด้วย app.Use ไม่ต้องใส่ซ้ำในแต่ละ group เพื่อไม่ให้ header ถูกเขียนซ้อนกัน)
+// ควรเรียก cfg.Validate() ตอนเริ่มระบบก่อน เพราะ policy ที่ผิดจะทำให้ตรงนี้ panic
+func CORS(cfg config.CORSConfig) fiber.Handler {
+	type groupHandler struct {
+		prefix  string
+		handler fiber.Handler
+	}
+
+	groups := make([]groupHandler, 0, len(cfg.Groups))
+	for _, group := range cfg.Groups {
+		groups = append(groups, groupHandler{prefix: strings.TrimSuffix(group.PathPrefix, "/"), handler: newCORSHandler(group.Policy)})
+	}
+	// เรียงจาก prefix ยาวไปสั้น เพื่อให้กลุ่มที่เจาะจงกว่าชนะ
+	sort.SliceStable(groups, func(i, j int) bool { return len(groups[i].prefix) > len(groups[j].prefix) })
+	defaultHandler := newCORSHandler(cfg.Default)
+
+	return func(c fiber.Ctx) error {
+		path := c.Path()
+		for _, group := range groups {
+			if path == group.prefix || strings.HasPrefix(path, group.prefix+"/") {
+				return group.handler(c)
+			}
+		}
+		return defaultHandler(c)
+	}
+}
+
+// newCORSHandler แปลง policy จาก config เป็น cors middleware ของ Fiber
+func newCORSHandler(policy config.CORSPolicy) fiber.Handler {
+	if policy.Disabled {
+		return func(c fiber.Ctx) error { return c.Next() }
+	}
+
+	corsConfig := cors.Config{
+		AllowOrigins:        policy.AllowOrigins,
+		AllowMethods:        policy.AllowMethods,
+		AllowHeaders:        policy.AllowHeaders,
+		ExposeHeaders:       policy.ExposeHeaders,
+		AllowCredentials:    policy.AllowCredentials,
+		MaxAge:              int(policy.MaxAge.Seconds()),
+		AllowPrivateNetwork: policy.AllowPrivateNetwork,
+	}
+	// ⭐️ Fiber ถือว่า "ไม่ระบุ origin" = อนุญาตทุก origin ซึ่งอันตรายเกินไปสำหรับค่าเริ่มต้น
+	// เราจึงตีความว่า "ไม่ระบุ" = ไม่อนุญาต origin ไหนเลย (ถ้าต้องการทุก origin ให้ใส่ "*" ให้ชัดเจน)
+	if len(policy.AllowOrigins) == 0 {
+		corsConfig.AllowOriginsFunc = func(string) bool { return false }
+	}
+	return cors.New(corsConfig)
+}
@@ -0,0 +1,226 @@
+package middleware
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"go-template/pkg/auth"
+	"go-template/pkg/config"
+	"go-template/pkg/custom_errors"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const testJWTSecret = "middleware-test-secret"
+
+// send ยิง request เข้า app แล้วคืน status กับ error code (ว่าง = ไม่ใช่ error response)
+func send(t *testing.T, app *fiber.App, req *http.Request) (*http.Response, string) {
+	t.Helper()
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var errorBody struct {
+		Error struct {
+			Code string `json:"code"`
+		} `json:"error"`
+	}
+	_ = json.Unmarshal(body, &errorBody)
+	return resp, errorBody.Error.Code
+}
+
+func newTestAuthService(t *testing.T) *auth.AuthService {
+	t.Helper()
+	authService, err := auth.NewAuthService(auth.Options{JWTSecret: testJWTSecret})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return authService
+}
+
+func signToken(t *testing.T, secret string, expiresAt time.Time) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, auth.JWTClaims{
+		UserID:           7,
+		Role:             auth.RoleUser,
+		RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(expiresAt)},
+	}).SignedString([]byte(secret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestJWTAuth(t *testing.T) {
+	authService := newTestAuthService(t)
+	validToken, err := authService.GenerateToken(7, "john@example.com", auth.RoleUser)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	app := fiber.New()
+	app.Get("/me", JWTAuth(authService), func(c fiber.Ctx) error {
+		claims, ok := GetClaims(c)
+		if !ok {
+			return c.SendStatus(fiber.StatusInternalServerError)
+		}
+		return c.SendString(strconv.FormatUint(uint64(claims.UserID), 10))
+	})
+
+	tests := []struct {
+		name          string
+		authorization string
+		wantStatus    int
+		wantCode      string
+	}{
+		{name: "valid token", authorization: "Bearer " + validToken, wantStatus: fiber.StatusOK},
+		{name: "scheme is case-insensitive", authorization: "bearer " + validToken, wantStatus: fiber.StatusOK},
+		{name: "missing header", authorization: "", wantStatus: fiber.StatusUnauthorized, wantCode: custom_errors.ErrUnauthorized},
+		{name: "wrong scheme", authorization: "Basic " + validToken, wantStatus: fiber.StatusUnauthorized, wantCode: custom_errors.ErrInvalidToken},
+		{name: "no token", authorization: "Bearer ", wantStatus: fiber.StatusUnauthorized, wantCode: custom_errors.ErrInvalidToken},
+		{name: "token without scheme", authorization: validToken, wantStatus: fiber.StatusUnauthorized, wantCode: custom_errors.ErrInvalidToken},
+		{name: "garbage token", authorization: "Bearer not-a-jwt", wantStatus: fiber.StatusUnauthorized, wantCode: custom_errors.ErrInvalidToken},
+		{name: "signed with another secret", authorization: "Bearer " + signToken(t, "other-secret", time.Now().Add(time.Hour)), wantStatus: fiber.StatusUnauthorized, wantCode: custom_errors.ErrInvalidToken},
+		{name: "expired token", authorization: "Bearer " + signToken(t, testJWTSecret, time.Now().Add(-time.Minute)), wantStatus: fiber.StatusUnauthorized, wantCode: custom_errors.ErrTokenExpired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(fiber.MethodGet, "/me", nil)
+			if tt.authorization != "" {
+				req.Header.Set(fiber.HeaderAuthorization, tt.authorization)
+			}
+			resp, code := send(t, app, req)
+			if resp.StatusCode != tt.wantStatus || code != tt.wantCode {
+				t.Errorf("got %d %q, want %d %q", resp.StatusCode, code, tt.wantStatus, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestRequireRoleAndPermission(t *testing.T) {
+	authService := newTestAuthService(t)
+	rbac := auth.NewRBAC(map[string][]string{
+		auth.RoleAdmin: {auth.PermissionAll},
+		"support":      {"orders:read"},
+		auth.RoleUser:  {"users:read", "reports:*"},
+	})
+
+	app := fiber.New()
+	ok := func(c fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) }
+	app.Get("/admin", JWTAuth(authService), RequireRole(auth.RoleAdmin), ok)
+	app.Get("/staff", JWTAuth(authService), RequireRole(auth.RoleAdmin, "Support"), ok)
+	app.Get("/orders", JWTAuth(authService), RequirePermission(rbac, "orders:read"), ok)
+	app.Get("/orders/export", JWTAuth(authService), RequirePermission(rbac, "orders:read", "orders:export"), ok)
+	app.Get("/reports", JWTAuth(authService), RequirePermission(rbac, "reports:read"), ok)
+	app.Get("/no-auth/role", RequireRole(auth.RoleAdmin), ok)
+	app.Get("/no-auth/permission", RequirePermission(rbac, "orders:read"), ok)
+
+	tests := []struct {
+		path       string
+		role       string // ว่าง = ไม่ส่ง token
+		wantStatus int
+		wantCode   string
+	}{
+		{path: "/admin", role: auth.RoleAdmin, wantStatus: fiber.StatusOK},
+		{path: "/admin", role: auth.RoleUser, wantStatus: fiber.StatusForbidden, wantCode: custom_errors.ErrPermissionDenied},
+		{path: "/staff", role: "support", wantStatus: fiber.StatusOK}, // role ไม่สนตัวพิมพ์
+		{path: "/staff", role: auth.RoleUser, wantStatus: fiber.StatusForbidden, wantCode: custom_errors.ErrPermissionDenied},
+		{path: "/orders", role: auth.RoleAdmin, wantStatus: fiber.StatusOK},
+		{path: "/orders", role: "support", wantStatus: fiber.StatusOK},
+		{path: "/orders", role: auth.RoleUser, wantStatus: fiber.StatusForbidden, wantCode: custom_errors.ErrPermissionDenied},
+		{path: "/orders/export", role: "support", wantStatus: fiber.StatusForbidden, wantCode: custom_errors.ErrPermissionDenied}, // ต้องมีครบทุกสิทธิ์
+		{path: "/orders/export", role: auth.RoleAdmin, wantStatus: fiber.StatusOK},
+		{path: "/reports", role: auth.RoleUser, wantStatus: fiber.StatusOK}, // resource wildcard
+		{path: "/reports", role: "guest", wantStatus: fiber.StatusForbidden, wantCode: custom_errors.ErrPermissionDenied},
+		{path: "/no-auth/role", wantStatus: fiber.StatusUnauthorized, wantCode: custom_errors.ErrUnauthorized},
+		{path: "/no-auth/permission", wantStatus: fiber.StatusUnauthorized, wantCode: custom_errors.ErrUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path+" as "+tt.role, func(t *testing.T) {
+			req := httptest.NewRequest(fiber.MethodGet, tt.path, nil)
+			if tt.role != "" {
+				token, err := authService.GenerateToken(1, "john@example.com", tt.role)
+				if err != nil {
+					t.Fatal(err)
+				}
+				req.Header.Set(fiber.HeaderAuthorization, "Bearer "+token)
+			}
+			resp, code := send(t, app, req)
+			if resp.StatusCode != tt.wantStatus || code != tt.wantCode {
+				t.Errorf("got %d %q, want %d %q", resp.StatusCode, code, tt.wantStatus, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestCORS(t *testing.T) {
+	app := fiber.New()
+	app.Use(CORS(config.CORSConfig{
+		Default: config.CORSPolicy{
+			AllowOrigins: []string{"https://app.example.com", "https://*.partner.com"},
+			AllowMethods: []string{fiber.MethodGet, fiber.MethodPost},
+		},
+		Groups: []config.CORSGroupConfig{
+			{PathPrefix: "/api/admin", Policy: config.CORSPolicy{AllowOrigins: []string{"https://admin.example.com"}, AllowCredentials: true}},
+			{PathPrefix: "/api/admin/public/", Policy: config.CORSPolicy{AllowOrigins: []string{"*"}}},
+			{PathPrefix: "/webhooks", Policy: config.CORSPolicy{Disabled: true}},
+			{PathPrefix: "/internal", Policy: config.CORSPolicy{}}, // ไม่ระบุ origin = ไม่อนุญาตใครเลย
+		},
+	}))
+	app.Use(func(c fiber.Ctx) error { return c.SendString("ok") })
+
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		origin      string
+		wantOrigin  string // ค่าของ Access-Control-Allow-Origin (ว่าง = ไม่อนุญาต)
+		wantCreds   bool
+		wantMethods string // เฉพาะ preflight ที่อนุญาต
+	}{
+		{name: "default allows listed origin", method: fiber.MethodGet, path: "/api/users", origin: "https://app.example.com", wantOrigin: "https://app.example.com"},
+		{name: "default allows subdomain wildcard", method: fiber.MethodGet, path: "/api/users", origin: "https://shop.partner.com", wantOrigin: "https://shop.partner.com"},
+		{name: "default rejects other origin", method: fiber.MethodGet, path: "/api/users", origin: "https://evil.com"},
+		{name: "default preflight", method: fiber.MethodOptions, path: "/api/users", origin: "https://app.example.com", wantOrigin: "https://app.example.com", wantMethods: "GET, POST"},
+		{name: "group uses its own origins", method: fiber.MethodGet, path: "/api/admin/users", origin: "https://admin.example.com", wantOrigin: "https://admin.example.com", wantCreds: true},
+		{name: "group does not inherit default origins", method: fiber.MethodGet, path: "/api/admin/users", origin: "https://app.example.com"},
+		{name: "group matches exact prefix", method: fiber.MethodGet, path: "/api/admin", origin: "https://admin.example.com", wantOrigin: "https://admin.example.com", wantCreds: true},
+		{name: "prefix only matches whole segments", method: fiber.MethodGet, path: "/api/administrators", origin: "https://app.example.com", wantOrigin: "https://app.example.com"},
+		{name: "longest prefix wins", method: fiber.MethodGet, path: "/api/admin/public/logo", origin: "https://anyone.io", wantOrigin: "*"},
+		{name: "disabled group sends no CORS headers", method: fiber.MethodGet, path: "/webhooks/dhl", origin: "https://app.example.com"},
+		{name: "empty origins deny all", method: fiber.MethodGet, path: "/internal/metrics", origin: "https://app.example.com"},
+		{name: "empty origins deny preflight", method: fiber.MethodOptions, path: "/internal/metrics", origin: "https://app.example.com"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			req.Header.Set(fiber.HeaderOrigin, tt.origin)
+			if tt.method == fiber.MethodOptions {
+				req.Header.Set(fiber.HeaderAccessControlRequestMethod, fiber.MethodPost)
+			}
+			resp, _ := send(t, app, req)
+
+			if got := resp.Header.Get(fiber.HeaderAccessControlAllowOrigin); got != tt.wantOrigin {
+				t.Errorf("Access-Control-Allow-Origin = %q, want %q", got, tt.wantOrigin)
+			}
+			if got := resp.Header.Get(fiber.HeaderAccessControlAllowCredentials) == "true"; got != tt.wantCreds {
+				t.Errorf("Access-Control-Allow-Credentials = %v, want %v", got, tt.wantCreds)
+			}
+			// Fiber ใส่ Allow-Methods ให้ preflight ทุกตัว แต่ browser จะไม่ยอมถ้าไม่มี Allow-Origin จึงตรวจเฉพาะตัวที่อนุญาต
+			if got := resp.Header.Get(fiber.HeaderAccessControlAllowMethods); tt.wantMethods != "" && got != tt.wantMethods {
+				t.Errorf("Access-Control-Allow-Methods = %q, want %q", got, tt.wantMethods)
+			}
+		})
+	}
+}
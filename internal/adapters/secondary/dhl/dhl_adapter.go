@@ -1,28 +1,29 @@
 package dhl
 
 import (
-	"bytes"
-	"encoding/json"
-	"fmt"
+	"context"
 	"net/http"
-	"time"
+	"net/url"
+
+	"github.com/google/uuid"
+
+	"go-template/pkg/httpclient"
 )
 
 // DHLAdapter handles communication with DHL API
 type DHLAdapter struct {
 	baseURL string
 	apiKey  string
-	client  *http.Client
+	client  *httpclient.Client
 }
 
 // NewDHLAdapter creates a new DHL adapter
-func NewDHLAdapter(baseURL, apiKey string) *DHLAdapter {
+// client คือ HTTP Client กลางที่มี retry + circuit breaker (ดู pkg/httpclient)
+func NewDHLAdapter(baseURL, apiKey string, client *httpclient.Client) *DHLAdapter {
 	return &DHLAdapter{
 		baseURL: baseURL,
 		apiKey:  apiKey,
-		client: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		client:  client,
 	}
 }
 
@@ -40,38 +41,105 @@ type TrackingResponse struct {
 }
 
 // TrackShipment tracks a shipment using DHL API
+// Deprecated: use TrackShipmentContext so the call can be cancelled with the incoming request.
 func (d *DHLAdapter) TrackShipment(trackingNumber string) (*TrackingResponse, error) {
+	return d.TrackShipmentContext(context.Background(), trackingNumber)
+}
+
+// TrackShipmentContext tracks a shipment using DHL API
+// error ที่คืนออกไปเป็น *custom_errors.AppError (EXTERNAL_API_ERROR) พร้อม upstream status/body
+func (d *DHLAdapter) TrackShipmentContext(ctx context.Context, trackingNumber string) (*TrackingResponse, error) {
 	req := TrackingRequest{
 		TrackingNumber: trackingNumber,
 	}
 
-	jsonData, err := json.Marshal(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	// POST แต่แค่อ่านสถานะ จึงลองซ้ำได้
+	var trackingResp TrackingResponse
+	if err := d.client.DoJSON(httpclient.WithRetry(ctx), http.MethodPost, d.baseURL+"/track", d.headers(), req, &trackingResp); err != nil {
+		return nil, err
 	}
 
-	httpReq, err := http.NewRequest("POST", d.baseURL+"/track", bytes.NewBuffer(jsonData))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
+	return &trackingResp, nil
+}
 
-	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("Authorization", "Bearer "+d.apiKey)
+// RateRequest represents a rate quote request
+type RateRequest struct {
+	DestinationCountry    string  `json:"destinationCountryCode"`
+	DestinationPostalCode string  `json:"destinationPostalCode"`
+	WeightKg              float64 `json:"weight"`
+	DeclaredValue         string  `json:"declaredValue"`
+}
 
-	resp, err := d.client.Do(httpReq)
-	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
-	}
-	defer resp.Body.Close()
+// RateResponse represents a rate quote response
+type RateResponse struct {
+	Products []RateProduct `json:"products"`
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("DHL API returned status: %d", resp.StatusCode)
+// RateProduct represents a single DHL product offer
+type RateProduct struct {
+	ProductCode string `json:"productCode"`
+	ProductName string `json:"productName"`
+	TotalPrice  string `json:"totalPrice"`
+	Currency    string `json:"currency"`
+	TransitDays int    `json:"transitDays"`
+}
+
+// ShipmentRequest represents a create shipment request
+type ShipmentRequest struct {
+	Reference   string          `json:"customerReference"`
+	ProductCode string          `json:"productCode,omitempty"`
+	Receiver    ShipmentAddress `json:"receiver"`
+	WeightKg    float64         `json:"weight"`
+}
+
+// ShipmentAddress represents a receiver address
+type ShipmentAddress struct {
+	Name         string `json:"name"`
+	Phone        string `json:"phone"`
+	AddressLine  string `json:"addressLine1"`
+	AddressLine2 string `json:"addressLine2,omitempty"`
+	City         string `json:"cityName"`
+	Province     string `json:"provinceName"`
+	PostalCode   string `json:"postalCode"`
+	CountryCode  string `json:"countryCode"`
+}
+
+// ShipmentResponse represents a create shipment response
+type ShipmentResponse struct {
+	ShipmentTrackingNumber string `json:"shipmentTrackingNumber"`
+	LabelURL               string `json:"labelUrl"`
+}
+
+// GetRates requests rate quotes from DHL API
+// POST แต่แค่ขอราคา จึงลองซ้ำได้
+func (d *DHLAdapter) GetRates(ctx context.Context, req *RateRequest) (*RateResponse, error) {
+	var rateResp RateResponse
+	if err := d.client.DoJSON(httpclient.WithRetry(ctx), http.MethodPost, d.baseURL+"/rates", d.headers(), req, &rateResp); err != nil {
+		return nil, err
 	}
+	return &rateResp, nil
+}
 
-	var trackingResp TrackingResponse
-	if err := json.NewDecoder(resp.Body).Decode(&trackingResp); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+// CreateShipment books a shipment and returns its tracking number and label
+// ⭐️ ทุกรอบที่ลองซ้ำใช้ Idempotency-Key เดียวกัน ถ้ารอบแรก timeout หลัง DHL จองไปแล้ว รอบถัดไปจะได้ shipment เดิมกลับมา (ไม่จองซ้ำ)
+func (d *DHLAdapter) CreateShipment(ctx context.Context, req *ShipmentRequest) (*ShipmentResponse, error) {
+	headers := d.headers()
+	headers[httpclient.IdempotencyKeyHeader] = uuid.NewString()
+
+	var shipmentResp ShipmentResponse
+	if err := d.client.DoJSON(ctx, http.MethodPost, d.baseURL+"/shipments", headers, req, &shipmentResp); err != nil {
+		return nil, err
 	}
+	return &shipmentResp, nil
+}
 
-	return &trackingResp, nil
+// CancelShipment cancels a booked shipment before pickup
+func (d *DHLAdapter) CancelShipment(ctx context.Context, trackingNumber string) error {
+	return d.client.DoJSON(ctx, http.MethodDelete, d.baseURL+"/shipments/"+url.PathEscape(trackingNumber), d.headers(), nil, nil)
+}
+
+func (d *DHLAdapter) headers() map[string]string {
+	return map[string]string{
+		"Authorization": "Bearer " + d.apiKey,
+	}
 }
@@ -0,0 +1,145 @@
+package dhl
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"go-template/internal/adapters/secondary/shipping"
+
+	"github.com/shopspring/decimal"
+)
+
+// ProviderName คือชื่อที่ใช้ลงทะเบียน DHL ใน shipping.Registry
+const ProviderName = "dhl"
+
+// provider คือ "ตัวแปลง" ที่ทำให้ DHLAdapter เสียบเข้ากับพอร์ต shipping.Provider ได้
+// หน้าที่เดียวของมันคือแปลงภาษาของ DHL ให้เป็นภาษากลางของระบบ
+type provider struct {
+	adapter *DHLAdapter
+}
+
+// NewShippingProvider คือโรงงานสร้าง shipping.Provider ที่ใช้ DHL
+func NewShippingProvider(adapter *DHLAdapter) shipping.Provider {
+	return &provider{adapter: adapter}
+}
+
+func (p *provider) Name() string {
+	return ProviderName
+}
+
+func (p *provider) Track(ctx context.Context, trackingNumber string) (*shipping.TrackingInfo, error) {
+	resp, err := p.adapter.TrackShipmentContext(ctx, trackingNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	return &shipping.TrackingInfo{
+		Provider:          ProviderName,
+		TrackingNumber:    resp.TrackingNumber,
+		Status:            mapStatus(resp.Status),
+		CarrierStatus:     resp.Status,
+		Location:          resp.Location,
+		EstimatedDelivery: parseDate(resp.EstimatedDate),
+	}, nil
+}
+
+func (p *provider) QuoteRates(ctx context.Context, req *shipping.RateRequest) ([]*shipping.RateQuote, error) {
+	resp, err := p.adapter.GetRates(ctx, &RateRequest{
+		DestinationCountry:    req.Destination.Country,
+		DestinationPostalCode: req.Destination.PostalCode,
+		WeightKg:              totalWeightKg(req.Parcels),
+		DeclaredValue:         req.DeclaredValue.StringFixed(2),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	quotes := make([]*shipping.RateQuote, 0, len(resp.Products))
+	for _, product := range resp.Products {
+		amount, err := decimal.NewFromString(product.TotalPrice)
+		if err != nil {
+			continue // ราคาอ่านไม่ออก ไม่แสดงดีกว่าแสดงผิด
+		}
+		quotes = append(quotes, &shipping.RateQuote{
+			Provider:      ProviderName,
+			ServiceCode:   product.ProductCode,
+			ServiceName:   product.ProductName,
+			Amount:        amount,
+			Currency:      product.Currency,
+			EstimatedDays: product.TransitDays,
+		})
+	}
+	return quotes, nil
+}
+
+func (p *provider) CreateLabel(ctx context.Context, req *shipping.LabelRequest) (*shipping.Label, error) {
+	resp, err := p.adapter.CreateShipment(ctx, &ShipmentRequest{
+		Reference:   req.Reference,
+		ProductCode: req.ServiceCode,
+		WeightKg:    totalWeightKg(req.Parcels),
+		Receiver: ShipmentAddress{
+			Name:         req.Recipient.Name,
+			Phone:        req.Recipient.Phone,
+			AddressLine:  req.Recipient.Line1,
+			AddressLine2: req.Recipient.Line2,
+			City:         req.Recipient.District,
+			Province:     req.Recipient.Province,
+			PostalCode:   req.Recipient.PostalCode,
+			CountryCode:  req.Recipient.Country,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &shipping.Label{
+		Provider:       ProviderName,
+		TrackingNumber: resp.ShipmentTrackingNumber,
+		LabelURL:       resp.LabelURL,
+	}, nil
+}
+
+func (p *provider) CancelShipment(ctx context.Context, trackingNumber string) error {
+	return p.adapter.CancelShipment(ctx, trackingNumber)
+}
+
+// --- Private Helpers ---
+
+// statuses แปลง statusCode ของ DHL เป็นสถานะกลาง
+var statuses = map[string]string{
+	"pre-transit":      shipping.StatusPreTransit,
+	"transit":          shipping.StatusInTransit,
+	"in-transit":       shipping.StatusInTransit,
+	"out-for-delivery": shipping.StatusOutForDelivery,
+	"delivered":        shipping.StatusDelivered,
+	"failure":          shipping.StatusException,
+	"exception":        shipping.StatusException,
+}
+
+func mapStatus(status string) string {
+	key := strings.ReplaceAll(strings.ToLower(strings.TrimSpace(status)), " ", "-")
+	if mapped, ok := statuses[key]; ok {
+		return mapped
+	}
+	return shipping.StatusUnknown
+}
+
+// parseDate รับได้ทั้ง RFC3339 และแค่วันที่ (ถ้าอ่านไม่ออกถือว่าไม่มีข้อมูล)
+func parseDate(value string) *time.Time {
+	for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return &t
+		}
+	}
+	return nil
+}
+
+// totalWeightKg รวมน้ำหนักทุกกล่อง (DHL รับน้ำหนักเป็นกิโลกรัม)
+func totalWeightKg(parcels []shipping.Parcel) float64 {
+	grams := 0
+	for _, parcel := range parcels {
+		grams += parcel.WeightGrams
+	}
+	return float64(grams) / 1000
+}
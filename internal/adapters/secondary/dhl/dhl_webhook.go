@@ -0,0 +1,47 @@
+package dhl
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"go-template/internal/adapters/secondary/shipping"
+)
+
+// WebhookPayload represents a DHL shipment status push notification
+type WebhookPayload struct {
+	ID        string `json:"id"`
+	Event     string `json:"event"`
+	Timestamp string `json:"timestamp"`
+	Shipment  struct {
+		TrackingNumber string `json:"trackingNumber"`
+		Status         string `json:"status"`
+		Location       string `json:"location"`
+	} `json:"shipment"`
+}
+
+// ParseWebhook แปลง payload ของ DHL เป็น shipping.WebhookEvent
+func (p *provider) ParseWebhook(payload []byte) (*shipping.WebhookEvent, error) {
+	var body WebhookPayload
+	if err := json.Unmarshal(payload, &body); err != nil {
+		return nil, fmt.Errorf("invalid DHL webhook payload: %w", err)
+	}
+	if body.ID == "" || body.Shipment.TrackingNumber == "" {
+		return nil, errors.New("DHL webhook payload is missing id or trackingNumber")
+	}
+
+	occurredAt, err := time.Parse(time.RFC3339, body.Timestamp)
+	if err != nil {
+		return nil, fmt.Errorf("invalid DHL webhook timestamp: %w", err)
+	}
+
+	return &shipping.WebhookEvent{
+		EventID:        body.ID,
+		TrackingNumber: body.Shipment.TrackingNumber,
+		Status:         mapStatus(body.Shipment.Status),
+		CarrierStatus:  body.Shipment.Status,
+		Location:       body.Shipment.Location,
+		OccurredAt:     occurredAt,
+	}, nil
+}
@@ -0,0 +1,178 @@
+package fakecarrier
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"go-template/internal/adapters/secondary/shipping"
+	"go-template/pkg/config"
+	"go-template/pkg/custom_errors"
+
+	"github.com/shopspring/decimal"
+)
+
+// ProviderName คือชื่อที่ใช้ลงทะเบียนขนส่งจำลองใน shipping.Registry
+const ProviderName = "fake"
+
+// FakeCarrier คือขนส่ง "จำลอง" สำหรับ Local Dev / Demo / ทดสอบ
+// ไม่ยิงออกไปข้างนอกเลย ทุกอย่างเก็บในหน่วยความจำ และตั้งพฤติกรรมได้จาก config (shipping.fake)
+type FakeCarrier struct {
+	cfg config.FakeCarrierConfig
+
+	mu        sync.Mutex
+	shipments map[string]bool // tracking number -> ถูกยกเลิกแล้วหรือยัง
+}
+
+// New คือโรงงานสร้าง FakeCarrier
+func New(cfg config.FakeCarrierConfig) *FakeCarrier {
+	if cfg.TrackingStatus == "" {
+		cfg.TrackingStatus = shipping.StatusInTransit
+	}
+	if len(cfg.Rates) == 0 {
+		cfg.Rates = []config.FakeRateConfig{
+			{ServiceCode: "standard", ServiceName: "Standard", Amount: "50.00", Currency: "THB", EstimatedDays: 3},
+			{ServiceCode: "express", ServiceName: "Express", Amount: "120.00", Currency: "THB", EstimatedDays: 1},
+		}
+	}
+	return &FakeCarrier{cfg: cfg, shipments: make(map[string]bool)}
+}
+
+func (f *FakeCarrier) Name() string {
+	return ProviderName
+}
+
+func (f *FakeCarrier) Track(ctx context.Context, trackingNumber string) (*shipping.TrackingInfo, error) {
+	if err := f.simulate(ctx); err != nil {
+		return nil, err
+	}
+
+	status := f.cfg.TrackingStatus
+	f.mu.Lock()
+	if cancelled := f.shipments[trackingNumber]; cancelled {
+		status = shipping.StatusException
+	}
+	f.mu.Unlock()
+
+	estimated := time.Now().AddDate(0, 0, 2)
+	return &shipping.TrackingInfo{
+		Provider:          ProviderName,
+		TrackingNumber:    trackingNumber,
+		Status:            status,
+		CarrierStatus:     "FAKE_" + strings.ToUpper(status),
+		Location:          "Bangkok Hub",
+		EstimatedDelivery: &estimated,
+	}, nil
+}
+
+func (f *FakeCarrier) QuoteRates(ctx context.Context, req *shipping.RateRequest) ([]*shipping.RateQuote, error) {
+	if err := f.simulate(ctx); err != nil {
+		return nil, err
+	}
+
+	quotes := make([]*shipping.RateQuote, 0, len(f.cfg.Rates))
+	for _, rate := range f.cfg.Rates {
+		amount, err := decimal.NewFromString(rate.Amount)
+		if err != nil {
+			return nil, custom_errors.SystemErrorWithDetails("ตั้งค่าราคาของขนส่งจำลองไม่ถูกต้อง", err.Error())
+		}
+		quotes = append(quotes, &shipping.RateQuote{
+			Provider:      ProviderName,
+			ServiceCode:   rate.ServiceCode,
+			ServiceName:   rate.ServiceName,
+			Amount:        amount,
+			Currency:      rate.Currency,
+			EstimatedDays: rate.EstimatedDays,
+		})
+	}
+	return quotes, nil
+}
+
+func (f *FakeCarrier) CreateLabel(ctx context.Context, req *shipping.LabelRequest) (*shipping.Label, error) {
+	if err := f.simulate(ctx); err != nil {
+		return nil, err
+	}
+
+	suffix := make([]byte, 5)
+	if _, err := rand.Read(suffix); err != nil {
+		return nil, custom_errors.SystemErrorWithDetails("ไม่สามารถออกเลขพัสดุจำลองได้", err.Error())
+	}
+	trackingNumber := "FAKE" + strings.ToUpper(hex.EncodeToString(suffix))
+
+	f.mu.Lock()
+	f.shipments[trackingNumber] = false
+	f.mu.Unlock()
+
+	return &shipping.Label{
+		Provider:       ProviderName,
+		TrackingNumber: trackingNumber,
+		LabelURL:       "https://labels.invalid/" + trackingNumber + ".pdf",
+	}, nil
+}
+
+func (f *FakeCarrier) CancelShipment(ctx context.Context, trackingNumber string) error {
+	if err := f.simulate(ctx); err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.shipments[trackingNumber]; !ok {
+		return custom_errors.NotFoundError("ไม่พบพัสดุเลขนี้ในขนส่งจำลอง")
+	}
+	f.shipments[trackingNumber] = true
+	return nil
+}
+
+// simulate จำลองความหน่วงและความล้มเหลวของขนส่งจริงตามที่ตั้งค่าไว้
+func (f *FakeCarrier) simulate(ctx context.Context) error {
+	if f.cfg.Latency > 0 {
+		select {
+		case <-ctx.Done():
+			return custom_errors.ExternalAPIError("fake: การเรียกปลายทางถูกยกเลิก", ctx.Err().Error())
+		case <-time.After(f.cfg.Latency):
+		}
+	}
+	if f.cfg.FailWith != "" {
+		return custom_errors.ExternalAPIError("fake: "+f.cfg.FailWith, map[string]interface{}{"service": ProviderName})
+	}
+	return nil
+}
+
+// webhookPayload คือรูปแบบ webhook ของขนส่งจำลอง (ใช้สถานะกลางของระบบตรงๆ)
+type webhookPayload struct {
+	EventID        string    `json:"event_id"`
+	TrackingNumber string    `json:"tracking_number"`
+	Status         string    `json:"status"`
+	Location       string    `json:"location"`
+	OccurredAt     time.Time `json:"occurred_at"`
+}
+
+// ParseWebhook ทำให้ขนส่งจำลองส่ง webhook ได้เหมือนขนส่งจริง (สะดวกตอนทดสอบ flow ทั้งเส้น)
+func (f *FakeCarrier) ParseWebhook(payload []byte) (*shipping.WebhookEvent, error) {
+	var body webhookPayload
+	if err := json.Unmarshal(payload, &body); err != nil {
+		return nil, fmt.Errorf("invalid fake webhook payload: %w", err)
+	}
+	if body.EventID == "" || body.TrackingNumber == "" || body.Status == "" {
+		return nil, errors.New("fake webhook payload requires event_id, tracking_number and status")
+	}
+	if body.OccurredAt.IsZero() {
+		body.OccurredAt = time.Now()
+	}
+
+	return &shipping.WebhookEvent{
+		EventID:        body.EventID,
+		TrackingNumber: body.TrackingNumber,
+		Status:         body.Status,
+		CarrierStatus:  "FAKE_" + strings.ToUpper(body.Status),
+		Location:       body.Location,
+		OccurredAt:     body.OccurredAt,
+	}, nil
+}
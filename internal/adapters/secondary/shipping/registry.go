@@ -0,0 +1,57 @@
+package shipping
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"go-template/pkg/custom_errors"
+)
+
+// Registry คือ "สมุดรายชื่อ" ขนส่งทั้งหมดที่เปิดใช้งาน พร้อมขนส่งเริ่มต้นจาก config
+type Registry struct {
+	providers   map[string]Provider
+	defaultName string
+}
+
+// NewRegistry สร้าง Registry (defaultName ต้องเป็นหนึ่งใน providers ที่ส่งเข้ามา)
+func NewRegistry(defaultName string, providers ...Provider) (*Registry, error) {
+	r := &Registry{providers: make(map[string]Provider, len(providers))}
+	for _, p := range providers {
+		name := strings.ToLower(p.Name())
+		if _, exists := r.providers[name]; exists {
+			return nil, fmt.Errorf("shipping provider %q registered twice", name)
+		}
+		r.providers[name] = p
+	}
+
+	r.defaultName = strings.ToLower(defaultName)
+	if _, ok := r.providers[r.defaultName]; !ok {
+		return nil, fmt.Errorf("default shipping provider %q is not enabled (enabled: %v)", defaultName, r.Names())
+	}
+	return r, nil
+}
+
+// Get คืนขนส่งตามชื่อ
+func (r *Registry) Get(name string) (Provider, error) {
+	p, ok := r.providers[strings.ToLower(name)]
+	if !ok {
+		return nil, custom_errors.ValidationError("ไม่รองรับขนส่งนี้", map[string]interface{}{"provider": name, "available": r.Names()})
+	}
+	return p, nil
+}
+
+// DefaultName คือชื่อขนส่งที่ใช้เมื่อ Order ไม่ได้ระบุมา
+func (r *Registry) DefaultName() string {
+	return r.defaultName
+}
+
+// Names คืนชื่อขนส่งทั้งหมดที่เปิดใช้งาน (เรียงตามตัวอักษร)
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.providers))
+	for name := range r.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,106 @@
+package shipping
+
+import (
+	"context"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Provider คือ "พอร์ต" กลางของบริษัทขนส่งทุกเจ้า (DHL, Kerry, Flash, ไปรษณีย์ไทย, ...)
+// โมดูลธุรกิจคุยผ่าน interface นี้เท่านั้น การเพิ่มขนส่งเจ้าใหม่จึงแค่เขียน Adapter แล้วลงทะเบียนใน Registry
+type Provider interface {
+	// Name คือชื่อที่ใช้อ้างถึงขนส่งเจ้านี้ (ตัวพิมพ์เล็ก เช่น "dhl") และถูกบันทึกไว้กับ Order
+	Name() string
+	// Track ถามสถานะพัสดุล่าสุด
+	Track(ctx context.Context, trackingNumber string) (*TrackingInfo, error)
+	// QuoteRates ขอราคาค่าส่งของทุกบริการที่ส่งไปปลายทางนี้ได้
+	QuoteRates(ctx context.Context, req *RateRequest) ([]*RateQuote, error)
+	// CreateLabel สร้างใบจ่าหน้า (จองการจัดส่ง) และได้เลขพัสดุกลับมา
+	CreateLabel(ctx context.Context, req *LabelRequest) (*Label, error)
+	// CancelShipment ยกเลิกการจัดส่งที่จองไว้ (ก่อนขนส่งมารับของ)
+	CancelShipment(ctx context.Context, trackingNumber string) error
+}
+
+// Address คือที่อยู่ผู้ส่ง/ผู้รับ
+type Address struct {
+	Name       string
+	Phone      string
+	Line1      string
+	Line2      string
+	District   string
+	Province   string
+	PostalCode string
+	Country    string // ISO 3166-1 alpha-2 เช่น "TH"
+}
+
+// Parcel คือพัสดุ 1 กล่อง
+type Parcel struct {
+	WeightGrams int
+}
+
+type RateRequest struct {
+	Destination   Address
+	Parcels       []Parcel
+	DeclaredValue decimal.Decimal
+}
+
+// RateQuote คือราคาค่าส่งของ 1 บริการ (เช่น ส่งด่วน / ส่งธรรมดา)
+type RateQuote struct {
+	Provider      string
+	ServiceCode   string
+	ServiceName   string
+	Amount        decimal.Decimal
+	Currency      string
+	EstimatedDays int
+}
+
+type LabelRequest struct {
+	Reference   string // เลขอ้างอิงฝั่งเรา (เช่น เลข Order) ใช้กันจองซ้ำ
+	ServiceCode string // ว่างได้ = ใช้บริการเริ่มต้นของขนส่งเจ้านั้น
+	Recipient   Address
+	Parcels     []Parcel
+}
+
+// Label คือผลการจองการจัดส่ง
+type Label struct {
+	Provider       string
+	TrackingNumber string
+	LabelURL       string
+}
+
+// TrackingInfo คือสถานะพัสดุในรูปแบบกลาง (ไม่ผูกกับ format ของขนส่งเจ้าใด)
+type TrackingInfo struct {
+	Provider          string
+	TrackingNumber    string
+	Status            string // หนึ่งใน Status*
+	CarrierStatus     string // สถานะดิบจากขนส่ง (เก็บไว้ดูตอน debug)
+	Location          string
+	EstimatedDelivery *time.Time
+}
+
+// Tracking statuses
+const (
+	StatusPreTransit     = "pre_transit"
+	StatusInTransit      = "in_transit"
+	StatusOutForDelivery = "out_for_delivery"
+	StatusDelivered      = "delivered"
+	StatusException      = "exception"
+	StatusUnknown        = "unknown"
+)
+
+// WebhookParser คือความสามารถ "เสริม" ของขนส่งที่ส่ง webhook แจ้งสถานะพัสดุมาหาเราได้
+// (ขนส่งที่ไม่รองรับก็ไม่ต้อง implement) การตรวจลายเซ็นทำไว้ก่อนหน้านี้แล้ว ตรงนี้แค่แปลง payload
+type WebhookParser interface {
+	ParseWebhook(payload []byte) (*WebhookEvent, error)
+}
+
+// WebhookEvent คือเหตุการณ์จากขนส่งในรูปแบบกลาง
+type WebhookEvent struct {
+	EventID        string // id ที่ไม่ซ้ำของเหตุการณ์ (ใช้กันประมวลผลซ้ำ)
+	TrackingNumber string
+	Status         string // หนึ่งใน Status*
+	CarrierStatus  string
+	Location       string
+	OccurredAt     time.Time
+}
@@ -0,0 +1,42 @@
+package example_auth
+
+import "time"
+
+// RefreshToken คือพิมพ์เขียวของ Refresh Token หนึ่งใบ
+// Token ทุกใบที่เกิดจากการ login ครั้งเดียวกันจะอยู่ใน "family" เดียวกัน
+// เพื่อให้เราสั่งฆ่าได้ทั้งสายเมื่อเจอการใช้ token เก่าซ้ำ
+type RefreshToken struct {
+	ID           uint
+	UserID       uint
+	FamilyID     string
+	TokenHash    string `log:"redact"`
+	ExpiresAt    time.Time
+	RevokedAt    *time.Time
+	ReplacedByID *uint
+	CreatedAt    time.Time
+}
+
+// IsRevoked บอกว่า token นี้ถูกยกเลิก (หรือถูก rotate ไปแล้ว) หรือยัง
+func (t *RefreshToken) IsRevoked() bool {
+	return t.RevokedAt != nil
+}
+
+// IsExpired บอกว่า token นี้หมดอายุ ณ เวลาที่ระบุหรือยัง
+func (t *RefreshToken) IsExpired(now time.Time) bool {
+	return !now.Before(t.ExpiresAt)
+}
+
+// Subject คือข้อมูลของเจ้าของ token ที่จะถูกใส่ลงใน Access Token
+type Subject struct {
+	UserID uint
+	Email  string
+	Role   string
+}
+
+// TokenPair คือผลลัพธ์ของการ login/refresh
+type TokenPair struct {
+	AccessToken           string
+	AccessTokenExpiresIn  time.Duration
+	RefreshToken          string
+	RefreshTokenExpiresAt time.Time
+}
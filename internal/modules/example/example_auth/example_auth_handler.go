@@ -0,0 +1,111 @@
+package example_auth
+
+import (
+	"go-template/pkg/custom_errors"
+	"go-template/pkg/logger"
+	"go-template/pkg/response"
+	"go-template/pkg/validator"
+	"time"
+
+	govalidator "github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v3"
+)
+
+// ====================================================================================
+// DTOs (Data Transfer Objects)
+// ====================================================================================
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required" vmsg:"required:กรุณาระบุ refresh_token"`
+}
+
+// TokenResponse คือรูปแบบ Token ที่ส่งกลับให้ Client (ใช้ร่วมกับ endpoint login ของโมดูล User ด้วย)
+type TokenResponse struct {
+	AccessToken           string    `json:"access_token"`
+	TokenType             string    `json:"token_type"`
+	ExpiresIn             int       `json:"expires_in"` // วินาที
+	RefreshToken          string    `json:"refresh_token"`
+	RefreshTokenExpiresAt time.Time `json:"refresh_token_expires_at"`
+}
+
+// NewTokenResponse แปลง TokenPair เป็น DTO สำหรับส่งออก
+func NewTokenResponse(pair *TokenPair, loc *time.Location) *TokenResponse {
+	return &TokenResponse{
+		AccessToken:           pair.AccessToken,
+		TokenType:             "Bearer",
+		ExpiresIn:             int(pair.AccessTokenExpiresIn.Seconds()),
+		RefreshToken:          pair.RefreshToken,
+		RefreshTokenExpiresAt: pair.RefreshTokenExpiresAt.In(loc),
+	}
+}
+
+// ====================================================================================
+// Handler
+// ====================================================================================
+
+// handler คือ struct ที่ทำงานจริง
+type handler struct {
+	service         Service
+	log             logger.Logger
+	bangkokLocation *time.Location
+	validator       *govalidator.Validate
+}
+
+// NewExampleAuthHandler คือโรงงานสร้าง Handler
+func NewExampleAuthHandler(service Service, log logger.Logger, bangkokLocation *time.Location, validator *govalidator.Validate) *handler {
+	return &handler{
+		service:         service,
+		log:             log,
+		bangkokLocation: bangkokLocation,
+		validator:       validator,
+	}
+}
+
+// --- Handler Methods ---
+
+func (h *handler) Refresh(c fiber.Ctx) error {
+	req, appErr := h.bindRefreshTokenRequest(c)
+	if appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	tokenPair, serviceErr := h.service.RefreshTokens(c, req.RefreshToken)
+	if serviceErr != nil {
+		return response.Error(c, serviceErr.(*custom_errors.AppError))
+	}
+
+	return response.Success(c, fiber.StatusOK, "Token refreshed successfully", NewTokenResponse(tokenPair, h.bangkokLocation), nil)
+}
+
+func (h *handler) Logout(c fiber.Ctx) error {
+	req, appErr := h.bindRefreshTokenRequest(c)
+	if appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	if serviceErr := h.service.Logout(c, req.RefreshToken); serviceErr != nil {
+		return response.Error(c, serviceErr.(*custom_errors.AppError))
+	}
+
+	return response.Message(c, fiber.StatusOK, "Logged out successfully")
+}
+
+// RegisterRoutes ลงทะเบียน routes ทั้งหมดของโมดูลนี้
+func (h *handler) RegisterRoutes(router fiber.Router) {
+	authRouter := router.Group("/auth")
+	authRouter.Post("/refresh", h.Refresh)
+	authRouter.Post("/logout", h.Logout)
+}
+
+// --- Private Helpers ---
+
+func (h *handler) bindRefreshTokenRequest(c fiber.Ctx) (*RefreshTokenRequest, *custom_errors.AppError) {
+	req := new(RefreshTokenRequest)
+	if err := c.Bind().Body(req); err != nil {
+		return nil, custom_errors.InvalidFormatError("Request body is not valid JSON", err.Error())
+	}
+	if validationResult := validator.Validate(h.validator, req); !validationResult.IsValid {
+		return nil, custom_errors.ValidationError("ข้อมูลที่ส่งมาไม่ถูกต้อง", validationResult.Errors)
+	}
+	return req, nil
+}
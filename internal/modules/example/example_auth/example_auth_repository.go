@@ -0,0 +1,131 @@
+package example_auth
+
+import (
+	"context"
+	"errors"
+	"go-template/pkg/logger"
+	"go-template/pkg/platform/postgres"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrTokenAlreadyRevoked ถูกคืนจาก Rotate เมื่อ token เก่าถูกใช้ไปแล้ว (มีคนชิง rotate ก่อน)
+var ErrTokenAlreadyRevoked = errors.New("refresh token already revoked")
+
+// Repository คือ "สัญญา" ที่ Service จะเรียกใช้
+type Repository interface {
+	Create(ctx context.Context, d *RefreshToken) error
+	GetByHash(ctx context.Context, tokenHash string) (*RefreshToken, error)
+	Rotate(ctx context.Context, oldID uint, newToken *RefreshToken) error
+	RevokeFamily(ctx context.Context, familyID string) error
+}
+
+// Model คือ "ชุดเกราะ" สำหรับ GORM
+type Model struct {
+	gorm.Model
+	UserID       uint      `gorm:"not null;index"`
+	FamilyID     string    `gorm:"not null;index"`
+	TokenHash    string    `gorm:"uniqueIndex;not null" log:"redact"`
+	ExpiresAt    time.Time `gorm:"not null"`
+	RevokedAt    *time.Time
+	ReplacedByID *uint
+}
+
+func (Model) TableName() string {
+	return "example_refresh_tokens"
+}
+
+// repository คือ struct ที่ทำงานจริง
+type repository struct {
+	db  *gorm.DB
+	log logger.Logger
+}
+
+// NewExampleAuthRepository คือโรงงานสร้าง Repository
+func NewExampleAuthRepository(db *gorm.DB, log logger.Logger) Repository {
+	return &repository{db: db, log: log}
+}
+
+// --- Implementation ---
+
+func (r *repository) Create(ctx context.Context, d *RefreshToken) error {
+	gormModel := toGORM(d)
+	if err := postgres.DB(ctx, r.db).Create(gormModel).Error; err != nil {
+		return err
+	}
+	*d = *gormModel.toDomain()
+	return nil
+}
+
+// GetByHash อ่านจาก primary เสมอ: token ที่เพิ่งออก/เพิ่งถูก rotate อาจยังไม่ถึง replica
+// และการตรวจ token ซ้ำ (reuse detection) ต้องเห็นสถานะล่าสุดเท่านั้น
+func (r *repository) GetByHash(ctx context.Context, tokenHash string) (*RefreshToken, error) {
+	var gormModel Model
+	result := postgres.DB(postgres.WithPrimary(ctx), r.db).Where("token_hash = ?", tokenHash).First(&gormModel)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return gormModel.toDomain(), nil
+}
+
+// Rotate ยกเลิก token เก่าและบันทึก token ใหม่ใน Transaction เดียวกัน
+// ⭐️ เงื่อนไข "revoked_at IS NULL" ทำให้ถ้ามี 2 request ใช้ token เดียวกันพร้อมกัน
+// จะมีแค่คนเดียวที่ rotate สำเร็จ อีกคนจะได้ ErrTokenAlreadyRevoked
+func (r *repository) Rotate(ctx context.Context, oldID uint, newToken *RefreshToken) error {
+	return postgres.DB(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		gormModel := toGORM(newToken)
+		if err := tx.Create(gormModel).Error; err != nil {
+			return err
+		}
+
+		result := tx.Model(&Model{}).
+			Where("id = ? AND revoked_at IS NULL", oldID).
+			Updates(map[string]interface{}{
+				"revoked_at":     time.Now(),
+				"replaced_by_id": gormModel.ID,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrTokenAlreadyRevoked
+		}
+
+		*newToken = *gormModel.toDomain()
+		return nil
+	})
+}
+
+// RevokeFamily ยกเลิก token ทุกใบที่ยังใช้งานได้ใน family เดียวกัน
+func (r *repository) RevokeFamily(ctx context.Context, familyID string) error {
+	return postgres.DB(ctx, r.db).Model(&Model{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// --- Translators ---
+
+func toGORM(d *RefreshToken) *Model {
+	return &Model{
+		UserID:       d.UserID,
+		FamilyID:     d.FamilyID,
+		TokenHash:    d.TokenHash,
+		ExpiresAt:    d.ExpiresAt,
+		RevokedAt:    d.RevokedAt,
+		ReplacedByID: d.ReplacedByID,
+	}
+}
+
+func (m *Model) toDomain() *RefreshToken {
+	return &RefreshToken{
+		ID:           m.ID,
+		UserID:       m.UserID,
+		FamilyID:     m.FamilyID,
+		TokenHash:    m.TokenHash,
+		ExpiresAt:    m.ExpiresAt,
+		RevokedAt:    m.RevokedAt,
+		ReplacedByID: m.ReplacedByID,
+		CreatedAt:    m.CreatedAt,
+	}
+}
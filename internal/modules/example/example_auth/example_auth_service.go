@@ -0,0 +1,167 @@
+package example_auth
+
+import (
+	"context"
+	"errors"
+	"go-template/pkg/auth"
+	"go-template/pkg/custom_errors"
+	"go-template/pkg/logger"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SubjectProvider คือ "port" ที่ Service นี้ใช้ถามข้อมูลล่าสุดของเจ้าของ token
+// (เช่น role ที่อาจถูกเปลี่ยน หรือบัญชีที่ถูกระงับไปแล้ว) ตอน refresh
+// โมดูลที่ดูแล User จะเป็นคน implement ให้ เพื่อไม่ให้สองโมดูล import กันไปมา
+type SubjectProvider interface {
+	GetActiveSubject(ctx context.Context, userID uint) (*Subject, error)
+}
+
+// Service คือ "สัญญา" ที่ Handler (และโมดูลอื่น) จะเรียกใช้
+type Service interface {
+	IssueTokens(ctx context.Context, subject *Subject) (*TokenPair, error)
+	RefreshTokens(ctx context.Context, rawRefreshToken string) (*TokenPair, error)
+	Logout(ctx context.Context, rawRefreshToken string) error
+}
+
+// service คือ struct ที่ทำงานจริง
+type service struct {
+	repo            Repository
+	authService     *auth.AuthService
+	subjects        SubjectProvider
+	refreshTokenTTL time.Duration
+	log             logger.Logger
+}
+
+// NewExampleAuthService คือโรงงานสร้าง Service
+func NewExampleAuthService(repo Repository, authService *auth.AuthService, subjects SubjectProvider, refreshTokenTTL time.Duration, log logger.Logger) Service {
+	if refreshTokenTTL <= 0 {
+		refreshTokenTTL = auth.DefaultRefreshTokenTTL
+	}
+	return &service{
+		repo:            repo,
+		authService:     authService,
+		subjects:        subjects,
+		refreshTokenTTL: refreshTokenTTL,
+		log:             log,
+	}
+}
+
+// --- Implementation ---
+
+// IssueTokens ออก Token คู่ใหม่ (เริ่ม family ใหม่) ใช้ตอน login
+func (s *service) IssueTokens(ctx context.Context, subject *Subject) (*TokenPair, error) {
+	familyID := auth.GenerateRandomKey()
+
+	rawRefreshToken, refreshToken, err := s.newRefreshToken(subject.UserID, familyID)
+	if err != nil {
+		return nil, custom_errors.SystemErrorWithDetails("ไม่สามารถสร้าง Refresh Token ได้", err.Error())
+	}
+	if err := s.repo.Create(ctx, refreshToken); err != nil {
+		return nil, custom_errors.SystemErrorWithDetails("ไม่สามารถบันทึก Refresh Token ได้", err.Error())
+	}
+
+	return s.buildTokenPair(subject, rawRefreshToken, refreshToken)
+}
+
+// RefreshTokens แลก Refresh Token ใบเดิมเป็น Token คู่ใหม่ (Rotation)
+// ⭐️ ถ้าเจอ token ที่ถูกใช้ไปแล้วถูกส่งมาอีก แปลว่า token อาจถูกขโมย
+// เราจะฆ่า token ทั้ง family ทิ้ง ให้ผู้ใช้ต้อง login ใหม่
+func (s *service) RefreshTokens(ctx context.Context, rawRefreshToken string) (*TokenPair, error) {
+	current, err := s.repo.GetByHash(ctx, auth.HashOpaqueToken(rawRefreshToken))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, custom_errors.InvalidTokenError("Refresh Token ไม่ถูกต้อง")
+		}
+		return nil, custom_errors.SystemErrorWithDetails("ไม่สามารถตรวจสอบ Refresh Token ได้", err.Error())
+	}
+
+	if current.IsRevoked() {
+		return nil, s.handleReuse(ctx, current)
+	}
+	if current.IsExpired(time.Now()) {
+		return nil, custom_errors.TokenExpiredError("Refresh Token หมดอายุแล้ว กรุณาเข้าสู่ระบบใหม่")
+	}
+
+	subject, err := s.subjects.GetActiveSubject(ctx, current.UserID)
+	if err != nil {
+		// ผู้ใช้ถูกลบ/ถูกระงับ -> ปิด session นี้ทิ้งไปเลย
+		if revokeErr := s.repo.RevokeFamily(ctx, current.FamilyID); revokeErr != nil {
+			s.log.WithContext(ctx).Error("Failed to revoke refresh token family", revokeErr, "family_id", current.FamilyID)
+		}
+		return nil, custom_errors.InvalidTokenError("บัญชีผู้ใช้นี้ไม่สามารถใช้งานได้แล้ว")
+	}
+
+	rawNewToken, newToken, err := s.newRefreshToken(current.UserID, current.FamilyID)
+	if err != nil {
+		return nil, custom_errors.SystemErrorWithDetails("ไม่สามารถสร้าง Refresh Token ได้", err.Error())
+	}
+	if err := s.repo.Rotate(ctx, current.ID, newToken); err != nil {
+		if errors.Is(err, ErrTokenAlreadyRevoked) {
+			return nil, s.handleReuse(ctx, current)
+		}
+		return nil, custom_errors.SystemErrorWithDetails("ไม่สามารถหมุนเวียน Refresh Token ได้", err.Error())
+	}
+
+	return s.buildTokenPair(subject, rawNewToken, newToken)
+}
+
+// Logout ยกเลิก session (ทั้ง family) ของ Refresh Token ที่ส่งมา
+func (s *service) Logout(ctx context.Context, rawRefreshToken string) error {
+	current, err := s.repo.GetByHash(ctx, auth.HashOpaqueToken(rawRefreshToken))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return custom_errors.InvalidTokenError("Refresh Token ไม่ถูกต้อง")
+		}
+		return custom_errors.SystemErrorWithDetails("ไม่สามารถตรวจสอบ Refresh Token ได้", err.Error())
+	}
+
+	if err := s.repo.RevokeFamily(ctx, current.FamilyID); err != nil {
+		return custom_errors.SystemErrorWithDetails("ไม่สามารถออกจากระบบได้", err.Error())
+	}
+
+	s.log.WithContext(ctx).Info("User logged out", "user_id", current.UserID)
+	return nil
+}
+
+// --- Private Helpers ---
+
+// handleReuse ฆ่า token ทั้ง family เมื่อเจอการใช้ token เก่าซ้ำ
+func (s *service) handleReuse(ctx context.Context, token *RefreshToken) error {
+	s.log.WithContext(ctx).Warn("Refresh token reuse detected, revoking token family",
+		"user_id", token.UserID,
+		"family_id", token.FamilyID,
+	)
+	if err := s.repo.RevokeFamily(ctx, token.FamilyID); err != nil {
+		return custom_errors.SystemErrorWithDetails("ไม่สามารถยกเลิก Refresh Token ได้", err.Error())
+	}
+	return custom_errors.InvalidTokenError("Refresh Token ถูกใช้ไปแล้ว กรุณาเข้าสู่ระบบใหม่")
+}
+
+// newRefreshToken สร้าง token ดิบ (ส่งให้ Client) คู่กับ Domain ที่เก็บแค่ hash
+func (s *service) newRefreshToken(userID uint, familyID string) (string, *RefreshToken, error) {
+	raw, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		return "", nil, err
+	}
+	return raw, &RefreshToken{
+		UserID:    userID,
+		FamilyID:  familyID,
+		TokenHash: auth.HashOpaqueToken(raw),
+		ExpiresAt: time.Now().Add(s.refreshTokenTTL),
+	}, nil
+}
+
+func (s *service) buildTokenPair(subject *Subject, rawRefreshToken string, refreshToken *RefreshToken) (*TokenPair, error) {
+	accessToken, err := s.authService.GenerateToken(subject.UserID, subject.Email, subject.Role)
+	if err != nil {
+		return nil, custom_errors.SystemErrorWithDetails("ไม่สามารถสร้าง Token ได้", err.Error())
+	}
+	return &TokenPair{
+		AccessToken:           accessToken,
+		AccessTokenExpiresIn:  s.authService.AccessTokenTTL(),
+		RefreshToken:          rawRefreshToken,
+		RefreshTokenExpiresAt: refreshToken.ExpiresAt,
+	}, nil
+}
@@ -0,0 +1,166 @@
+package example_auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go-template/pkg/auth"
+	"go-template/pkg/custom_errors"
+	"go-template/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+// memoryRepository เก็บ Refresh Token ไว้ใน map โดยทำตามเงื่อนไขเดียวกับ repository จริง
+// (Rotate ยกเลิกใบเดิมได้เฉพาะตอนที่ยังไม่ถูกยกเลิก)
+type memoryRepository struct {
+	tokens map[uint]*RefreshToken
+	nextID uint
+}
+
+func newMemoryRepository() *memoryRepository {
+	return &memoryRepository{tokens: make(map[uint]*RefreshToken)}
+}
+
+func (r *memoryRepository) Create(ctx context.Context, d *RefreshToken) error {
+	r.nextID++
+	d.ID = r.nextID
+	stored := *d
+	r.tokens[d.ID] = &stored
+	return nil
+}
+
+func (r *memoryRepository) GetByHash(ctx context.Context, tokenHash string) (*RefreshToken, error) {
+	for _, t := range r.tokens {
+		if t.TokenHash == tokenHash {
+			found := *t
+			return &found, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *memoryRepository) Rotate(ctx context.Context, oldID uint, newToken *RefreshToken) error {
+	old, ok := r.tokens[oldID]
+	if !ok || old.IsRevoked() {
+		return ErrTokenAlreadyRevoked
+	}
+	if err := r.Create(ctx, newToken); err != nil {
+		return err
+	}
+	now := time.Now()
+	old.RevokedAt = &now
+	old.ReplacedByID = &newToken.ID
+	return nil
+}
+
+func (r *memoryRepository) RevokeFamily(ctx context.Context, familyID string) error {
+	now := time.Now()
+	for _, t := range r.tokens {
+		if t.FamilyID == familyID && !t.IsRevoked() {
+			t.RevokedAt = &now
+		}
+	}
+	return nil
+}
+
+type activeSubjects map[uint]*Subject
+
+func (s activeSubjects) GetActiveSubject(ctx context.Context, userID uint) (*Subject, error) {
+	if subject, ok := s[userID]; ok {
+		return subject, nil
+	}
+	return nil, errors.New("user is not active")
+}
+
+func newTestService(t *testing.T) (Service, *memoryRepository) {
+	t.Helper()
+	authService, err := auth.NewAuthService(auth.Options{JWTSecret: "test-secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := newMemoryRepository()
+	subjects := activeSubjects{1: {UserID: 1, Email: "john@example.com", Role: "user"}}
+	return NewExampleAuthService(repo, authService, subjects, time.Hour, logger.NewSlogLogger()), repo
+}
+
+func assertInvalidToken(t *testing.T, err error) {
+	t.Helper()
+	var appErr *custom_errors.AppError
+	if !errors.As(err, &appErr) || appErr.Code != custom_errors.ErrInvalidToken {
+		t.Fatalf("err = %v, want %s", err, custom_errors.ErrInvalidToken)
+	}
+}
+
+func TestRefreshTokensRejectsRotatedToken(t *testing.T) {
+	ctx := context.Background()
+	s, _ := newTestService(t)
+
+	first, err := s.IssueTokens(ctx, &Subject{UserID: 1, Email: "john@example.com", Role: "user"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := s.RefreshTokens(ctx, first.RefreshToken)
+	if err != nil {
+		t.Fatalf("first refresh: %v", err)
+	}
+	if second.RefreshToken == first.RefreshToken || second.AccessToken == "" {
+		t.Fatalf("refresh should return a new token pair, got %+v", second)
+	}
+
+	_, err = s.RefreshTokens(ctx, first.RefreshToken)
+	assertInvalidToken(t, err)
+}
+
+func TestRefreshTokensReuseRevokesFamily(t *testing.T) {
+	ctx := context.Background()
+	s, repo := newTestService(t)
+
+	first, err := s.IssueTokens(ctx, &Subject{UserID: 1, Email: "john@example.com", Role: "user"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := s.IssueTokens(ctx, &Subject{UserID: 1, Email: "john@example.com", Role: "user"}) // login จากอีกเครื่อง
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := s.RefreshTokens(ctx, first.RefreshToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// คนร้ายเอา token ใบแรกมาใช้ซ้ำ -> ทั้ง family ต้องถูกฆ่า รวมถึงใบล่าสุดของเจ้าของตัวจริง
+	_, err = s.RefreshTokens(ctx, first.RefreshToken)
+	assertInvalidToken(t, err)
+	_, err = s.RefreshTokens(ctx, second.RefreshToken)
+	assertInvalidToken(t, err)
+
+	for _, token := range repo.tokens {
+		if token.TokenHash == auth.HashOpaqueToken(other.RefreshToken) && token.IsRevoked() {
+			t.Error("reuse in one family must not revoke sessions from another login")
+		}
+	}
+	if _, err := s.RefreshTokens(ctx, other.RefreshToken); err != nil {
+		t.Errorf("refresh in another family: %v", err)
+	}
+}
+
+func TestLogoutInvalidatesToken(t *testing.T) {
+	ctx := context.Background()
+	s, _ := newTestService(t)
+
+	pair, err := s.IssueTokens(ctx, &Subject{UserID: 1, Email: "john@example.com", Role: "user"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Logout(ctx, pair.RefreshToken); err != nil {
+		t.Fatalf("Logout() = %v", err)
+	}
+
+	_, err = s.RefreshTokens(ctx, pair.RefreshToken)
+	assertInvalidToken(t, err)
+
+	assertInvalidToken(t, s.Logout(ctx, "unknown-token"))
+}
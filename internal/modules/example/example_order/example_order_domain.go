@@ -0,0 +1,166 @@
+package example_order
+
+import (
+	"go-template/internal/adapters/secondary/shipping"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Order คือพิมพ์เขียวหลักของคำสั่งซื้อ
+// ⭐️ เงินทุกจำนวนใช้ decimal.Decimal (ห้ามใช้ float64 เด็ดขาด! 0.1 + 0.2 != 0.3)
+type Order struct {
+	ID                uint
+	UserID            uint
+	OrderNumber       string
+	TotalAmount       decimal.Decimal
+	Status            string
+	TrackingNumber    string     // มีค่าตั้งแต่สถานะ shipped เป็นต้นไป
+	ShippingProvider  string     // ชื่อขนส่งใน shipping.Registry ("" = ขนส่งเริ่มต้น)
+	ShipmentStatus    string     // สถานะพัสดุล่าสุดจากขนส่ง (shipping.Status*)
+	ShipmentUpdatedAt *time.Time // เวลาที่เหตุการณ์ล่าสุดเกิดขึ้นฝั่งขนส่ง
+	ShippingAddress   *Address
+	Items             []*OrderItem
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
+
+// OrderItem คือรายการสินค้า 1 บรรทัดในคำสั่งซื้อ (ตาราง example_order_details)
+type OrderItem struct {
+	ID          uint
+	OrderID     uint
+	ProductSKU  string
+	ProductName string
+	Quantity    int
+	UnitPrice   decimal.Decimal
+	TotalPrice  decimal.Decimal
+}
+
+// Address คือที่อยู่สำหรับจัดส่ง (เก็บเป็น JSONB)
+type Address struct {
+	RecipientName string
+	Phone         string
+	Line1         string
+	Line2         string
+	District      string
+	Province      string
+	PostalCode    string
+	Country       string
+}
+
+// StatusHistory คือประวัติการเปลี่ยนสถานะ 1 ครั้ง (ตาราง example_order_status_history)
+type StatusHistory struct {
+	ID         uint
+	OrderID    uint
+	FromStatus string // "" = ตอนสร้าง Order
+	ToStatus   string
+	ActorID    *uint // nil = ระบบเป็นคนเปลี่ยน
+	ActorRole  string
+	Reason     string
+	CreatedAt  time.Time
+}
+
+// StatusChange คือคำขอเปลี่ยนสถานะ 1 ครั้ง
+type StatusChange struct {
+	ToStatus       string
+	Reason         string
+	TrackingNumber string // ต้องระบุเมื่อเปลี่ยนเป็น shipped
+}
+
+// Order statuses (ต้องตรงกับ CHECK constraint check_order_status)
+const (
+	StatusPending    = "pending"
+	StatusProcessing = "processing"
+	StatusShipped    = "shipped"
+	StatusCompleted  = "completed"
+	StatusCancelled  = "cancelled"
+	StatusRefunded   = "refunded"
+)
+
+// ActorRoleSystem คือ actor_role ในประวัติสถานะ เมื่อระบบเป็นคนเปลี่ยน (ไม่มีผู้ใช้)
+const ActorRoleSystem = "system"
+
+// Permissions ของโมดูลนี้ (ใช้คู่กับตารางสิทธิ์ auth.permissions ใน config)
+const (
+	PermissionOrdersRead   = "orders:read"   // ดูคำสั่งซื้อของผู้ใช้คนอื่น
+	PermissionOrdersList   = "orders:list"   // ดูรายการคำสั่งซื้อของผู้ใช้คนอื่น
+	PermissionOrdersManage = "orders:manage" // เปลี่ยนสถานะคำสั่งซื้อ (ฝั่งร้านค้า/หลังบ้าน)
+)
+
+// ====================================================================================
+// Status State Machine
+// ====================================================================================
+
+// statusTransitions คือ "แผนที่" ว่าจากสถานะไหนไปสถานะไหนได้บ้าง
+//
+//	pending ──► processing ──► shipped ──► completed ──► refunded
+//	   │             │
+//	   └──► cancelled ◄┘
+//
+// cancelled และ refunded เป็นสถานะสุดท้าย (ไปต่อไม่ได้แล้ว)
+var statusTransitions = map[string][]string{
+	StatusPending:    {StatusProcessing, StatusCancelled},
+	StatusProcessing: {StatusShipped, StatusCancelled},
+	StatusShipped:    {StatusCompleted},
+	StatusCompleted:  {StatusRefunded},
+	StatusCancelled:  {},
+	StatusRefunded:   {},
+}
+
+// IsValidStatus ตรวจว่าเป็นสถานะที่ระบบรู้จักหรือไม่
+func IsValidStatus(status string) bool {
+	_, ok := statusTransitions[status]
+	return ok
+}
+
+// AllowedTransitions คืนรายการสถานะที่ไปต่อได้จากสถานะปัจจุบัน
+func AllowedTransitions(from string) []string {
+	return append([]string{}, statusTransitions[from]...)
+}
+
+// CanTransition ตรวจว่าเปลี่ยนจาก from ไป to ได้หรือไม่
+func CanTransition(from, to string) bool {
+	for _, next := range statusTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// CalculateTotals คำนวณ total_price ของทุกรายการ และ total_amount ของทั้ง Order
+// ด้วยเลขทศนิยมแบบแม่นยำ (ปัดเศษ 2 ตำแหน่งแบบ half-up ตามหลักบัญชี)
+func (o *Order) CalculateTotals() {
+	total := decimal.Zero
+	for _, item := range o.Items {
+		item.TotalPrice = item.UnitPrice.Mul(decimal.NewFromInt(int64(item.Quantity))).Round(2)
+		total = total.Add(item.TotalPrice)
+	}
+	o.TotalAmount = total.Round(2)
+}
+
+// ====================================================================================
+// Shipping (Port)
+// ====================================================================================
+
+// ShippingProviders คือ "พอร์ต" ที่โมดูล Order ใช้หาขนส่งตามชื่อ (ปกติคือ *shipping.Registry)
+// Service ไม่รู้จักขนส่งเจ้าไหนเป็นการเฉพาะ การเพิ่มขนส่งใหม่จึงไม่ต้องแก้โมดูลนี้เลย
+type ShippingProviders interface {
+	Get(name string) (shipping.Provider, error)
+	DefaultName() string
+}
+
+// ShipmentUpdate คือการแจ้งสถานะพัสดุจากขนส่ง (เช่นจาก webhook) ที่ระบบเป็นคนนำมาใช้ ไม่ใช่ผู้ใช้
+type ShipmentUpdate struct {
+	Provider       string
+	TrackingNumber string
+	Status         string // shipping.Status*
+	OccurredAt     time.Time
+	Reference      string // ที่มาของการแจ้ง (เช่น webhook event id) ถูกบันทึกลงประวัติสถานะ
+}
+
+// ShipmentRequest คือคำขอจองการจัดส่งของ Order
+type ShipmentRequest struct {
+	ServiceCode string // ว่างได้ = บริการเริ่มต้นของขนส่ง
+	Parcels     []shipping.Parcel
+}
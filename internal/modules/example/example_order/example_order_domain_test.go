@@ -0,0 +1,94 @@
+package example_order
+
+import "testing"
+
+var allStatuses = []string{StatusPending, StatusProcessing, StatusShipped, StatusCompleted, StatusCancelled, StatusRefunded}
+
+func TestCanTransition(t *testing.T) {
+	// ทุกคู่ที่ไม่อยู่ในตารางนี้ต้องถูกปฏิเสธ (รวมถึงการเปลี่ยนไปสถานะเดิม)
+	allowed := map[[2]string]bool{
+		{StatusPending, StatusProcessing}:   true,
+		{StatusPending, StatusCancelled}:    true,
+		{StatusProcessing, StatusShipped}:   true,
+		{StatusProcessing, StatusCancelled}: true,
+		{StatusShipped, StatusCompleted}:    true,
+		{StatusCompleted, StatusRefunded}:   true,
+	}
+
+	for _, from := range allStatuses {
+		for _, to := range allStatuses {
+			want := allowed[[2]string{from, to}]
+			t.Run(from+"->"+to, func(t *testing.T) {
+				if got := CanTransition(from, to); got != want {
+					t.Errorf("CanTransition(%q, %q) = %v, want %v", from, to, got, want)
+				}
+			})
+		}
+	}
+}
+
+func TestTerminalStatuses(t *testing.T) {
+	tests := []struct {
+		from     string
+		terminal bool
+	}{
+		{from: StatusPending, terminal: false},
+		{from: StatusProcessing, terminal: false},
+		{from: StatusShipped, terminal: false},
+		{from: StatusCompleted, terminal: false}, // ยังคืนเงินได้
+		{from: StatusCancelled, terminal: true},
+		{from: StatusRefunded, terminal: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.from, func(t *testing.T) {
+			if got := len(AllowedTransitions(tt.from)) == 0; got != tt.terminal {
+				t.Errorf("terminal = %v, want %v (allowed: %v)", got, tt.terminal, AllowedTransitions(tt.from))
+			}
+		})
+	}
+}
+
+func TestCompletedCanOnlyBeRefunded(t *testing.T) {
+	for _, to := range allStatuses {
+		if want := to == StatusRefunded; CanTransition(StatusCompleted, to) != want {
+			t.Errorf("CanTransition(completed, %q) = %v, want %v", to, !want, want)
+		}
+	}
+}
+
+func TestUnknownStatuses(t *testing.T) {
+	tests := []struct {
+		name string
+		from string
+		to   string
+	}{
+		{name: "unknown source", from: "archived", to: StatusCancelled},
+		{name: "unknown target", from: StatusPending, to: "archived"},
+		{name: "empty statuses", from: "", to: ""},
+		{name: "case sensitive", from: "PENDING", to: StatusProcessing},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if CanTransition(tt.from, tt.to) {
+				t.Errorf("CanTransition(%q, %q) = true, want false", tt.from, tt.to)
+			}
+		})
+	}
+
+	for _, status := range allStatuses {
+		if !IsValidStatus(status) {
+			t.Errorf("IsValidStatus(%q) = false, want true", status)
+		}
+	}
+	if IsValidStatus("archived") {
+		t.Error(`IsValidStatus("archived") = true, want false`)
+	}
+}
+
+func TestAllowedTransitionsReturnsCopy(t *testing.T) {
+	next := AllowedTransitions(StatusPending)
+	next[0] = StatusRefunded
+	if CanTransition(StatusPending, StatusRefunded) {
+		t.Fatal("modifying the result of AllowedTransitions must not change the state machine")
+	}
+}
@@ -0,0 +1,532 @@
+package example_order
+
+import (
+	"go-template/internal/adapters/primary/http/middleware"
+	"go-template/internal/adapters/secondary/shipping"
+	"go-template/pkg/custom_errors"
+	"go-template/pkg/logger"
+	"go-template/pkg/response"
+	"go-template/pkg/validator"
+	"strings"
+	"time"
+
+	govalidator "github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v3"
+	"github.com/shopspring/decimal"
+)
+
+// ====================================================================================
+// DTOs (Data Transfer Objects)
+// ====================================================================================
+
+// CreateOrderRequest ไม่มี user_id เพราะเจ้าของ Order คือคนที่ login อยู่เสมอ
+// และไม่มี total_price/total_amount เพราะ Server เป็นคนคำนวณเอง (ไม่เชื่อตัวเลขจาก Client)
+type CreateOrderRequest struct {
+	Items            []*CreateOrderItemRequest `json:"items" validate:"required,min=1,max=100,dive,required"`
+	ShippingAddress  *AddressRequest           `json:"shipping_address" validate:"omitempty"`
+	ShippingProvider string                    `json:"shipping_provider" validate:"omitempty,max=50"` // ว่าง = ขนส่งเริ่มต้น
+}
+
+// CreateOrderItemRequest รับ unit_price ได้ทั้งแบบ string ("199.50") และ number (199.50)
+// แต่แนะนำให้ส่งเป็น string เพื่อไม่ให้เสียความแม่นยำระหว่างทาง
+type CreateOrderItemRequest struct {
+	ProductSKU  string          `json:"product_sku" validate:"required,max=100"`
+	ProductName string          `json:"product_name" validate:"required,max=255"`
+	Quantity    int             `json:"quantity" validate:"required,gte=1,lte=10000"`
+	UnitPrice   decimal.Decimal `json:"unit_price" validate:"required"`
+}
+
+type AddressRequest struct {
+	RecipientName string `json:"recipient_name" validate:"required,max=255"`
+	Phone         string `json:"phone" validate:"required,max=30"`
+	Line1         string `json:"line1" validate:"required,max=255"`
+	Line2         string `json:"line2" validate:"omitempty,max=255"`
+	District      string `json:"district" validate:"required,max=100"`
+	Province      string `json:"province" validate:"required,max=100"`
+	PostalCode    string `json:"postal_code" validate:"required,max=20"`
+	Country       string `json:"country" validate:"required,len=2"`
+}
+
+type ChangeStatusRequest struct {
+	Status         string `json:"status" validate:"required,oneof=pending processing shipped completed cancelled refunded"`
+	Reason         string `json:"reason" validate:"omitempty,max=500"`
+	TrackingNumber string `json:"tracking_number" validate:"omitempty,max=100"`
+}
+
+// ParcelRequest คือพัสดุ 1 กล่อง (น้ำหนักเป็นกรัม)
+type ParcelRequest struct {
+	WeightGrams int `json:"weight_grams" validate:"required,gte=1,lte=70000"`
+}
+
+type QuoteShippingRequest struct {
+	Parcels []*ParcelRequest `json:"parcels" validate:"required,min=1,max=20,dive,required"`
+}
+
+type CreateShipmentRequest struct {
+	ServiceCode string           `json:"service_code" validate:"omitempty,max=50"`
+	Parcels     []*ParcelRequest `json:"parcels" validate:"required,min=1,max=20,dive,required"`
+}
+
+type GetOrderByIDParams struct {
+	ID uint `uri:"id" validate:"required,gte=1"`
+}
+
+type ListOrdersQuery struct {
+	UserID *uint `query:"user_id" validate:"omitempty,gte=1"`
+	Limit  *int  `query:"limit" validate:"omitempty,gte=1,lte=100"`
+	Page   *int  `query:"page" validate:"omitempty,gte=1"`
+	Offset *int  `query:"offset" validate:"omitempty,gte=0"`
+}
+
+// ⭐️ เงินทุกจำนวนใน Response เป็น string (เช่น "1299.00") เพื่อไม่ให้ Client แปลงเป็น float แล้วเพี้ยน
+type Response struct {
+	ID               uint             `json:"id"`
+	UserID           uint             `json:"user_id"`
+	OrderNumber      string           `json:"order_number"`
+	TotalAmount      string           `json:"total_amount"`
+	Status           string           `json:"status"`
+	TrackingNumber   string           `json:"tracking_number,omitempty"`
+	ShippingProvider string           `json:"shipping_provider,omitempty"`
+	ShipmentStatus   string           `json:"shipment_status,omitempty"`
+	ShippingAddress  *AddressResponse `json:"shipping_address"`
+	Items            []*ItemResponse  `json:"items"`
+	CreatedAt        time.Time        `json:"created_at"`
+	UpdatedAt        time.Time        `json:"updated_at"`
+}
+
+type ItemResponse struct {
+	ID          uint   `json:"id"`
+	ProductSKU  string `json:"product_sku"`
+	ProductName string `json:"product_name"`
+	Quantity    int    `json:"quantity"`
+	UnitPrice   string `json:"unit_price"`
+	TotalPrice  string `json:"total_price"`
+}
+
+type AddressResponse struct {
+	RecipientName string `json:"recipient_name"`
+	Phone         string `json:"phone"`
+	Line1         string `json:"line1"`
+	Line2         string `json:"line2,omitempty"`
+	District      string `json:"district"`
+	Province      string `json:"province"`
+	PostalCode    string `json:"postal_code"`
+	Country       string `json:"country"`
+}
+
+type StatusHistoryResponse struct {
+	ID         uint      `json:"id"`
+	FromStatus *string   `json:"from_status"`
+	ToStatus   string    `json:"to_status"`
+	ActorID    *uint     `json:"actor_id"`
+	ActorRole  string    `json:"actor_role,omitempty"`
+	Reason     string    `json:"reason,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// TrackingResponse คือสถานะพัสดุในรูปแบบของเรา (ไม่ว่าขนส่งเจ้าไหนจะตอบมาแบบไหนก็ตาม)
+type TrackingResponse struct {
+	OrderID           uint       `json:"order_id"`
+	OrderNumber       string     `json:"order_number"`
+	Provider          string     `json:"provider"`
+	TrackingNumber    string     `json:"tracking_number"`
+	Status            string     `json:"status"`
+	CarrierStatus     string     `json:"carrier_status"`
+	Location          string     `json:"location,omitempty"`
+	EstimatedDelivery *time.Time `json:"estimated_delivery"`
+}
+
+type RateQuoteResponse struct {
+	Provider      string `json:"provider"`
+	ServiceCode   string `json:"service_code"`
+	ServiceName   string `json:"service_name"`
+	Amount        string `json:"amount"`
+	Currency      string `json:"currency"`
+	EstimatedDays int    `json:"estimated_days"`
+}
+
+type ShipmentResponse struct {
+	Order    *Response `json:"order"`
+	LabelURL string    `json:"label_url,omitempty"`
+}
+
+// ====================================================================================
+// Handler
+// ====================================================================================
+
+// handler คือ struct ที่ทำงานจริง
+type handler struct {
+	service         Service
+	log             logger.Logger
+	bangkokLocation *time.Location
+	validator       *govalidator.Validate
+}
+
+// NewExampleOrderHandler คือโรงงานสร้าง Handler
+func NewExampleOrderHandler(service Service, log logger.Logger, bangkokLocation *time.Location, validator *govalidator.Validate) *handler {
+	return &handler{
+		service:         service,
+		log:             log,
+		bangkokLocation: bangkokLocation,
+		validator:       validator,
+	}
+}
+
+// --- Handler Methods ---
+
+func (h *handler) CreateOrder(c fiber.Ctx) error {
+	req := new(CreateOrderRequest)
+	if err := c.Bind().Body(req); err != nil {
+		appErr := custom_errors.InvalidFormatError("Request body is not valid JSON", err.Error())
+		return response.Error(c, appErr)
+	}
+
+	if validationResult := validator.Validate(h.validator, req); !validationResult.IsValid {
+		appErr := custom_errors.ValidationError("ข้อมูลที่ส่งมาไม่ถูกต้อง", validationResult.Errors)
+		return response.Error(c, appErr)
+	}
+
+	createdOrder, serviceErr := h.service.CreateOrder(c, toDomain(req))
+	if serviceErr != nil {
+		return response.Error(c, serviceErr.(*custom_errors.AppError))
+	}
+
+	return response.Success(c, fiber.StatusCreated, "Order created successfully", h.toResponse(createdOrder), nil)
+}
+
+func (h *handler) GetOrderByID(c fiber.Ctx) error {
+	params, appErr := h.bindOrderIDParams(c)
+	if appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	order, serviceErr := h.service.GetOrderByID(c, params.ID)
+	if serviceErr != nil {
+		return response.Error(c, serviceErr.(*custom_errors.AppError))
+	}
+
+	return response.Success(c, fiber.StatusOK, "Order retrieved successfully", h.toResponse(order), nil)
+}
+
+func (h *handler) ListOrders(c fiber.Ctx) error {
+	query := new(ListOrdersQuery)
+	if err := c.Bind().Query(query); err != nil {
+		appErr := custom_errors.InvalidFormatError("Query parameter ไม่ถูกต้อง", err.Error())
+		return response.Error(c, appErr)
+	}
+
+	if validationResult := validator.Validate(h.validator, query); !validationResult.IsValid {
+		appErr := custom_errors.ValidationError("Query parameter ไม่ถูกต้อง", validationResult.Errors)
+		return response.Error(c, appErr)
+	}
+
+	// ไม่ระบุ user_id = ดูของตัวเอง
+	claims, ok := middleware.GetClaims(c)
+	if !ok {
+		return response.Error(c, custom_errors.UnauthorizedError("กรุณาเข้าสู่ระบบก่อนใช้งาน"))
+	}
+	userID := claims.UserID
+	if query.UserID != nil {
+		userID = *query.UserID
+	}
+
+	limit := 10
+	if query.Limit != nil {
+		limit = *query.Limit
+	}
+	offset := 0
+	if query.Offset != nil {
+		offset = *query.Offset
+	} else if query.Page != nil {
+		offset = (*query.Page - 1) * limit
+	}
+
+	orders, totalCount, serviceErr := h.service.ListOrdersByUser(c, userID, limit, offset)
+	if serviceErr != nil {
+		return response.Error(c, serviceErr.(*custom_errors.AppError))
+	}
+
+	pagination := response.NewPagePagination(totalCount, limit, offset)
+	return response.Success(c, fiber.StatusOK, "Orders retrieved successfully", h.toResponseList(orders), pagination)
+}
+
+func (h *handler) ChangeStatus(c fiber.Ctx) error {
+	params, appErr := h.bindOrderIDParams(c)
+	if appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	req := new(ChangeStatusRequest)
+	if err := c.Bind().Body(req); err != nil {
+		appErr := custom_errors.InvalidFormatError("Request body is not valid JSON", err.Error())
+		return response.Error(c, appErr)
+	}
+
+	if validationResult := validator.Validate(h.validator, req); !validationResult.IsValid {
+		appErr := custom_errors.ValidationError("ข้อมูลที่ส่งมาไม่ถูกต้อง", validationResult.Errors)
+		return response.Error(c, appErr)
+	}
+
+	change := &StatusChange{
+		ToStatus:       req.Status,
+		Reason:         req.Reason,
+		TrackingNumber: strings.TrimSpace(req.TrackingNumber),
+	}
+
+	order, serviceErr := h.service.ChangeStatus(c, params.ID, change)
+	if serviceErr != nil {
+		return response.Error(c, serviceErr.(*custom_errors.AppError))
+	}
+
+	return response.Success(c, fiber.StatusOK, "Order status updated successfully", h.toResponse(order), nil)
+}
+
+func (h *handler) GetStatusHistory(c fiber.Ctx) error {
+	params, appErr := h.bindOrderIDParams(c)
+	if appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	histories, serviceErr := h.service.GetStatusHistory(c, params.ID)
+	if serviceErr != nil {
+		return response.Error(c, serviceErr.(*custom_errors.AppError))
+	}
+
+	return response.Success(c, fiber.StatusOK, "Order status history retrieved successfully", h.toHistoryResponseList(histories), nil)
+}
+
+func (h *handler) GetTracking(c fiber.Ctx) error {
+	params, appErr := h.bindOrderIDParams(c)
+	if appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	order, info, serviceErr := h.service.GetTracking(c, params.ID)
+	if serviceErr != nil {
+		return response.Error(c, serviceErr.(*custom_errors.AppError))
+	}
+
+	var estimatedDelivery *time.Time
+	if info.EstimatedDelivery != nil {
+		t := info.EstimatedDelivery.In(h.bangkokLocation)
+		estimatedDelivery = &t
+	}
+
+	responsePayload := &TrackingResponse{
+		OrderID:           order.ID,
+		OrderNumber:       order.OrderNumber,
+		Provider:          info.Provider,
+		TrackingNumber:    order.TrackingNumber,
+		Status:            info.Status,
+		CarrierStatus:     info.CarrierStatus,
+		Location:          info.Location,
+		EstimatedDelivery: estimatedDelivery,
+	}
+	return response.Success(c, fiber.StatusOK, "Shipment tracking retrieved successfully", responsePayload, nil)
+}
+
+func (h *handler) QuoteShipping(c fiber.Ctx) error {
+	params, appErr := h.bindOrderIDParams(c)
+	if appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	req := new(QuoteShippingRequest)
+	if err := c.Bind().Body(req); err != nil {
+		appErr := custom_errors.InvalidFormatError("Request body is not valid JSON", err.Error())
+		return response.Error(c, appErr)
+	}
+
+	if validationResult := validator.Validate(h.validator, req); !validationResult.IsValid {
+		appErr := custom_errors.ValidationError("ข้อมูลที่ส่งมาไม่ถูกต้อง", validationResult.Errors)
+		return response.Error(c, appErr)
+	}
+
+	quotes, serviceErr := h.service.QuoteShipping(c, params.ID, toParcels(req.Parcels))
+	if serviceErr != nil {
+		return response.Error(c, serviceErr.(*custom_errors.AppError))
+	}
+
+	responsePayloads := make([]*RateQuoteResponse, 0, len(quotes))
+	for _, quote := range quotes {
+		responsePayloads = append(responsePayloads, &RateQuoteResponse{
+			Provider:      quote.Provider,
+			ServiceCode:   quote.ServiceCode,
+			ServiceName:   quote.ServiceName,
+			Amount:        quote.Amount.StringFixed(2),
+			Currency:      quote.Currency,
+			EstimatedDays: quote.EstimatedDays,
+		})
+	}
+	return response.Success(c, fiber.StatusOK, "Shipping rates retrieved successfully", responsePayloads, nil)
+}
+
+func (h *handler) CreateShipment(c fiber.Ctx) error {
+	params, appErr := h.bindOrderIDParams(c)
+	if appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	req := new(CreateShipmentRequest)
+	if err := c.Bind().Body(req); err != nil {
+		appErr := custom_errors.InvalidFormatError("Request body is not valid JSON", err.Error())
+		return response.Error(c, appErr)
+	}
+
+	if validationResult := validator.Validate(h.validator, req); !validationResult.IsValid {
+		appErr := custom_errors.ValidationError("ข้อมูลที่ส่งมาไม่ถูกต้อง", validationResult.Errors)
+		return response.Error(c, appErr)
+	}
+
+	shipmentReq := &ShipmentRequest{
+		ServiceCode: req.ServiceCode,
+		Parcels:     toParcels(req.Parcels),
+	}
+
+	order, label, serviceErr := h.service.CreateShipment(c, params.ID, shipmentReq)
+	if serviceErr != nil {
+		return response.Error(c, serviceErr.(*custom_errors.AppError))
+	}
+
+	responsePayload := &ShipmentResponse{
+		Order:    h.toResponse(order),
+		LabelURL: label.LabelURL,
+	}
+	return response.Success(c, fiber.StatusCreated, "Shipment created successfully", responsePayload, nil)
+}
+
+// RegisterRoutes ลงทะเบียน routes ทั้งหมดของโมดูลนี้ (ทุก route ต้องเข้าสู่ระบบก่อน)
+func (h *handler) RegisterRoutes(router fiber.Router, authMiddleware fiber.Handler) {
+	orderRouter := router.Group("/orders", authMiddleware)
+
+	orderRouter.Post("", h.CreateOrder)
+	orderRouter.Get("", h.ListOrders)
+	orderRouter.Get("/:id", h.GetOrderByID)
+	orderRouter.Patch("/:id/status", h.ChangeStatus)
+	orderRouter.Get("/:id/history", h.GetStatusHistory)
+	orderRouter.Get("/:id/tracking", h.GetTracking)
+	orderRouter.Post("/:id/shipping/quotes", h.QuoteShipping)
+	orderRouter.Post("/:id/shipment", h.CreateShipment)
+}
+
+// --- Private Helpers ---
+
+// bindOrderIDParams อ่านและตรวจสอบ :id จาก URL
+func (h *handler) bindOrderIDParams(c fiber.Ctx) (*GetOrderByIDParams, *custom_errors.AppError) {
+	params := new(GetOrderByIDParams)
+	if err := c.Bind().URI(params); err != nil {
+		return nil, custom_errors.ValidationError("ID ที่ส่งมาไม่ถูกต้อง", fiber.Map{"id": "must be a positive integer"})
+	}
+
+	if validationResult := validator.Validate(h.validator, params); !validationResult.IsValid {
+		return nil, custom_errors.ValidationError("ID ที่ส่งมาไม่ถูกต้อง", validationResult.Errors)
+	}
+	return params, nil
+}
+
+func toDomain(req *CreateOrderRequest) *Order {
+	order := &Order{
+		ShippingProvider: strings.ToLower(strings.TrimSpace(req.ShippingProvider)),
+		Items:            make([]*OrderItem, 0, len(req.Items)),
+	}
+	for _, item := range req.Items {
+		order.Items = append(order.Items, &OrderItem{
+			ProductSKU:  item.ProductSKU,
+			ProductName: item.ProductName,
+			Quantity:    item.Quantity,
+			UnitPrice:   item.UnitPrice,
+		})
+	}
+	if a := req.ShippingAddress; a != nil {
+		order.ShippingAddress = &Address{
+			RecipientName: a.RecipientName,
+			Phone:         a.Phone,
+			Line1:         a.Line1,
+			Line2:         a.Line2,
+			District:      a.District,
+			Province:      a.Province,
+			PostalCode:    a.PostalCode,
+			Country:       a.Country,
+		}
+	}
+	return order
+}
+
+func toParcels(reqs []*ParcelRequest) []shipping.Parcel {
+	parcels := make([]shipping.Parcel, 0, len(reqs))
+	for _, p := range reqs {
+		parcels = append(parcels, shipping.Parcel{WeightGrams: p.WeightGrams})
+	}
+	return parcels
+}
+
+func (h *handler) toResponse(o *Order) *Response {
+	items := make([]*ItemResponse, 0, len(o.Items))
+	for _, item := range o.Items {
+		items = append(items, &ItemResponse{
+			ID:          item.ID,
+			ProductSKU:  item.ProductSKU,
+			ProductName: item.ProductName,
+			Quantity:    item.Quantity,
+			UnitPrice:   item.UnitPrice.StringFixed(2),
+			TotalPrice:  item.TotalPrice.StringFixed(2),
+		})
+	}
+
+	var address *AddressResponse
+	if a := o.ShippingAddress; a != nil {
+		address = &AddressResponse{
+			RecipientName: a.RecipientName,
+			Phone:         a.Phone,
+			Line1:         a.Line1,
+			Line2:         a.Line2,
+			District:      a.District,
+			Province:      a.Province,
+			PostalCode:    a.PostalCode,
+			Country:       a.Country,
+		}
+	}
+
+	return &Response{
+		ID:               o.ID,
+		UserID:           o.UserID,
+		OrderNumber:      o.OrderNumber,
+		TotalAmount:      o.TotalAmount.StringFixed(2),
+		Status:           o.Status,
+		TrackingNumber:   o.TrackingNumber,
+		ShippingProvider: o.ShippingProvider,
+		ShipmentStatus:   o.ShipmentStatus,
+		ShippingAddress:  address,
+		Items:            items,
+		CreatedAt:        o.CreatedAt.In(h.bangkokLocation),
+		UpdatedAt:        o.UpdatedAt.In(h.bangkokLocation),
+	}
+}
+
+func (h *handler) toResponseList(orders []*Order) []*Response {
+	responses := make([]*Response, 0, len(orders))
+	for _, o := range orders {
+		responses = append(responses, h.toResponse(o))
+	}
+	return responses
+}
+
+func (h *handler) toHistoryResponseList(histories []*StatusHistory) []*StatusHistoryResponse {
+	responses := make([]*StatusHistoryResponse, 0, len(histories))
+	for _, history := range histories {
+		var fromStatus *string
+		if history.FromStatus != "" {
+			fromStatus = &history.FromStatus
+		}
+		responses = append(responses, &StatusHistoryResponse{
+			ID:         history.ID,
+			FromStatus: fromStatus,
+			ToStatus:   history.ToStatus,
+			ActorID:    history.ActorID,
+			ActorRole:  history.ActorRole,
+			Reason:     history.Reason,
+			CreatedAt:  history.CreatedAt.In(h.bangkokLocation),
+		})
+	}
+	return responses
+}
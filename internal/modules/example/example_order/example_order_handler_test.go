@@ -0,0 +1,56 @@
+package example_order
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go-template/pkg/custom_errors"
+	"go-template/pkg/validator"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+func TestCreateOrderRequiresUnitPrice(t *testing.T) {
+	// ต้องถูกตีกลับตั้งแต่ handler จึงไม่ต้องมี service
+	h := NewExampleOrderHandler(nil, nil, nil, validator.New())
+	app := fiber.New()
+	app.Post("/orders", h.CreateOrder)
+
+	tests := []struct {
+		name string
+		item string
+	}{
+		{name: "unit_price missing", item: `{"product_sku": "SKU-1", "product_name": "Mug", "quantity": 1}`},
+		{name: "unit_price null", item: `{"product_sku": "SKU-1", "product_name": "Mug", "quantity": 1, "unit_price": null}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(fiber.MethodPost, "/orders", strings.NewReader(`{"items": [`+tt.item+`]}`))
+			req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			var body struct {
+				Error struct {
+					Code    string                            `json:"code"`
+					Details []validator.ValidationErrorDetail `json:"details"`
+				} `json:"error"`
+			}
+			if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != fiber.StatusBadRequest || body.Error.Code != custom_errors.ErrValidation {
+				t.Fatalf("status = %d, code = %q, want 400 %s", resp.StatusCode, body.Error.Code, custom_errors.ErrValidation)
+			}
+			if len(body.Error.Details) != 1 || body.Error.Details[0].Field != "UnitPrice" {
+				t.Errorf("details = %+v, want one error on UnitPrice", body.Error.Details)
+			}
+		})
+	}
+}
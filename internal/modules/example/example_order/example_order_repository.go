@@ -0,0 +1,397 @@
+package example_order
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"go-template/pkg/logger"
+	"go-template/pkg/platform/postgres"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// ErrStatusChanged ถูกคืนจาก UpdateStatus เมื่อสถานะใน DB ไม่ใช่สถานะที่เราอ่านมา (มีคนเปลี่ยนตัดหน้าไปแล้ว)
+var ErrStatusChanged = errors.New("order status changed concurrently")
+
+// Repository คือ "สัญญา" ที่ Service จะเรียกใช้
+type Repository interface {
+	Create(ctx context.Context, o *Order) error
+	GetByID(ctx context.Context, id uint) (*Order, error)
+	ListByUser(ctx context.Context, userID uint, limit, offset int) ([]*Order, int, error)
+	UpdateStatus(ctx context.Context, o *Order, fromStatus string, history *StatusHistory) error
+	ListStatusHistory(ctx context.Context, orderID uint) ([]*StatusHistory, error)
+	GetByTrackingNumber(ctx context.Context, provider, trackingNumber string, includeUnassigned bool) (*Order, error)
+	UpdateShipmentStatus(ctx context.Context, id uint, status string, occurredAt time.Time) (bool, error)
+}
+
+// Model คือ "ชุดเกราะ" สำหรับ GORM (ตาราง example_orders)
+type Model struct {
+	gorm.Model
+	UserID            uint            `gorm:"not null;index"`
+	OrderNumber       string          `gorm:"uniqueIndex;not null"`
+	TotalAmount       decimal.Decimal `gorm:"type:decimal(12,2);not null"`
+	Status            string          `gorm:"not null;default:pending"`
+	TrackingNumber    *string
+	ShippingProvider  *string
+	ShipmentStatus    *string
+	ShipmentUpdatedAt *time.Time
+	ShippingAddress   []byte      `gorm:"type:jsonb"`
+	Items             []ItemModel `gorm:"foreignKey:OrderID"`
+}
+
+func (Model) TableName() string {
+	return "example_orders"
+}
+
+// ItemModel คือ "ชุดเกราะ" สำหรับ GORM (ตาราง example_order_details)
+type ItemModel struct {
+	gorm.Model
+	OrderID     uint            `gorm:"not null;index"`
+	ProductSKU  string          `gorm:"column:product_sku;not null"`
+	ProductName string          `gorm:"not null"`
+	Quantity    int             `gorm:"not null"`
+	UnitPrice   decimal.Decimal `gorm:"type:decimal(10,2);not null"`
+	TotalPrice  decimal.Decimal `gorm:"type:decimal(12,2);not null"`
+}
+
+func (ItemModel) TableName() string {
+	return "example_order_details"
+}
+
+// HistoryModel คือ "ชุดเกราะ" สำหรับ GORM (ตาราง example_order_status_history)
+// เป็นตาราง append-only จึงไม่ใช้ gorm.Model (ไม่มี updated_at / deleted_at)
+type HistoryModel struct {
+	ID         uint      `gorm:"primarykey"`
+	CreatedAt  time.Time `gorm:"not null"`
+	OrderID    uint      `gorm:"not null;index"`
+	FromStatus *string
+	ToStatus   string `gorm:"not null"`
+	ActorID    *uint
+	ActorRole  *string
+	Reason     *string
+}
+
+func (HistoryModel) TableName() string {
+	return "example_order_status_history"
+}
+
+// addressJSON คือรูปแบบที่ใช้เก็บ Address ลงคอลัมน์ JSONB
+// (แยกออกมาเพื่อให้ Domain ไม่ต้องมี json tags)
+type addressJSON struct {
+	RecipientName string `json:"recipient_name"`
+	Phone         string `json:"phone"`
+	Line1         string `json:"line1"`
+	Line2         string `json:"line2,omitempty"`
+	District      string `json:"district"`
+	Province      string `json:"province"`
+	PostalCode    string `json:"postal_code"`
+	Country       string `json:"country"`
+}
+
+// repository คือ struct ที่ทำงานจริง
+type repository struct {
+	db  *gorm.DB
+	log logger.Logger
+}
+
+// NewExampleOrderRepository คือโรงงานสร้าง Repository
+func NewExampleOrderRepository(db *gorm.DB, log logger.Logger) Repository {
+	return &repository{db: db, log: log}
+}
+
+// --- Implementation ---
+
+// Create บันทึก Order พร้อมรายการสินค้าทั้งหมดใน Transaction เดียว
+// ถ้าบันทึกรายการไหนไม่สำเร็จ ทุกอย่างจะถูก rollback ไม่มี Order ครึ่งๆ กลางๆ
+func (r *repository) Create(ctx context.Context, o *Order) error {
+	gormModel, err := toGORM(o)
+	if err != nil {
+		return err
+	}
+
+	err = postgres.DB(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		// 1. บันทึกหัว Order ก่อน (ยังไม่บันทึก Items เพื่อให้เราคุมลำดับเอง)
+		if err := tx.Omit("Items").Create(gormModel).Error; err != nil {
+			return err
+		}
+
+		// 2. ผูก Items เข้ากับ Order ที่เพิ่งได้ ID มา แล้วบันทึก
+		for i := range gormModel.Items {
+			gormModel.Items[i].OrderID = gormModel.ID
+		}
+		if len(gormModel.Items) > 0 {
+			if err := tx.Create(&gormModel.Items).Error; err != nil {
+				return err
+			}
+		}
+
+		// 3. บันทึกประวัติสถานะแรก (ผู้สร้างคือเจ้าของ Order)
+		initial := &StatusHistory{OrderID: gormModel.ID, ToStatus: gormModel.Status, ActorID: &gormModel.UserID}
+		return tx.Create(historyToGORM(initial)).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	created, err := gormModel.toDomain()
+	if err != nil {
+		return err
+	}
+	*o = *created
+	return nil
+}
+
+func (r *repository) GetByID(ctx context.Context, id uint) (*Order, error) {
+	var gormModel Model
+	result := postgres.DB(ctx, r.db).Preload("Items", orderItemsByID).First(&gormModel, id)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return gormModel.toDomain()
+}
+
+// ListByUser ดึงคำสั่งซื้อของผู้ใช้คนหนึ่งแบบแบ่งหน้า (ใหม่สุดขึ้นก่อน)
+func (r *repository) ListByUser(ctx context.Context, userID uint, limit, offset int) ([]*Order, int, error) {
+	var gormModels []Model
+	var totalCount int64
+
+	query := postgres.DB(ctx, r.db).Model(&Model{}).Where("user_id = ?", userID)
+	if err := query.Count(&totalCount).Error; err != nil {
+		return nil, 0, err
+	}
+
+	result := query.Preload("Items", orderItemsByID).
+		Order("created_at desc").Order("id desc").
+		Limit(limit).Offset(offset).
+		Find(&gormModels)
+	if result.Error != nil {
+		return nil, 0, result.Error
+	}
+
+	orders := make([]*Order, 0, len(gormModels))
+	for _, model := range gormModels {
+		order, err := model.toDomain()
+		if err != nil {
+			return nil, 0, err
+		}
+		orders = append(orders, order)
+	}
+	return orders, int(totalCount), nil
+}
+
+// UpdateStatus เปลี่ยนสถานะ (พร้อมข้อมูลการจัดส่งใน o) และบันทึกประวัติใน Transaction เดียว
+// ⭐️ ใช้ WHERE status = fromStatus เป็น optimistic lock: ถ้ามีคนเปลี่ยนตัดหน้าไปแล้ว จะได้ ErrStatusChanged
+func (r *repository) UpdateStatus(ctx context.Context, o *Order, fromStatus string, history *StatusHistory) error {
+	return postgres.DB(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&Model{}).
+			Where("id = ? AND status = ?", o.ID, fromStatus).
+			Updates(map[string]interface{}{
+				"status":            history.ToStatus,
+				"tracking_number":   nullableString(o.TrackingNumber),
+				"shipping_provider": nullableString(o.ShippingProvider),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrStatusChanged
+		}
+
+		history.OrderID = o.ID
+		history.FromStatus = fromStatus
+		historyModel := historyToGORM(history)
+		if err := tx.Create(historyModel).Error; err != nil {
+			return err
+		}
+		*history = *historyModel.toDomain()
+		return nil
+	})
+}
+
+// ListStatusHistory ดึงประวัติสถานะทั้งหมดของ Order (เก่าสุดขึ้นก่อน)
+func (r *repository) ListStatusHistory(ctx context.Context, orderID uint) ([]*StatusHistory, error) {
+	var historyModels []HistoryModel
+	result := postgres.DB(ctx, r.db).Where("order_id = ?", orderID).Order("created_at asc").Order("id asc").Find(&historyModels)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	histories := make([]*StatusHistory, 0, len(historyModels))
+	for _, model := range historyModels {
+		histories = append(histories, model.toDomain())
+	}
+	return histories, nil
+}
+
+// GetByTrackingNumber หา Order จากเลขพัสดุของขนส่งเจ้านั้น
+// includeUnassigned = รวม Order เก่าที่ยังไม่มีข้อมูลขนส่งด้วย (ใช้เมื่อ provider คือขนส่งเริ่มต้น)
+func (r *repository) GetByTrackingNumber(ctx context.Context, provider, trackingNumber string, includeUnassigned bool) (*Order, error) {
+	query := postgres.DB(ctx, r.db).Preload("Items", orderItemsByID).Where("tracking_number = ?", trackingNumber)
+	if includeUnassigned {
+		query = query.Where("shipping_provider = ? OR shipping_provider IS NULL", provider)
+	} else {
+		query = query.Where("shipping_provider = ?", provider)
+	}
+
+	var gormModel Model
+	if err := query.First(&gormModel).Error; err != nil {
+		return nil, err
+	}
+	return gormModel.toDomain()
+}
+
+// UpdateShipmentStatus บันทึกสถานะพัสดุล่าสุด
+// ⭐️ เหตุการณ์ที่เก่ากว่าที่บันทึกไว้จะถูกข้าม (webhook ไม่รับประกันลำดับ) คืน false เมื่อถูกข้าม
+func (r *repository) UpdateShipmentStatus(ctx context.Context, id uint, status string, occurredAt time.Time) (bool, error) {
+	result := postgres.DB(ctx, r.db).Model(&Model{}).
+		Where("id = ? AND (shipment_updated_at IS NULL OR shipment_updated_at <= ?)", id, occurredAt).
+		Updates(map[string]interface{}{
+			"shipment_status":     status,
+			"shipment_updated_at": occurredAt,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// orderItemsByID ทำให้รายการสินค้าเรียงตามลำดับที่ถูกสร้างเสมอ
+func orderItemsByID(db *gorm.DB) *gorm.DB {
+	return db.Order("id asc")
+}
+
+// --- Translators ---
+
+func toGORM(o *Order) (*Model, error) {
+	var shippingAddress []byte
+	if o.ShippingAddress != nil {
+		a := o.ShippingAddress
+		encoded, err := json.Marshal(addressJSON{
+			RecipientName: a.RecipientName,
+			Phone:         a.Phone,
+			Line1:         a.Line1,
+			Line2:         a.Line2,
+			District:      a.District,
+			Province:      a.Province,
+			PostalCode:    a.PostalCode,
+			Country:       a.Country,
+		})
+		if err != nil {
+			return nil, err
+		}
+		shippingAddress = encoded
+	}
+
+	items := make([]ItemModel, 0, len(o.Items))
+	for _, item := range o.Items {
+		items = append(items, ItemModel{
+			OrderID:     item.OrderID,
+			ProductSKU:  item.ProductSKU,
+			ProductName: item.ProductName,
+			Quantity:    item.Quantity,
+			UnitPrice:   item.UnitPrice,
+			TotalPrice:  item.TotalPrice,
+		})
+	}
+
+	return &Model{
+		UserID:           o.UserID,
+		OrderNumber:      o.OrderNumber,
+		TotalAmount:      o.TotalAmount,
+		Status:           o.Status,
+		TrackingNumber:   nullableString(o.TrackingNumber),
+		ShippingProvider: nullableString(o.ShippingProvider),
+		ShippingAddress:  shippingAddress,
+		Items:            items,
+	}, nil
+}
+
+func (m *Model) toDomain() (*Order, error) {
+	var shippingAddress *Address
+	if len(m.ShippingAddress) > 0 {
+		var a addressJSON
+		if err := json.Unmarshal(m.ShippingAddress, &a); err != nil {
+			return nil, err
+		}
+		shippingAddress = &Address{
+			RecipientName: a.RecipientName,
+			Phone:         a.Phone,
+			Line1:         a.Line1,
+			Line2:         a.Line2,
+			District:      a.District,
+			Province:      a.Province,
+			PostalCode:    a.PostalCode,
+			Country:       a.Country,
+		}
+	}
+
+	items := make([]*OrderItem, 0, len(m.Items))
+	for _, item := range m.Items {
+		items = append(items, &OrderItem{
+			ID:          item.ID,
+			OrderID:     item.OrderID,
+			ProductSKU:  item.ProductSKU,
+			ProductName: item.ProductName,
+			Quantity:    item.Quantity,
+			UnitPrice:   item.UnitPrice,
+			TotalPrice:  item.TotalPrice,
+		})
+	}
+
+	return &Order{
+		ID:                m.ID,
+		UserID:            m.UserID,
+		OrderNumber:       m.OrderNumber,
+		TotalAmount:       m.TotalAmount,
+		Status:            m.Status,
+		TrackingNumber:    stringValue(m.TrackingNumber),
+		ShippingProvider:  stringValue(m.ShippingProvider),
+		ShipmentStatus:    stringValue(m.ShipmentStatus),
+		ShipmentUpdatedAt: m.ShipmentUpdatedAt,
+		ShippingAddress:   shippingAddress,
+		Items:             items,
+		CreatedAt:         m.CreatedAt,
+		UpdatedAt:         m.UpdatedAt,
+	}, nil
+}
+
+func historyToGORM(h *StatusHistory) *HistoryModel {
+	return &HistoryModel{
+		OrderID:    h.OrderID,
+		FromStatus: nullableString(h.FromStatus),
+		ToStatus:   h.ToStatus,
+		ActorID:    h.ActorID,
+		ActorRole:  nullableString(h.ActorRole),
+		Reason:     nullableString(h.Reason),
+	}
+}
+
+func (m *HistoryModel) toDomain() *StatusHistory {
+	return &StatusHistory{
+		ID:         m.ID,
+		OrderID:    m.OrderID,
+		FromStatus: stringValue(m.FromStatus),
+		ToStatus:   m.ToStatus,
+		ActorID:    m.ActorID,
+		ActorRole:  stringValue(m.ActorRole),
+		Reason:     stringValue(m.Reason),
+		CreatedAt:  m.CreatedAt,
+	}
+}
+
+// nullableString แปลง "" เป็น NULL เพื่อให้ DB แยก "ไม่มีค่า" ออกจาก "ค่าว่าง" ได้
+func nullableString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
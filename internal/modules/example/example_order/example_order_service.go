@@ -0,0 +1,455 @@
+package example_order
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"go-template/internal/adapters/secondary/shipping"
+	"go-template/pkg/auth"
+	"go-template/pkg/custom_errors"
+	"go-template/pkg/logger"
+	"go-template/pkg/validator"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// ขีดจำกัดทางธุรกิจ (ให้สอดคล้องกับขนาดคอลัมน์ DECIMAL ในตาราง)
+var (
+	maxUnitPrice   = decimal.RequireFromString("99999999.99")   // DECIMAL(10, 2)
+	maxTotalAmount = decimal.RequireFromString("9999999999.99") // DECIMAL(12, 2)
+)
+
+// จำนวนครั้งที่จะลองสร้างเลข Order ใหม่ ถ้าบังเอิญชนกับเลขที่มีอยู่แล้ว
+const maxOrderNumberAttempts = 3
+
+// Service คือ "สัญญา" ที่ Handler จะเรียกใช้
+type Service interface {
+	CreateOrder(ctx context.Context, orderToCreate *Order) (*Order, error)
+	GetOrderByID(ctx context.Context, id uint) (*Order, error)
+	ListOrdersByUser(ctx context.Context, userID uint, limit, offset int) ([]*Order, int, error)
+	ChangeStatus(ctx context.Context, id uint, change *StatusChange) (*Order, error)
+	GetStatusHistory(ctx context.Context, id uint) ([]*StatusHistory, error)
+	GetTracking(ctx context.Context, id uint) (*Order, *shipping.TrackingInfo, error)
+	QuoteShipping(ctx context.Context, id uint, parcels []shipping.Parcel) ([]*shipping.RateQuote, error)
+	CreateShipment(ctx context.Context, id uint, req *ShipmentRequest) (*Order, *shipping.Label, error)
+	ApplyShipmentUpdate(ctx context.Context, update *ShipmentUpdate) error
+}
+
+// OrderNumberGenerator คือ "เครื่องออกเลข" คำสั่งซื้อ
+type OrderNumberGenerator interface {
+	Generate(now time.Time) (string, error)
+}
+
+// service คือ struct ที่ทำงานจริง
+type service struct {
+	repo            Repository
+	rbac            *auth.RBAC
+	numberGenerator OrderNumberGenerator
+	shipping        ShippingProviders
+	log             logger.Logger
+}
+
+// NewExampleOrderService คือโรงงานสร้าง Service
+func NewExampleOrderService(repo Repository, rbac *auth.RBAC, numberGenerator OrderNumberGenerator, shippingProviders ShippingProviders, log logger.Logger) Service {
+	return &service{repo: repo, rbac: rbac, numberGenerator: numberGenerator, shipping: shippingProviders, log: log}
+}
+
+// --- Implementation ---
+
+// CreateOrder สร้างคำสั่งซื้อของผู้ใช้ที่ login อยู่ พร้อมรายการสินค้า
+func (s *service) CreateOrder(ctx context.Context, orderToCreate *Order) (*Order, error) {
+	// 1. เจ้าของ Order คือคนที่ login อยู่เสมอ (ไม่เชื่อ user_id จาก Client)
+	claims, ok := auth.ClaimsFromContext(ctx)
+	if !ok {
+		return nil, custom_errors.UnauthorizedError("กรุณาเข้าสู่ระบบก่อนใช้งาน")
+	}
+
+	// 2. ตรวจสอบราคาและคำนวณยอดรวมด้วยเลขทศนิยมแบบแม่นยำ
+	if details := validateItems(orderToCreate.Items); len(details) > 0 {
+		return nil, custom_errors.ValidationError("รายการสินค้าไม่ถูกต้อง", details)
+	}
+	orderToCreate.CalculateTotals()
+	if orderToCreate.TotalAmount.GreaterThan(maxTotalAmount) {
+		return nil, custom_errors.ValidationError("ยอดรวมของคำสั่งซื้อเกินกว่าที่ระบบรองรับ", orderToCreate.TotalAmount.StringFixed(2))
+	}
+
+	// 3. เติมข้อมูลที่เหลือตามกฎธุรกิจ (ไม่ระบุขนส่งมา = ใช้ขนส่งเริ่มต้นจาก config)
+	orderToCreate.UserID = claims.UserID
+	orderToCreate.Status = StatusPending
+	if orderToCreate.ShippingProvider == "" {
+		orderToCreate.ShippingProvider = s.shipping.DefaultName()
+	}
+	provider, err := s.shipping.Get(orderToCreate.ShippingProvider)
+	if err != nil {
+		return nil, err
+	}
+	orderToCreate.ShippingProvider = provider.Name()
+
+	// 4. บันทึก (ถ้าเลข Order ชนกับของเดิม ให้ออกเลขใหม่แล้วลองอีกครั้ง)
+	for attempt := 1; ; attempt++ {
+		orderNumber, err := s.numberGenerator.Generate(time.Now())
+		if err != nil {
+			return nil, custom_errors.SystemErrorWithDetails("ไม่สามารถออกเลขคำสั่งซื้อได้", err.Error())
+		}
+		orderToCreate.OrderNumber = orderNumber
+
+		err = s.repo.Create(ctx, orderToCreate)
+		if err == nil {
+			break
+		}
+		if errors.Is(err, gorm.ErrDuplicatedKey) && attempt < maxOrderNumberAttempts {
+			s.log.WithContext(ctx).Warn("Order number collision, retrying", "order_number", orderNumber, "attempt", attempt)
+			continue
+		}
+		return nil, custom_errors.SystemErrorWithDetails("ไม่สามารถสร้างคำสั่งซื้อได้", err.Error())
+	}
+
+	s.log.WithContext(ctx).Info("Order created", "order_id", orderToCreate.ID, "order_number", orderToCreate.OrderNumber, "user_id", orderToCreate.UserID)
+	return orderToCreate, nil
+}
+
+// GetOrderByID ดูคำสั่งซื้อ (เจ้าของดูได้เสมอ คนอื่นต้องมี permission)
+func (s *service) GetOrderByID(ctx context.Context, id uint) (*Order, error) {
+	order, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, custom_errors.NotFoundError(fmt.Sprintf("ไม่พบคำสั่งซื้อ ID: %d", id))
+		}
+		return nil, custom_errors.SystemErrorWithDetails("เกิดข้อผิดพลาดในการค้นหาคำสั่งซื้อ", err.Error())
+	}
+
+	if err := s.rbac.RequireSelfOrPermission(ctx, order.UserID, PermissionOrdersRead); err != nil {
+		// ⭐️ ตอบว่า "ไม่พบ" แทน "ไม่มีสิทธิ์" เพื่อไม่ให้คนอื่นไล่เดาเลข ID ของ Order ได้
+		if appErr, ok := err.(*custom_errors.AppError); ok && appErr.Code == custom_errors.ErrPermissionDenied {
+			return nil, custom_errors.NotFoundError(fmt.Sprintf("ไม่พบคำสั่งซื้อ ID: %d", id))
+		}
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// ListOrdersByUser ดูรายการคำสั่งซื้อของผู้ใช้ (ของตัวเองได้เสมอ ของคนอื่นต้องมี permission)
+func (s *service) ListOrdersByUser(ctx context.Context, userID uint, limit, offset int) ([]*Order, int, error) {
+	if err := s.rbac.RequireSelfOrPermission(ctx, userID, PermissionOrdersList); err != nil {
+		return nil, 0, err
+	}
+
+	orders, totalCount, err := s.repo.ListByUser(ctx, userID, limit, offset)
+	if err != nil {
+		return nil, 0, custom_errors.SystemErrorWithDetails("เกิดข้อผิดพลาดในการดึงข้อมูลคำสั่งซื้อ", err.Error())
+	}
+	return orders, totalCount, nil
+}
+
+// ChangeStatus เปลี่ยนสถานะ Order ตาม State Machine และบันทึกประวัติ
+// เจ้าของยกเลิก Order ของตัวเองได้ตอนยังเป็น pending, การเปลี่ยนอื่นๆ ต้องมี orders:manage
+func (s *service) ChangeStatus(ctx context.Context, id uint, change *StatusChange) (*Order, error) {
+	claims, ok := auth.ClaimsFromContext(ctx)
+	if !ok {
+		return nil, custom_errors.UnauthorizedError("กรุณาเข้าสู่ระบบก่อนใช้งาน")
+	}
+
+	// 1. ค้นหา (และตรวจว่ามองเห็น Order นี้ได้) ก่อน
+	order, err := s.GetOrderByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. ตรวจสิทธิ์
+	isOwnerCancelling := order.UserID == claims.UserID && order.Status == StatusPending && change.ToStatus == StatusCancelled
+	if !isOwnerCancelling {
+		if err := s.rbac.RequirePermission(ctx, PermissionOrdersManage); err != nil {
+			return nil, err
+		}
+	}
+
+	// 3. ของที่ถูกส่งออกไปแล้วต้องมีเลขพัสดุเสมอ (ไม่งั้นจะติดตามพัสดุไม่ได้)
+	if change.TrackingNumber != "" {
+		if change.ToStatus != StatusShipped {
+			return nil, custom_errors.ValidationError("ข้อมูลที่ส่งมาไม่ถูกต้อง", validator.ValidationErrorDetail{Field: "tracking_number", Message: "ระบุเลขพัสดุได้เฉพาะตอนเปลี่ยนเป็น shipped", Value: change.TrackingNumber})
+		}
+		order.TrackingNumber = change.TrackingNumber
+	}
+	if change.ToStatus == StatusShipped && order.TrackingNumber == "" {
+		return nil, custom_errors.ValidationError("ข้อมูลที่ส่งมาไม่ถูกต้อง", validator.ValidationErrorDetail{Field: "tracking_number", Message: "กรุณาระบุเลขพัสดุเมื่อเปลี่ยนเป็น shipped"})
+	}
+
+	// 4. เปลี่ยนสถานะ
+	actorID := claims.UserID
+	history := &StatusHistory{ToStatus: change.ToStatus, ActorID: &actorID, ActorRole: claims.Role, Reason: change.Reason}
+	if err := s.transition(ctx, order, history); err != nil {
+		return nil, err
+	}
+
+	s.log.WithContext(ctx).Info("Order status changed", "order_id", order.ID, "from", history.FromStatus, "to", history.ToStatus, "actor_id", actorID)
+	return order, nil
+}
+
+// GetStatusHistory ดูประวัติสถานะของ Order (ใช้สิทธิ์เดียวกับการดู Order)
+func (s *service) GetStatusHistory(ctx context.Context, id uint) ([]*StatusHistory, error) {
+	if _, err := s.GetOrderByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	histories, err := s.repo.ListStatusHistory(ctx, id)
+	if err != nil {
+		return nil, custom_errors.SystemErrorWithDetails("เกิดข้อผิดพลาดในการดึงประวัติสถานะ", err.Error())
+	}
+	return histories, nil
+}
+
+// GetTracking ถามสถานะพัสดุล่าสุดจากขนส่งของ Order (ใช้สิทธิ์เดียวกับการดู Order)
+func (s *service) GetTracking(ctx context.Context, id uint) (*Order, *shipping.TrackingInfo, error) {
+	order, err := s.GetOrderByID(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if order.TrackingNumber == "" {
+		return nil, nil, custom_errors.ConflictError("คำสั่งซื้อนี้ยังไม่ถูกจัดส่ง จึงยังไม่มีเลขพัสดุ", map[string]interface{}{"status": order.Status})
+	}
+	provider, err := s.providerFor(order)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	info, err := provider.Track(ctx, order.TrackingNumber)
+	if err != nil {
+		s.log.WithContext(ctx).Warn("Shipment tracking failed", "order_id", order.ID, "provider", provider.Name(), "tracking_number", order.TrackingNumber, "error", err)
+		return nil, nil, externalError("ไม่สามารถติดตามพัสดุได้ในขณะนี้", err)
+	}
+	return order, info, nil
+}
+
+// QuoteShipping ขอราคาค่าส่งของ Order จากขนส่งของ Order นั้น (ฝั่งหลังบ้าน)
+func (s *service) QuoteShipping(ctx context.Context, id uint, parcels []shipping.Parcel) ([]*shipping.RateQuote, error) {
+	if err := s.rbac.RequirePermission(ctx, PermissionOrdersManage); err != nil {
+		return nil, err
+	}
+
+	order, err := s.GetOrderByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if order.ShippingAddress == nil {
+		return nil, custom_errors.ConflictError("คำสั่งซื้อนี้ไม่มีที่อยู่จัดส่ง", nil)
+	}
+	provider, err := s.providerFor(order)
+	if err != nil {
+		return nil, err
+	}
+
+	quotes, err := provider.QuoteRates(ctx, &shipping.RateRequest{
+		Destination:   toShippingAddress(order.ShippingAddress),
+		Parcels:       parcels,
+		DeclaredValue: order.TotalAmount,
+	})
+	if err != nil {
+		return nil, externalError("ไม่สามารถขอราคาค่าส่งได้ในขณะนี้", err)
+	}
+	return quotes, nil
+}
+
+// CreateShipment จองการจัดส่งกับขนส่งของ Order แล้วเปลี่ยนสถานะเป็น shipped พร้อมเลขพัสดุที่ได้มา
+func (s *service) CreateShipment(ctx context.Context, id uint, req *ShipmentRequest) (*Order, *shipping.Label, error) {
+	claims, ok := auth.ClaimsFromContext(ctx)
+	if !ok {
+		return nil, nil, custom_errors.UnauthorizedError("กรุณาเข้าสู่ระบบก่อนใช้งาน")
+	}
+	if err := s.rbac.RequirePermission(ctx, PermissionOrdersManage); err != nil {
+		return nil, nil, err
+	}
+
+	// 1. ตรวจว่า Order พร้อมส่ง (ตรวจก่อนจองจริง จะได้ไม่ต้องยกเลิกทีหลัง)
+	order, err := s.GetOrderByID(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !CanTransition(order.Status, StatusShipped) {
+		return nil, nil, invalidTransitionError(order.Status, StatusShipped)
+	}
+	if order.ShippingAddress == nil {
+		return nil, nil, custom_errors.ConflictError("คำสั่งซื้อนี้ไม่มีที่อยู่จัดส่ง", nil)
+	}
+	provider, err := s.providerFor(order)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// 2. จองกับขนส่ง
+	label, err := provider.CreateLabel(ctx, &shipping.LabelRequest{
+		Reference:   order.OrderNumber,
+		ServiceCode: req.ServiceCode,
+		Recipient:   toShippingAddress(order.ShippingAddress),
+		Parcels:     req.Parcels,
+	})
+	if err != nil {
+		return nil, nil, externalError("ไม่สามารถจองการจัดส่งได้ในขณะนี้", err)
+	}
+
+	// 3. บันทึกเลขพัสดุ + เปลี่ยนสถานะ ถ้าไม่สำเร็จให้ยกเลิกการจองที่เพิ่งทำไป (ไม่ให้มีพัสดุลอยๆ ที่ไม่มี Order อ้างถึง)
+	order.TrackingNumber = label.TrackingNumber
+	order.ShippingProvider = provider.Name()
+	actorID := claims.UserID
+	history := &StatusHistory{ToStatus: StatusShipped, ActorID: &actorID, ActorRole: claims.Role, Reason: "shipment created with " + provider.Name()}
+	if err := s.transition(ctx, order, history); err != nil {
+		if cancelErr := provider.CancelShipment(ctx, label.TrackingNumber); cancelErr != nil {
+			s.log.WithContext(ctx).Error("Failed to cancel orphaned shipment", cancelErr, "order_id", order.ID, "provider", provider.Name(), "tracking_number", label.TrackingNumber)
+		}
+		return nil, nil, err
+	}
+
+	s.log.WithContext(ctx).Info("Shipment created", "order_id", order.ID, "provider", provider.Name(), "tracking_number", label.TrackingNumber)
+	return order, label, nil
+}
+
+// ApplyShipmentUpdate นำสถานะพัสดุจากขนส่งมาใช้กับ Order (ระบบเป็นคนเรียก ไม่ต้องมีผู้ใช้ใน ctx)
+// เมื่อพัสดุถึงปลายทาง Order ที่ยัง shipped อยู่จะถูกปิดเป็น completed อัตโนมัติ
+func (s *service) ApplyShipmentUpdate(ctx context.Context, update *ShipmentUpdate) error {
+	// 1. หา Order จากเลขพัสดุ
+	order, err := s.repo.GetByTrackingNumber(ctx, update.Provider, update.TrackingNumber, update.Provider == s.shipping.DefaultName())
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return custom_errors.NotFoundError(fmt.Sprintf("ไม่พบคำสั่งซื้อของพัสดุ %s (%s)", update.TrackingNumber, update.Provider))
+		}
+		return custom_errors.SystemErrorWithDetails("เกิดข้อผิดพลาดในการค้นหาคำสั่งซื้อ", err.Error())
+	}
+
+	// 2. บันทึกสถานะพัสดุ (เหตุการณ์ที่มาช้ากว่าเหตุการณ์ใหม่กว่าจะถูกข้ามไป)
+	applied, err := s.repo.UpdateShipmentStatus(ctx, order.ID, update.Status, update.OccurredAt)
+	if err != nil {
+		return custom_errors.SystemErrorWithDetails("ไม่สามารถบันทึกสถานะพัสดุได้", err.Error())
+	}
+	if !applied {
+		s.log.WithContext(ctx).Info("Stale shipment update skipped", "order_id", order.ID, "status", update.Status, "occurred_at", update.OccurredAt)
+		return nil
+	}
+
+	// 3. ส่งถึงแล้ว -> ปิด Order (ถ้ายังไม่ถูกปิด)
+	if update.Status == shipping.StatusDelivered && order.Status == StatusShipped {
+		history := &StatusHistory{ToStatus: StatusCompleted, ActorRole: ActorRoleSystem, Reason: "delivered: " + update.Reference}
+		if err := s.transition(ctx, order, history); err != nil {
+			return err
+		}
+		s.log.WithContext(ctx).Info("Order completed on delivery", "order_id", order.ID, "provider", update.Provider, "tracking_number", update.TrackingNumber)
+	}
+	return nil
+}
+
+// --- Private Helpers ---
+
+// transition คือ "ด่านตรวจ" ของ State Machine: ทุกการเปลี่ยนสถานะต้องผ่านที่นี่เท่านั้น
+// ถ้าสำเร็จ order.Status จะถูกอัปเดตให้ตรงกับ DB
+func (s *service) transition(ctx context.Context, order *Order, history *StatusHistory) error {
+	if !IsValidStatus(history.ToStatus) {
+		return custom_errors.ValidationError("สถานะไม่ถูกต้อง", validator.ValidationErrorDetail{Field: "status", Message: "ไม่รู้จักสถานะนี้", Value: history.ToStatus})
+	}
+	if !CanTransition(order.Status, history.ToStatus) {
+		return invalidTransitionError(order.Status, history.ToStatus)
+	}
+
+	if err := s.repo.UpdateStatus(ctx, order, order.Status, history); err != nil {
+		if errors.Is(err, ErrStatusChanged) {
+			return custom_errors.ConflictError("สถานะของคำสั่งซื้อถูกเปลี่ยนไปแล้ว กรุณาโหลดข้อมูลใหม่แล้วลองอีกครั้ง", nil)
+		}
+		return custom_errors.SystemErrorWithDetails("ไม่สามารถเปลี่ยนสถานะคำสั่งซื้อได้", err.Error())
+	}
+
+	order.Status = history.ToStatus
+	order.UpdatedAt = history.CreatedAt
+	return nil
+}
+
+// providerFor คืนขนส่งของ Order (Order เก่าที่ยังไม่มีข้อมูลขนส่ง จะใช้ขนส่งเริ่มต้น)
+func (s *service) providerFor(order *Order) (shipping.Provider, error) {
+	name := order.ShippingProvider
+	if name == "" {
+		name = s.shipping.DefaultName()
+	}
+	provider, err := s.shipping.Get(name)
+	if err != nil {
+		return nil, custom_errors.SystemErrorWithDetails("ขนส่งของคำสั่งซื้อนี้ไม่ได้เปิดใช้งานอยู่", map[string]interface{}{"provider": name})
+	}
+	return provider, nil
+}
+
+// externalError ส่ง AppError จาก Adapter ต่อไปตามเดิม (มีรายละเอียดจากปลายทางอยู่แล้ว) ที่เหลือห่อเป็น EXTERNAL_API_ERROR
+func externalError(message string, err error) *custom_errors.AppError {
+	if appErr, ok := err.(*custom_errors.AppError); ok {
+		return appErr
+	}
+	return custom_errors.ExternalAPIError(message, err.Error())
+}
+
+func toShippingAddress(a *Address) shipping.Address {
+	return shipping.Address{
+		Name:       a.RecipientName,
+		Phone:      a.Phone,
+		Line1:      a.Line1,
+		Line2:      a.Line2,
+		District:   a.District,
+		Province:   a.Province,
+		PostalCode: a.PostalCode,
+		Country:    a.Country,
+	}
+}
+
+func invalidTransitionError(from, to string) *custom_errors.AppError {
+	return custom_errors.InvalidStateTransitionError(
+		fmt.Sprintf("ไม่สามารถเปลี่ยนสถานะจาก %s เป็น %s ได้", from, to),
+		map[string]interface{}{"from": from, "to": to, "allowed": AllowedTransitions(from)},
+	)
+}
+
+// validateItems ตรวจกฎที่ validator tag ตรวจไม่ได้ (เช่น จำนวนตำแหน่งทศนิยมของราคา)
+func validateItems(items []*OrderItem) []validator.ValidationErrorDetail {
+	var details []validator.ValidationErrorDetail
+	for i, item := range items {
+		field := fmt.Sprintf("items[%d].unit_price", i)
+		switch {
+		case item.UnitPrice.IsZero():
+			details = append(details, validator.ValidationErrorDetail{Field: field, Message: "ราคาต้องมากกว่า 0", Value: item.UnitPrice.String()})
+		case item.UnitPrice.IsNegative():
+			details = append(details, validator.ValidationErrorDetail{Field: field, Message: "ราคาต้องไม่ติดลบ", Value: item.UnitPrice.String()})
+		case !item.UnitPrice.Equal(item.UnitPrice.Round(2)):
+			details = append(details, validator.ValidationErrorDetail{Field: field, Message: "ราคามีทศนิยมได้ไม่เกิน 2 ตำแหน่ง", Value: item.UnitPrice.String()})
+		case item.UnitPrice.GreaterThan(maxUnitPrice):
+			details = append(details, validator.ValidationErrorDetail{Field: field, Message: "ราคาเกินกว่าที่ระบบรองรับ", Value: item.UnitPrice.String()})
+		}
+	}
+	return details
+}
+
+// ====================================================================================
+// Order Number Generator
+// ====================================================================================
+
+// ตัวอักษรที่ใช้ในเลข Order (ตัดตัวที่สับสนง่ายอย่าง 0/O และ 1/I/L ออก)
+const orderNumberAlphabet = "23456789ABCDEFGHJKMNPQRSTUVWXYZ"
+
+// randomOrderNumberGenerator ออกเลขรูปแบบ ORD-YYYYMMDD-XXXXXXXX
+// (วันที่ตามเวลาไทย + ตัวสุ่ม 8 ตัว ≈ 8.5 แสนล้านแบบต่อวัน โอกาสชนต่ำมาก และถ้าชนจริง Service จะลองใหม่)
+type randomOrderNumberGenerator struct {
+	location *time.Location
+}
+
+// NewOrderNumberGenerator คือโรงงานสร้างเครื่องออกเลข Order
+func NewOrderNumberGenerator(location *time.Location) OrderNumberGenerator {
+	return &randomOrderNumberGenerator{location: location}
+}
+
+func (g *randomOrderNumberGenerator) Generate(now time.Time) (string, error) {
+	randomBytes := make([]byte, 8)
+	if _, err := rand.Read(randomBytes); err != nil {
+		return "", fmt.Errorf("failed to generate order number: %w", err)
+	}
+	suffix := make([]byte, len(randomBytes))
+	for i, b := range randomBytes {
+		suffix[i] = orderNumberAlphabet[int(b)%len(orderNumberAlphabet)]
+	}
+	return fmt.Sprintf("ORD-%s-%s", now.In(g.location).Format("20060102"), suffix), nil
+}
@@ -0,0 +1,36 @@
+package example_order
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestValidateItems(t *testing.T) {
+	tests := []struct {
+		name      string
+		unitPrice decimal.Decimal
+		wantMsg   string
+	}{
+		{name: "valid", unitPrice: decimal.RequireFromString("199.50")},
+		{name: "not sent", unitPrice: decimal.Decimal{}, wantMsg: "ราคาต้องมากกว่า 0"},
+		{name: "zero", unitPrice: decimal.RequireFromString("0.00"), wantMsg: "ราคาต้องมากกว่า 0"},
+		{name: "negative", unitPrice: decimal.RequireFromString("-1"), wantMsg: "ราคาต้องไม่ติดลบ"},
+		{name: "three decimals", unitPrice: decimal.RequireFromString("1.005"), wantMsg: "ราคามีทศนิยมได้ไม่เกิน 2 ตำแหน่ง"},
+		{name: "too large", unitPrice: decimal.RequireFromString("100000000"), wantMsg: "ราคาเกินกว่าที่ระบบรองรับ"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			details := validateItems([]*OrderItem{{ProductSKU: "SKU-1", Quantity: 1, UnitPrice: tt.unitPrice}})
+			if tt.wantMsg == "" {
+				if len(details) != 0 {
+					t.Fatalf("validateItems() = %+v, want no errors", details)
+				}
+				return
+			}
+			if len(details) != 1 || details[0].Message != tt.wantMsg || details[0].Field != "items[0].unit_price" {
+				t.Errorf("validateItems() = %+v, want one error %q on items[0].unit_price", details, tt.wantMsg)
+			}
+		})
+	}
+}
@@ -4,14 +4,59 @@ import "time"
 
 // Domain คือพิมพ์เขียวหลักของข้อมูล User ในระบบของเรา
 // จะต้องบริสุทธิ์ ไม่มี gorm tags หรือ json tags
+// (มีแค่ tag log:"redact" ที่บอก Logger ว่าห้ามพิมพ์ค่านี้ออกมา)
 type Domain struct {
 	ID           uint
 	Name         string
 	Email        string
-	PasswordHash string
+	PasswordHash string `log:"redact"`
 	Status       string
 	Role         string
 	LastLoginAt  *time.Time
 	CreatedAt    time.Time
 	UpdatedAt    time.Time
+	DeletedAt    *time.Time // nil = ยังใช้งานอยู่ (ไม่ได้ถูก soft delete)
 }
+
+// UserUpdate คือชุดข้อมูลที่ต้องการแก้ไข (field ที่เป็น nil = ไม่แก้)
+type UserUpdate struct {
+	Name   *string
+	Email  *string
+	Status *string
+	Role   *string
+}
+
+// UserFilter คือเงื่อนไขการกรองรายชื่อผู้ใช้ (ค่า zero value = ไม่กรอง field นั้น)
+type UserFilter struct {
+	Statuses    []string  // status IN (...)
+	Roles       []string  // role IN (...)
+	EmailPrefix string    // email ขึ้นต้นด้วย ... (ไม่สนตัวพิมพ์เล็ก/ใหญ่)
+	CreatedAt   TimeRange // ช่วงเวลาของ created_at
+	Query       string    // ค้นหาคำใน name หรือ email
+}
+
+// TimeRange คือช่วงเวลาแบบเปิด/ปิดได้ทั้งสองฝั่ง (nil = ไม่จำกัดฝั่งนั้น)
+type TimeRange struct {
+	GTE *time.Time
+	GT  *time.Time
+	LTE *time.Time
+	LT  *time.Time
+}
+
+// CursorPosition คือตำแหน่งที่ Keyset Pagination จะเริ่มอ่านต่อ
+// (แถวแรกที่ได้จะ "ถัดจาก" แถวที่มีค่า SortValue + ID นี้ ตามทิศทางที่อ่าน)
+type CursorPosition struct {
+	SortValue interface{} // ค่าของ field ที่ใช้เรียง (string หรือ time.Time) ถ้าเรียงด้วย id จะเป็น nil
+	ID        uint
+	Backward  bool // true = อ่านย้อนกลับ (ไปหน้าก่อนหน้า)
+}
+
+// Permissions ของโมดูลนี้ (ใช้คู่กับตารางสิทธิ์ auth.permissions ใน config)
+const (
+	PermissionUsersList    = "users:list"    // ดูรายชื่อผู้ใช้ทั้งหมด
+	PermissionUsersRead    = "users:read"    // ดูข้อมูลผู้ใช้คนอื่นที่ไม่ใช่ตัวเอง
+	PermissionUsersUpdate  = "users:update"  // แก้ไขข้อมูลผู้ใช้คนอื่น
+	PermissionUsersManage  = "users:manage"  // เปลี่ยน status/role ของผู้ใช้
+	PermissionUsersDelete  = "users:delete"  // ลบ (soft delete) ผู้ใช้คนอื่น
+	PermissionUsersRestore = "users:restore" // กู้คืนผู้ใช้ที่ถูกลบ
+)
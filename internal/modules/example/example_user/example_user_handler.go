@@ -1,10 +1,15 @@
 package example_user
 
 import (
+	"fmt"
+	"go-template/internal/adapters/primary/http/middleware"
+	"go-template/internal/modules/example/example_auth"
+	"go-template/pkg/auth"
 	"go-template/pkg/custom_errors"
 	"go-template/pkg/logger"
 	"go-template/pkg/response"
 	"go-template/pkg/validator"
+	"strings"
 	"time"
 
 	govalidator "github.com/go-playground/validator/v10" // ⭐️ 1. ตั้งชื่อเล่นให้ไลบรารีเป็น "govalidator"
@@ -21,16 +26,41 @@ type CreateRequest struct {
 	Password string `json:"password" validate:"required,min=8" vmsg:"required:กรุณาระบุรหัสผ่าน,min:รหัสผ่านต้องมีความยาวอย่างน้อย 8 ตัวอักษร"`
 }
 
+type LoginRequest struct {
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required" vmsg:"required:กรุณาระบุรหัสผ่าน"`
+}
+
+// UpdateUserRequest ใช้กับ PUT (แทนที่ข้อมูลหลักทั้งหมด)
+type UpdateUserRequest struct {
+	Name   string  `json:"name" validate:"required,min=2"`
+	Email  string  `json:"email" validate:"required,email"`
+	Status *string `json:"status" validate:"omitempty,oneof=active inactive banned"`
+	Role   *string `json:"role" validate:"omitempty,oneof=user admin"`
+}
+
+// PatchUserRequest ใช้กับ PATCH (ส่งมาเฉพาะ field ที่ต้องการแก้)
+type PatchUserRequest struct {
+	Name   *string `json:"name" validate:"omitempty,min=2"`
+	Email  *string `json:"email" validate:"omitempty,email"`
+	Status *string `json:"status" validate:"omitempty,oneof=active inactive banned"`
+	Role   *string `json:"role" validate:"omitempty,oneof=user admin"`
+}
+
 type GetUserByIDParams struct {
 	ID uint `uri:"id" validate:"required,gte=1"`
 }
 
 type ListUsersQuery struct {
-	Limit  *int    `query:"limit"`
+	Limit  *int    `query:"limit" validate:"omitempty,gte=1,lte=100"`
 	Page   *int    `query:"page"`
 	Offset *int    `query:"offset"`
 	Cursor *string `query:"cursor"`
 	Sort   *string `query:"sort" validate:"omitempty,sort_format"`
+	Q      *string `query:"q" validate:"omitempty,max=100"`
+	// ตัวกรองอื่นๆ ใช้รูปแบบ key[operator]=value และถูกอ่านแยกโดย parseUserFilter:
+	//   filter[status]=active,inactive   filter[role]=admin   filter[email]=john@
+	//   created_at[gte]=2025-01-01       created_at[lt]=2025-02-01T00:00:00+07:00
 }
 
 type Response struct {
@@ -43,6 +73,11 @@ type Response struct {
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+type LoginResponse struct {
+	*example_auth.TokenResponse
+	User *Response `json:"user"`
+}
+
 // ====================================================================================
 // Handler
 // ====================================================================================
@@ -84,7 +119,7 @@ func (h *handler) CreateUser(c fiber.Ctx) error {
 		Email: req.Email,
 	}
 
-	createdUserDomain, serviceErr := h.service.CreateUser(domainData, req.Password)
+	createdUserDomain, serviceErr := h.service.CreateUser(c, domainData, req.Password)
 	if serviceErr != nil {
 		return response.Error(c, serviceErr.(*custom_errors.AppError))
 	}
@@ -94,18 +129,12 @@ func (h *handler) CreateUser(c fiber.Ctx) error {
 }
 
 func (h *handler) GetUserByID(c fiber.Ctx) error {
-	params := new(GetUserByIDParams)
-	if err := c.Bind().URI(params); err != nil {
-		appErr := custom_errors.ValidationError("ID ที่ส่งมาไม่ถูกต้อง", fiber.Map{"id": "must be a positive integer"})
+	params, appErr := h.bindUserIDParams(c)
+	if appErr != nil {
 		return response.Error(c, appErr)
 	}
 
-	if validationResult := validator.Validate(h.validator, params); !validationResult.IsValid {
-		appErr := custom_errors.ValidationError("ID ที่ส่งมาไม่ถูกต้อง", validationResult.Errors)
-		return response.Error(c, appErr)
-	}
-
-	userDomain, serviceErr := h.service.GetUserByID(params.ID)
+	userDomain, serviceErr := h.service.GetUserByID(c, params.ID)
 	if serviceErr != nil {
 		return response.Error(c, serviceErr.(*custom_errors.AppError))
 	}
@@ -126,6 +155,15 @@ func (h *handler) ListUsers(c fiber.Ctx) error {
 		return response.Error(c, appErr)
 	}
 
+	filter, filterErrors := parseUserFilter(c.Queries(), h.bangkokLocation)
+	if len(filterErrors) > 0 {
+		appErr := custom_errors.ValidationError("Filter ไม่ถูกต้อง", filterErrors)
+		return response.Error(c, appErr)
+	}
+	if query.Q != nil {
+		filter.Query = strings.TrimSpace(*query.Q)
+	}
+
 	sort := "id:asc"
 	if query.Sort != nil {
 		sort = *query.Sort
@@ -137,13 +175,13 @@ func (h *handler) ListUsers(c fiber.Ctx) error {
 			limit = *query.Limit
 		}
 
-		userDomains, nextCursor, hasMore, serviceErr := h.service.ListUsersByCursor(*query.Cursor, limit, sort)
+		userDomains, page, serviceErr := h.service.ListUsersByCursor(c, filter, *query.Cursor, limit, sort)
 		if serviceErr != nil {
 			return response.Error(c, serviceErr.(*custom_errors.AppError))
 		}
 
 		responsePayloads := h.toResponseList(userDomains)
-		pagination := response.NewCursorPagination(nextCursor, hasMore)
+		pagination := response.NewCursorPagination(page.NextCursor, page.PrevCursor, page.HasMore, page.HasPrevious)
 		return response.Success(c, fiber.StatusOK, "Users retrieved successfully", responsePayloads, pagination)
 
 	} else {
@@ -158,7 +196,7 @@ func (h *handler) ListUsers(c fiber.Ctx) error {
 			offset = (*query.Page - 1) * limit
 		}
 
-		userDomains, totalCount, serviceErr := h.service.ListUsersByPage(limit, offset, sort)
+		userDomains, totalCount, serviceErr := h.service.ListUsersByPage(c, filter, limit, offset, sort)
 		if serviceErr != nil {
 			return response.Error(c, serviceErr.(*custom_errors.AppError))
 		}
@@ -169,15 +207,168 @@ func (h *handler) ListUsers(c fiber.Ctx) error {
 	}
 }
 
+func (h *handler) Login(c fiber.Ctx) error {
+	req := new(LoginRequest)
+	if err := c.Bind().Body(req); err != nil {
+		appErr := custom_errors.InvalidFormatError("Request body is not valid JSON", err.Error())
+		return response.Error(c, appErr)
+	}
+
+	if validationResult := validator.Validate(h.validator, req); !validationResult.IsValid {
+		appErr := custom_errors.ValidationError("ข้อมูลที่ส่งมาไม่ถูกต้อง", validationResult.Errors)
+		return response.Error(c, appErr)
+	}
+
+	userDomain, tokenPair, serviceErr := h.service.Login(c, req.Email, req.Password)
+	if serviceErr != nil {
+		return response.Error(c, serviceErr.(*custom_errors.AppError))
+	}
+
+	responsePayload := &LoginResponse{
+		TokenResponse: example_auth.NewTokenResponse(tokenPair, h.bangkokLocation),
+		User:          h.toResponse(userDomain),
+	}
+	return response.Success(c, fiber.StatusOK, "Login successful", responsePayload, nil)
+}
+
+func (h *handler) UpdateUser(c fiber.Ctx) error {
+	params, appErr := h.bindUserIDParams(c)
+	if appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	req := new(UpdateUserRequest)
+	if err := c.Bind().Body(req); err != nil {
+		appErr := custom_errors.InvalidFormatError("Request body is not valid JSON", err.Error())
+		return response.Error(c, appErr)
+	}
+
+	if validationResult := validator.Validate(h.validator, req); !validationResult.IsValid {
+		appErr := custom_errors.ValidationError("ข้อมูลที่ส่งมาไม่ถูกต้อง", validationResult.Errors)
+		return response.Error(c, appErr)
+	}
+
+	changes := &UserUpdate{
+		Name:   &req.Name,
+		Email:  &req.Email,
+		Status: req.Status,
+		Role:   req.Role,
+	}
+
+	userDomain, serviceErr := h.service.UpdateUser(c, params.ID, changes)
+	if serviceErr != nil {
+		return response.Error(c, serviceErr.(*custom_errors.AppError))
+	}
+
+	return response.Success(c, fiber.StatusOK, "User updated successfully", h.toResponse(userDomain), nil)
+}
+
+func (h *handler) PatchUser(c fiber.Ctx) error {
+	params, appErr := h.bindUserIDParams(c)
+	if appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	req := new(PatchUserRequest)
+	if err := c.Bind().Body(req); err != nil {
+		appErr := custom_errors.InvalidFormatError("Request body is not valid JSON", err.Error())
+		return response.Error(c, appErr)
+	}
+
+	if validationResult := validator.Validate(h.validator, req); !validationResult.IsValid {
+		appErr := custom_errors.ValidationError("ข้อมูลที่ส่งมาไม่ถูกต้อง", validationResult.Errors)
+		return response.Error(c, appErr)
+	}
+
+	changes := &UserUpdate{
+		Name:   req.Name,
+		Email:  req.Email,
+		Status: req.Status,
+		Role:   req.Role,
+	}
+
+	userDomain, serviceErr := h.service.UpdateUser(c, params.ID, changes)
+	if serviceErr != nil {
+		return response.Error(c, serviceErr.(*custom_errors.AppError))
+	}
+
+	return response.Success(c, fiber.StatusOK, "User updated successfully", h.toResponse(userDomain), nil)
+}
+
+func (h *handler) DeleteUser(c fiber.Ctx) error {
+	params, appErr := h.bindUserIDParams(c)
+	if appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	if serviceErr := h.service.DeleteUser(c, params.ID); serviceErr != nil {
+		return response.Error(c, serviceErr.(*custom_errors.AppError))
+	}
+
+	return response.Message(c, fiber.StatusOK, "User deleted successfully")
+}
+
+func (h *handler) RestoreUser(c fiber.Ctx) error {
+	params, appErr := h.bindUserIDParams(c)
+	if appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	userDomain, serviceErr := h.service.RestoreUser(c, params.ID)
+	if serviceErr != nil {
+		return response.Error(c, serviceErr.(*custom_errors.AppError))
+	}
+
+	return response.Success(c, fiber.StatusOK, "User restored successfully", h.toResponse(userDomain), nil)
+}
+
+func (h *handler) HardDeleteUser(c fiber.Ctx) error {
+	params, appErr := h.bindUserIDParams(c)
+	if appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	if serviceErr := h.service.HardDeleteUser(c, params.ID); serviceErr != nil {
+		return response.Error(c, serviceErr.(*custom_errors.AppError))
+	}
+
+	return response.Message(c, fiber.StatusOK, "User permanently deleted")
+}
+
 // RegisterRoutes ลงทะเบียน routes ทั้งหมดของโมดูลนี้
-func (h *handler) RegisterRoutes(router fiber.Router) {
+// authMiddleware คือ Middleware ตรวจ JWT ที่จะถูกใส่ให้กับ routes ที่ต้องเข้าสู่ระบบก่อน
+// rbac คือตารางสิทธิ์ที่ใช้ตรวจ permission ราย route
+func (h *handler) RegisterRoutes(router fiber.Router, authMiddleware fiber.Handler, rbac *auth.RBAC) {
 	userRouter := router.Group("/users")
+
+	// --- Public routes ---
 	userRouter.Post("", h.CreateUser)
-	userRouter.Get("", h.ListUsers)
-	userRouter.Get("/:id", h.GetUserByID)
+	userRouter.Post("/login", h.Login)
+
+	// --- Protected routes (ต้องมี Bearer Token) ---
+	userRouter.Get("", authMiddleware, middleware.RequirePermission(rbac, PermissionUsersList), h.ListUsers)
+	userRouter.Get("/:id", authMiddleware, h.GetUserByID)
+	userRouter.Put("/:id", authMiddleware, h.UpdateUser)
+	userRouter.Patch("/:id", authMiddleware, h.PatchUser)
+	userRouter.Delete("/:id", authMiddleware, h.DeleteUser)
+	userRouter.Post("/:id/restore", authMiddleware, middleware.RequirePermission(rbac, PermissionUsersRestore), h.RestoreUser)
+	userRouter.Delete("/:id/permanent", authMiddleware, middleware.RequireRole(auth.RoleAdmin), h.HardDeleteUser)
 }
 
 // --- Private Helpers ---
+
+// bindUserIDParams อ่านและตรวจสอบ :id จาก URL
+func (h *handler) bindUserIDParams(c fiber.Ctx) (*GetUserByIDParams, *custom_errors.AppError) {
+	params := new(GetUserByIDParams)
+	if err := c.Bind().URI(params); err != nil {
+		return nil, custom_errors.ValidationError("ID ที่ส่งมาไม่ถูกต้อง", fiber.Map{"id": "must be a positive integer"})
+	}
+
+	if validationResult := validator.Validate(h.validator, params); !validationResult.IsValid {
+		return nil, custom_errors.ValidationError("ID ที่ส่งมาไม่ถูกต้อง", validationResult.Errors)
+	}
+	return params, nil
+}
 func (h *handler) toResponse(d *Domain) *Response {
 	return &Response{
 		ID:        d.ID,
@@ -197,3 +388,111 @@ func (h *handler) toResponseList(domains []*Domain) []*Response {
 	}
 	return responses
 }
+
+// ====================================================================================
+// Filter Grammar
+// ====================================================================================
+
+// ⭐️ "แผนที่" ของตัวกรองที่อนุญาต: key ไหนใช้ได้ และค่าไหนถูกต้อง
+// (คล้ายกับ whitelist ของ parseSortString) key ที่ไม่อยู่ในนี้จะถูกปฏิเสธทั้งหมด
+var (
+	allowedStatusFilters = map[string]bool{"active": true, "inactive": true, "banned": true}
+	allowedRoleFilters   = map[string]bool{"user": true, "admin": true}
+	allowedTimeOperators = map[string]bool{"gte": true, "gt": true, "lte": true, "lt": true}
+)
+
+// parseUserFilter อ่าน query string รูปแบบ key[operator]=value ให้กลายเป็น UserFilter
+// loc คือเขตเวลาที่ใช้ตีความวันที่ที่ไม่ระบุเวลา (ปกติคือกรุงเทพฯ)
+func parseUserFilter(queries map[string]string, loc *time.Location) (*UserFilter, []validator.ValidationErrorDetail) {
+	filter := &UserFilter{}
+	var errs []validator.ValidationErrorDetail
+
+	for key, value := range queries {
+		name, operator, isBracketed := parseBracketKey(key)
+		if !isBracketed {
+			continue // เป็น query ปกติ (limit, sort, q, ...) ที่ Bind จัดการไปแล้ว
+		}
+
+		switch {
+		case name == "filter" && operator == "status":
+			values, invalid := splitAllowed(value, allowedStatusFilters)
+			if invalid != "" {
+				errs = append(errs, filterError(key, value, "status ต้องเป็น active, inactive หรือ banned: "+invalid))
+				continue
+			}
+			filter.Statuses = values
+
+		case name == "filter" && operator == "role":
+			values, invalid := splitAllowed(value, allowedRoleFilters)
+			if invalid != "" {
+				errs = append(errs, filterError(key, value, "role ต้องเป็น user หรือ admin: "+invalid))
+				continue
+			}
+			filter.Roles = values
+
+		case name == "filter" && operator == "email":
+			prefix := strings.TrimSpace(value)
+			if prefix == "" || len(prefix) > 255 {
+				errs = append(errs, filterError(key, value, "email ต้องมีความยาว 1-255 ตัวอักษร"))
+				continue
+			}
+			filter.EmailPrefix = prefix
+
+		case name == "created_at" && allowedTimeOperators[operator]:
+			t, err := parseFilterTime(value, loc)
+			if err != nil {
+				errs = append(errs, filterError(key, value, "รูปแบบเวลาต้องเป็น RFC3339 หรือ YYYY-MM-DD"))
+				continue
+			}
+			switch operator {
+			case "gte":
+				filter.CreatedAt.GTE = &t
+			case "gt":
+				filter.CreatedAt.GT = &t
+			case "lte":
+				filter.CreatedAt.LTE = &t
+			case "lt":
+				filter.CreatedAt.LT = &t
+			}
+
+		default:
+			errs = append(errs, filterError(key, value, "ไม่รองรับตัวกรองนี้"))
+		}
+	}
+
+	return filter, errs
+}
+
+// parseBracketKey แยก "filter[status]" ออกเป็น ("filter", "status", true)
+func parseBracketKey(key string) (name, operator string, ok bool) {
+	open := strings.IndexByte(key, '[')
+	if open <= 0 || !strings.HasSuffix(key, "]") {
+		return "", "", false
+	}
+	return key[:open], key[open+1 : len(key)-1], true
+}
+
+// splitAllowed แยกค่าที่คั่นด้วย comma และคืนค่าแรกที่ไม่อยู่ใน whitelist (ถ้ามี)
+func splitAllowed(value string, allowed map[string]bool) ([]string, string) {
+	var values []string
+	for _, v := range strings.Split(value, ",") {
+		v = strings.ToLower(strings.TrimSpace(v))
+		if !allowed[v] {
+			return nil, fmt.Sprintf("%q", v)
+		}
+		values = append(values, v)
+	}
+	return values, ""
+}
+
+// parseFilterTime รับได้ทั้ง RFC3339 เต็มๆ และแค่วันที่ (ตีความเป็นเวลาเที่ยงคืนของ loc)
+func parseFilterTime(value string, loc *time.Location) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.ParseInLocation("2006-01-02", value, loc)
+}
+
+func filterError(key, value, message string) validator.ValidationErrorDetail {
+	return validator.ValidationErrorDetail{Field: key, Message: message, Value: value}
+}
@@ -0,0 +1,108 @@
+package example_user
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseBracketKey(t *testing.T) {
+	tests := []struct {
+		key          string
+		wantName     string
+		wantOperator string
+		wantOK       bool
+	}{
+		{key: "filter[status]", wantName: "filter", wantOperator: "status", wantOK: true},
+		{key: "created_at[gte]", wantName: "created_at", wantOperator: "gte", wantOK: true},
+		{key: "filter[]", wantName: "filter", wantOperator: "", wantOK: true},
+		{key: "limit", wantOK: false},
+		{key: "[status]", wantOK: false},
+		{key: "filter[status", wantOK: false},
+		{key: "filterstatus]", wantOK: false},
+	}
+	for _, tt := range tests {
+		name, operator, ok := parseBracketKey(tt.key)
+		if name != tt.wantName || operator != tt.wantOperator || ok != tt.wantOK {
+			t.Errorf("parseBracketKey(%q) = (%q, %q, %v), want (%q, %q, %v)", tt.key, name, operator, ok, tt.wantName, tt.wantOperator, tt.wantOK)
+		}
+	}
+}
+
+func TestParseUserFilter(t *testing.T) {
+	bangkok, err := time.LoadLocation("Asia/Bangkok")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("valid filters", func(t *testing.T) {
+		filter, errs := parseUserFilter(map[string]string{
+			"filter[status]":  "Active, banned",
+			"filter[role]":    "admin",
+			"filter[email]":   "  john@ ",
+			"created_at[gte]": "2025-01-01",
+			"created_at[gt]":  "2025-01-01T00:00:00Z",
+			"created_at[lte]": "2025-02-01T00:00:00+07:00",
+			"created_at[lt]":  "2025-03-01",
+			"limit":           "10", // query ปกติถูกข้าม
+			"q":               "john",
+		}, bangkok)
+		if len(errs) != 0 {
+			t.Fatalf("unexpected errors: %+v", errs)
+		}
+		if got := filter.Statuses; len(got) != 2 || got[0] != "active" || got[1] != "banned" {
+			t.Errorf("Statuses = %v, want [active banned]", got)
+		}
+		if got := filter.Roles; len(got) != 1 || got[0] != "admin" {
+			t.Errorf("Roles = %v, want [admin]", got)
+		}
+		if filter.EmailPrefix != "john@" {
+			t.Errorf("EmailPrefix = %q, want %q", filter.EmailPrefix, "john@")
+		}
+		wantTimes := map[string]struct {
+			got  *time.Time
+			want time.Time
+		}{
+			"gte": {filter.CreatedAt.GTE, time.Date(2025, 1, 1, 0, 0, 0, 0, bangkok)},
+			"gt":  {filter.CreatedAt.GT, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+			"lte": {filter.CreatedAt.LTE, time.Date(2025, 1, 31, 17, 0, 0, 0, time.UTC)},
+			"lt":  {filter.CreatedAt.LT, time.Date(2025, 3, 1, 0, 0, 0, 0, bangkok)},
+		}
+		for op, tt := range wantTimes {
+			if tt.got == nil || !tt.got.Equal(tt.want) {
+				t.Errorf("CreatedAt.%s = %v, want %v", op, tt.got, tt.want)
+			}
+		}
+		if filter.Query != "" {
+			t.Errorf("Query = %q, want empty (q is bound by the handler, not the filter grammar)", filter.Query)
+		}
+	})
+
+	rejected := []struct {
+		name  string
+		key   string
+		value string
+	}{
+		{name: "unknown status", key: "filter[status]", value: "active,deleted"},
+		{name: "empty status", key: "filter[status]", value: ""},
+		{name: "unknown role", key: "filter[role]", value: "superuser"},
+		{name: "blank email", key: "filter[email]", value: "   "},
+		{name: "email too long", key: "filter[email]", value: string(make([]byte, 256))},
+		{name: "unknown time operator", key: "created_at[eq]", value: "2025-01-01"},
+		{name: "unknown time operator ne", key: "created_at[ne]", value: "2025-01-01"},
+		{name: "bad time format", key: "created_at[gte]", value: "01/02/2025"},
+		{name: "unknown filter field", key: "filter[password_hash]", value: "x"},
+		{name: "operator on a field without operators", key: "updated_at[gte]", value: "2025-01-01"},
+		{name: "filter with empty operator", key: "filter[]", value: "x"},
+	}
+	for _, tt := range rejected {
+		t.Run(tt.name, func(t *testing.T) {
+			_, errs := parseUserFilter(map[string]string{tt.key: tt.value}, bangkok)
+			if len(errs) != 1 {
+				t.Fatalf("errors = %+v, want exactly 1", errs)
+			}
+			if errs[0].Field != tt.key {
+				t.Errorf("error field = %q, want %q", errs[0].Field, tt.key)
+			}
+		})
+	}
+}
@@ -1,8 +1,11 @@
 package example_user
 
 import (
+	"context"
 	"fmt"
 	"go-template/pkg/logger"
+	"go-template/pkg/platform/postgres"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
@@ -10,11 +13,17 @@ import (
 
 // Repository คือ "สัญญา" ที่ Service จะเรียกใช้
 type Repository interface {
-	Create(d *Domain) error
-	GetByEmail(email string) (*Domain, error)
-	GetByID(id uint) (*Domain, error)
-	ListByPage(limit, offset int, sortField, sortDirection string) ([]*Domain, int, error)
-	ListByCursor(lastID uint, limit int, sortField, sortDirection string) ([]*Domain, error)
+	Create(ctx context.Context, d *Domain) error
+	GetByEmail(ctx context.Context, email string) (*Domain, error)
+	GetByID(ctx context.Context, id uint) (*Domain, error)
+	GetByIDUnscoped(ctx context.Context, id uint) (*Domain, error)
+	ListByPage(ctx context.Context, filter *UserFilter, limit, offset int, sortField, sortDirection string) ([]*Domain, int, error)
+	ListByCursor(ctx context.Context, filter *UserFilter, position *CursorPosition, limit int, sortField, sortDirection string) ([]*Domain, error)
+	UpdateLastLoginAt(ctx context.Context, id uint, loginAt time.Time) error
+	Update(ctx context.Context, d *Domain) error
+	Delete(ctx context.Context, id uint) error
+	Restore(ctx context.Context, id uint) error
+	HardDelete(ctx context.Context, id uint) error
 }
 
 // Model คือ "ชุดเกราะ" สำหรับ GORM
@@ -22,7 +31,7 @@ type Model struct {
 	gorm.Model
 	Name        string `gorm:"not null"`
 	Email       string `gorm:"uniqueIndex:idx_unique_active_email;not null"`
-	Password    string `gorm:"not null"`
+	Password    string `gorm:"not null" log:"redact"`
 	Status      string `gorm:"not null;default:active"`
 	Role        string `gorm:"not null;default:user"`
 	LastLoginAt *time.Time
@@ -45,40 +54,120 @@ func NewExampleRepository(db *gorm.DB, log logger.Logger) Repository {
 
 // --- Implementation ---
 
-func (r *repository) Create(d *Domain) error {
+func (r *repository) Create(ctx context.Context, d *Domain) error {
 	gormModel := toGORM(d)
-	result := r.db.Create(gormModel)
+	result := postgres.DB(ctx, r.db).Create(gormModel)
 	if result.Error != nil {
-		fmt.Print("Failed to create user in database")
+		r.log.WithContext(ctx).Error("Failed to create user in database", result.Error)
 		return result.Error
 	}
 	*d = *gormModel.toDomain() // อัปเดตค่าที่ DB สร้างให้กลับไปที่ Domain object
 	return nil
 }
 
-func (r *repository) GetByEmail(email string) (*Domain, error) {
+func (r *repository) GetByEmail(ctx context.Context, email string) (*Domain, error) {
 	var gormModel Model
-	result := r.db.Where("email = ?", email).First(&gormModel)
+	result := postgres.DB(ctx, r.db).Where("email = ?", email).First(&gormModel)
 	if result.Error != nil {
 		return nil, result.Error
 	}
 	return gormModel.toDomain(), nil
 }
 
-func (r *repository) GetByID(id uint) (*Domain, error) {
+func (r *repository) GetByID(ctx context.Context, id uint) (*Domain, error) {
 	var gormModel Model
-	result := r.db.First(&gormModel, id)
+	result := postgres.DB(ctx, r.db).First(&gormModel, id)
 	if result.Error != nil {
 		return nil, result.Error
 	}
-	r.log.Dumpf(logger.LevelDebug, "result", gormModel)
-	loc, _ := time.LoadLocation("Asia/Bangkok")
-	fmt.Println("DB time (raw):", gormModel.CreatedAt)
-	fmt.Println("DB time (Bangkok):", gormModel.CreatedAt.In(loc))
-	//fmt.Println("------ggggg------", id, gormModel)
 	return gormModel.toDomain(), nil
 }
 
+// GetByIDUnscoped ค้นหาผู้ใช้จาก ID รวมถึงผู้ใช้ที่ถูก soft delete ไปแล้ว
+func (r *repository) GetByIDUnscoped(ctx context.Context, id uint) (*Domain, error) {
+	var gormModel Model
+	result := postgres.DB(ctx, r.db).Unscoped().First(&gormModel, id)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return gormModel.toDomain(), nil
+}
+
+// Update บันทึกข้อมูลที่แก้ไขได้ (name, email, status, role) ของผู้ใช้
+func (r *repository) Update(ctx context.Context, d *Domain) error {
+	gormModel := toGORM(d)
+	result := postgres.DB(ctx, r.db).Model(&Model{}).
+		Where("id = ?", d.ID).
+		Select("name", "email", "status", "role").
+		Updates(gormModel)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	// อ่านค่าล่าสุดกลับมา (เช่น updated_at ที่ DB เพิ่งเปลี่ยน)
+	var updated Model
+	if err := postgres.DB(ctx, r.db).First(&updated, d.ID).Error; err != nil {
+		return err
+	}
+	*d = *updated.toDomain()
+	return nil
+}
+
+// Delete ทำ soft delete (ตั้งค่า deleted_at)
+func (r *repository) Delete(ctx context.Context, id uint) error {
+	result := postgres.DB(ctx, r.db).Delete(&Model{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// Restore กู้คืนผู้ใช้ที่ถูก soft delete
+// ⭐️ ถ้ามีผู้ใช้ active คนอื่นใช้อีเมลเดียวกันอยู่ DB จะปฏิเสธด้วย index "unique_active_email"
+// และ GORM จะคืน gorm.ErrDuplicatedKey มาให้ Service ตีความต่อ
+func (r *repository) Restore(ctx context.Context, id uint) error {
+	result := postgres.DB(ctx, r.db).Unscoped().Model(&Model{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Update("deleted_at", nil)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// HardDelete ลบผู้ใช้ออกจากตารางจริงๆ (กู้คืนไม่ได้)
+func (r *repository) HardDelete(ctx context.Context, id uint) error {
+	result := postgres.DB(ctx, r.db).Unscoped().Delete(&Model{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// UpdateLastLoginAt อัปเดตเวลาเข้าสู่ระบบล่าสุดของผู้ใช้
+func (r *repository) UpdateLastLoginAt(ctx context.Context, id uint, loginAt time.Time) error {
+	result := postgres.DB(ctx, r.db).Model(&Model{}).Where("id = ?", id).Update("last_login_at", loginAt)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
 // --- Translators ---
 
 func toGORM(d *Domain) *Model {
@@ -92,6 +181,10 @@ func toGORM(d *Domain) *Model {
 }
 
 func (m *Model) toDomain() *Domain {
+	var deletedAt *time.Time
+	if m.DeletedAt.Valid {
+		deletedAt = &m.DeletedAt.Time
+	}
 	return &Domain{
 		ID:           m.ID,
 		Name:         m.Name,
@@ -102,16 +195,17 @@ func (m *Model) toDomain() *Domain {
 		LastLoginAt:  m.LastLoginAt,
 		CreatedAt:    m.CreatedAt,
 		UpdatedAt:    m.UpdatedAt,
+		DeletedAt:    deletedAt,
 	}
 }
 
 // ListByPage handles page-based pagination
-func (r *repository) ListByPage(limit, offset int, sortField, sortDirection string) ([]*Domain, int, error) {
+func (r *repository) ListByPage(ctx context.Context, filter *UserFilter, limit, offset int, sortField, sortDirection string) ([]*Domain, int, error) {
 	var gormModels []Model
 	var totalCount int64
 
-	// 1. นับจำนวนทั้งหมดก่อน (สำหรับ Pagination)
-	if err := r.db.Model(&Model{}).Count(&totalCount).Error; err != nil {
+	// 1. นับจำนวนทั้งหมดก่อน (สำหรับ Pagination) โดยใช้เงื่อนไขกรองเดียวกัน
+	if err := applyUserFilter(postgres.DB(ctx, r.db).Model(&Model{}), filter).Count(&totalCount).Error; err != nil {
 		return nil, 0, err
 	}
 
@@ -119,7 +213,7 @@ func (r *repository) ListByPage(limit, offset int, sortField, sortDirection stri
 	orderClause := fmt.Sprintf("%s %s", sortField, sortDirection)
 
 	// 3. ดึงข้อมูลตามหน้า
-	result := r.db.Order(orderClause).Limit(limit).Offset(offset).Find(&gormModels)
+	result := applyUserFilter(postgres.DB(ctx, r.db).Model(&Model{}), filter).Order(orderClause).Limit(limit).Offset(offset).Find(&gormModels)
 	if result.Error != nil {
 		return nil, 0, result.Error
 	}
@@ -133,26 +227,42 @@ func (r *repository) ListByPage(limit, offset int, sortField, sortDirection stri
 	return domains, int(totalCount), nil
 }
 
-// ListByCursor handles cursor-based pagination
-func (r *repository) ListByCursor(lastID uint, limit int, sortField, sortDirection string) ([]*Domain, error) {
+// ListByCursor handles cursor-based (keyset) pagination
+// position = nil คือหน้าแรก ผลลัพธ์จะเรียงตาม "ทิศที่อ่าน" เสมอ
+// (ถ้าอ่านย้อนกลับ Service จะเป็นคนกลับลำดับให้เอง)
+func (r *repository) ListByCursor(ctx context.Context, filter *UserFilter, position *CursorPosition, limit int, sortField, sortDirection string) ([]*Domain, error) {
 	var gormModels []Model
 
-	// สร้าง query เริ่มต้น
-	query := r.db.Model(&Model{})
+	// ⭐️ ถ้าอ่านย้อนกลับ ให้กลับทิศการเรียงชั่วคราว แล้วค่อยกลับคืนทีหลัง
+	direction := sortDirection
+	if position != nil && position.Backward {
+		direction = oppositeDirection(sortDirection)
+	}
+	comparator := ">"
+	if direction == "desc" {
+		comparator = "<"
+	}
+
+	query := applyUserFilter(postgres.DB(ctx, r.db).Model(&Model{}), filter)
 
-	// สร้างคำสั่ง Order By
-	orderClause := fmt.Sprintf("%s %s", sortField, sortDirection)
-	query = query.Order(orderClause)
-
-	// ⭐️ Logic ของ Cursor: ดึงข้อมูลที่ "ถัดจาก" รายการสุดท้ายที่เห็น ⭐️
-	if lastID > 0 {
-		// ตัวอย่างง่ายๆ คือดึง ID ที่มากกว่า ID สุดท้าย (ถ้าเรียงแบบ asc)
-		// ในชีวิตจริง Logic ตรงนี้จะซับซ้อนกว่านี้มาก
-		if sortField == "id" && sortDirection == "asc" {
-			query = query.Where("id > ?", lastID)
+	// ⭐️ Logic ของ Cursor: ดึงข้อมูลที่ "ถัดจาก" ตำแหน่งที่ cursor ชี้ไว้
+	// ใช้ Row Value Comparison ของ Postgres: (field, id) > (?, ?)
+	// ทำให้แถวที่มีค่า field ซ้ำกันไม่หาย/ไม่ซ้ำ เพราะมี id เป็นตัวตัดสิน
+	// (sortField มาจาก whitelist ใน parseSortString เท่านั้น จึงใส่ลง SQL ได้อย่างปลอดภัย)
+	if position != nil {
+		if sortField == "id" {
+			query = query.Where(fmt.Sprintf("id %s ?", comparator), position.ID)
+		} else {
+			query = query.Where(fmt.Sprintf("(%s, id) %s (?, ?)", sortField, comparator), position.SortValue, position.ID)
 		}
 	}
 
+	// เรียงด้วย field หลัก แล้วใช้ id เป็นตัวตัดสินเสมอ
+	query = query.Order(fmt.Sprintf("%s %s", sortField, direction))
+	if sortField != "id" {
+		query = query.Order(fmt.Sprintf("id %s", direction))
+	}
+
 	// ดึงข้อมูล
 	result := query.Limit(limit).Find(&gormModels)
 	if result.Error != nil {
@@ -167,3 +277,50 @@ func (r *repository) ListByCursor(lastID uint, limit int, sortField, sortDirecti
 
 	return domains, nil
 }
+
+// applyUserFilter แปลง UserFilter เป็น WHERE clause แบบ parameterized (ไม่ต่อ string จากค่าของ Client)
+func applyUserFilter(query *gorm.DB, filter *UserFilter) *gorm.DB {
+	if filter == nil {
+		return query
+	}
+	if len(filter.Statuses) > 0 {
+		query = query.Where("status IN ?", filter.Statuses)
+	}
+	if len(filter.Roles) > 0 {
+		query = query.Where("role IN ?", filter.Roles)
+	}
+	if filter.EmailPrefix != "" {
+		query = query.Where("email ILIKE ?", escapeLike(filter.EmailPrefix)+"%")
+	}
+	if filter.CreatedAt.GTE != nil {
+		query = query.Where("created_at >= ?", *filter.CreatedAt.GTE)
+	}
+	if filter.CreatedAt.GT != nil {
+		query = query.Where("created_at > ?", *filter.CreatedAt.GT)
+	}
+	if filter.CreatedAt.LTE != nil {
+		query = query.Where("created_at <= ?", *filter.CreatedAt.LTE)
+	}
+	if filter.CreatedAt.LT != nil {
+		query = query.Where("created_at < ?", *filter.CreatedAt.LT)
+	}
+	if filter.Query != "" {
+		pattern := "%" + escapeLike(filter.Query) + "%"
+		query = query.Where("(name ILIKE ? OR email ILIKE ?)", pattern, pattern)
+	}
+	return query
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// escapeLike กันไม่ให้ตัวอักษรพิเศษของ LIKE (% และ _) ที่ Client ส่งมากลายเป็น wildcard
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+func oppositeDirection(direction string) string {
+	if direction == "desc" {
+		return "asc"
+	}
+	return "desc"
+}
@@ -0,0 +1,209 @@
+package example_user
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	gormpostgres "gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+
+	"go-template/pkg/logger"
+)
+
+// capturedQuery คือ SQL และค่าที่ GORM สร้างขึ้น (จับไว้แทนการยิงไปที่ DB จริง)
+type capturedQuery struct {
+	sql  string
+	vars []interface{}
+}
+
+// newDryRunRepository สร้าง Repository ที่ไม่ต่อ DB จริง แต่จำ SQL ของทุก query ไว้ให้ตรวจ
+func newDryRunRepository(t *testing.T) (*repository, *[]capturedQuery) {
+	t.Helper()
+	db, err := gorm.Open(gormpostgres.New(gormpostgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+		Logger:               gormlogger.Discard,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var queries []capturedQuery
+	err = db.Callback().Query().After("gorm:query").Register("test:capture", func(tx *gorm.DB) {
+		queries = append(queries, capturedQuery{sql: tx.Statement.SQL.String(), vars: tx.Statement.Vars})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &repository{db: db, log: logger.NewSlogLogger()}, &queries
+}
+
+func TestEscapeLike(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "john", want: "john"},
+		{in: "100%", want: `100\%`},
+		{in: "a_b", want: `a\_b`},
+		{in: `back\slash`, want: `back\\slash`},
+		{in: `%_\`, want: `\%\_\\`},
+		{in: `\%`, want: `\\\%`}, // backslash ที่ Client ส่งมาต้องไม่ไป "ปลด" การ escape ของเรา
+		{in: "สมชาย", want: "สมชาย"},
+		{in: "", want: ""},
+	}
+	for _, tt := range tests {
+		if got := escapeLike(tt.in); got != tt.want {
+			t.Errorf("escapeLike(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestListByCursorKeysetCondition(t *testing.T) {
+	createdAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		position      *CursorPosition
+		sortField     string
+		sortDirection string
+		wantSQL       string
+		wantVars      []interface{}
+	}{
+		{
+			name:          "first page has no keyset condition",
+			sortField:     "name",
+			sortDirection: "asc",
+			wantSQL:       `SELECT * FROM "example_users" WHERE "example_users"."deleted_at" IS NULL ORDER BY name asc,id asc LIMIT $1`,
+			wantVars:      []interface{}{11},
+		},
+		{
+			name:          "forward ascending",
+			position:      &CursorPosition{SortValue: "john", ID: 5},
+			sortField:     "name",
+			sortDirection: "asc",
+			wantSQL:       `SELECT * FROM "example_users" WHERE (name, id) > ($1, $2) AND "example_users"."deleted_at" IS NULL ORDER BY name asc,id asc LIMIT $3`,
+			wantVars:      []interface{}{"john", uint(5), 11},
+		},
+		{
+			name:          "forward descending",
+			position:      &CursorPosition{SortValue: createdAt, ID: 5},
+			sortField:     "created_at",
+			sortDirection: "desc",
+			wantSQL:       `SELECT * FROM "example_users" WHERE (created_at, id) < ($1, $2) AND "example_users"."deleted_at" IS NULL ORDER BY created_at desc,id desc LIMIT $3`,
+			wantVars:      []interface{}{createdAt, uint(5), 11},
+		},
+		{
+			name:          "backward ascending flips comparator and order",
+			position:      &CursorPosition{SortValue: "john", ID: 5, Backward: true},
+			sortField:     "name",
+			sortDirection: "asc",
+			wantSQL:       `SELECT * FROM "example_users" WHERE (name, id) < ($1, $2) AND "example_users"."deleted_at" IS NULL ORDER BY name desc,id desc LIMIT $3`,
+			wantVars:      []interface{}{"john", uint(5), 11},
+		},
+		{
+			name:          "backward descending flips comparator and order",
+			position:      &CursorPosition{SortValue: "a@example.com", ID: 5, Backward: true},
+			sortField:     "email",
+			sortDirection: "desc",
+			wantSQL:       `SELECT * FROM "example_users" WHERE (email, id) > ($1, $2) AND "example_users"."deleted_at" IS NULL ORDER BY email asc,id asc LIMIT $3`,
+			wantVars:      []interface{}{"a@example.com", uint(5), 11},
+		},
+		{
+			name:          "sorting by id compares id only",
+			position:      &CursorPosition{ID: 5},
+			sortField:     "id",
+			sortDirection: "desc",
+			wantSQL:       `SELECT * FROM "example_users" WHERE id < $1 AND "example_users"."deleted_at" IS NULL ORDER BY id desc LIMIT $2`,
+			wantVars:      []interface{}{uint(5), 11},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, queries := newDryRunRepository(t)
+			if _, err := repo.ListByCursor(context.Background(), nil, tt.position, 11, tt.sortField, tt.sortDirection); err != nil {
+				t.Fatal(err)
+			}
+			if len(*queries) != 1 {
+				t.Fatalf("got %d queries, want 1", len(*queries))
+			}
+			assertQuery(t, (*queries)[0], tt.wantSQL, tt.wantVars)
+		})
+	}
+}
+
+func TestApplyUserFilter(t *testing.T) {
+	gte := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	lt := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		filter   *UserFilter
+		wantSQL  string
+		wantVars []interface{}
+	}{
+		{
+			name:    "nil filter",
+			filter:  nil,
+			wantSQL: `SELECT * FROM "example_users" WHERE "example_users"."deleted_at" IS NULL ORDER BY id asc LIMIT $1`,
+		},
+		{
+			name:     "status and role lists",
+			filter:   &UserFilter{Statuses: []string{"active", "banned"}, Roles: []string{"admin"}},
+			wantSQL:  `SELECT * FROM "example_users" WHERE status IN ($1,$2) AND role IN ($3) AND "example_users"."deleted_at" IS NULL ORDER BY id asc LIMIT $4`,
+			wantVars: []interface{}{"active", "banned", "admin"},
+		},
+		{
+			name:     "email prefix is escaped",
+			filter:   &UserFilter{EmailPrefix: "john_100%"},
+			wantSQL:  `SELECT * FROM "example_users" WHERE email ILIKE $1 AND "example_users"."deleted_at" IS NULL ORDER BY id asc LIMIT $2`,
+			wantVars: []interface{}{`john\_100\%%`},
+		},
+		{
+			name:     "created_at range",
+			filter:   &UserFilter{CreatedAt: TimeRange{GTE: &gte, LT: &lt}},
+			wantSQL:  `SELECT * FROM "example_users" WHERE created_at >= $1 AND created_at < $2 AND "example_users"."deleted_at" IS NULL ORDER BY id asc LIMIT $3`,
+			wantVars: []interface{}{gte, lt},
+		},
+		{
+			name:     "free-text q is escaped and matches name or email",
+			filter:   &UserFilter{Query: `50%_off\`},
+			wantSQL:  `SELECT * FROM "example_users" WHERE ((name ILIKE $1 OR email ILIKE $2)) AND "example_users"."deleted_at" IS NULL ORDER BY id asc LIMIT $3`,
+			wantVars: []interface{}{`%50\%\_off\\%`, `%50\%\_off\\%`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, queries := newDryRunRepository(t)
+			if _, err := repo.ListByCursor(context.Background(), tt.filter, nil, 11, "id", "asc"); err != nil {
+				t.Fatal(err)
+			}
+			if len(*queries) != 1 {
+				t.Fatalf("got %d queries, want 1", len(*queries))
+			}
+			assertQuery(t, (*queries)[0], tt.wantSQL, append(tt.wantVars, 11))
+		})
+	}
+}
+
+func assertQuery(t *testing.T, got capturedQuery, wantSQL string, wantVars []interface{}) {
+	t.Helper()
+	if got.sql != wantSQL {
+		t.Errorf("SQL =\n  %s\nwant\n  %s", got.sql, wantSQL)
+	}
+	if len(got.vars) != len(wantVars) {
+		t.Fatalf("vars = %#v, want %#v", got.vars, wantVars)
+	}
+	for i := range wantVars {
+		if wantTime, ok := wantVars[i].(time.Time); ok {
+			if gotTime, ok := got.vars[i].(time.Time); !ok || !gotTime.Equal(wantTime) {
+				t.Errorf("vars[%d] = %#v, want %v", i, got.vars[i], wantTime)
+			}
+			continue
+		}
+		if got.vars[i] != wantVars[i] {
+			t.Errorf("vars[%d] = %#v, want %#v", i, got.vars[i], wantVars[i])
+		}
+	}
+}
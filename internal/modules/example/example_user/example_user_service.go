@@ -1,12 +1,18 @@
 package example_user
 
 import (
+	"context"
+	"database/sql"
 	"errors"
 	"fmt"
+	"go-template/internal/modules/example/example_auth"
 	"go-template/pkg/auth"
 	"go-template/pkg/custom_errors"
 	"go-template/pkg/logger"
+	"go-template/pkg/pagination"
+	"go-template/pkg/platform/postgres"
 	"strings"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -14,61 +20,90 @@ import (
 // Service คือ "สัญญา" ที่ Handler จะเรียกใช้
 // ✨ 1. แก้ไข "สัญญา" ให้รับ Domain object และ password ✨
 type Service interface {
-	CreateUser(userToCreate *Domain, plainPassword string) (*Domain, error)
-	GetUserByID(id uint) (*Domain, error)
-	ListUsersByPage(limit, offset int, sort string) ([]*Domain, int, error)
-	ListUsersByCursor(cursor string, limit int, sort string) ([]*Domain, string, bool, error)
+	CreateUser(ctx context.Context, userToCreate *Domain, plainPassword string) (*Domain, error)
+	GetUserByID(ctx context.Context, id uint) (*Domain, error)
+	ListUsersByPage(ctx context.Context, filter *UserFilter, limit, offset int, sort string) ([]*Domain, int, error)
+	ListUsersByCursor(ctx context.Context, filter *UserFilter, cursor string, limit int, sort string) ([]*Domain, *pagination.CursorPage, error)
+	Login(ctx context.Context, email, plainPassword string) (*Domain, *example_auth.TokenPair, error)
+	UpdateUser(ctx context.Context, id uint, changes *UserUpdate) (*Domain, error)
+	DeleteUser(ctx context.Context, id uint) error
+	RestoreUser(ctx context.Context, id uint) (*Domain, error)
+	HardDeleteUser(ctx context.Context, id uint) error
 }
 
 // service คือ struct ที่ทำงานจริง
 type service struct {
-	repo      Repository
-	jwtSecret string
-	log       logger.Logger
+	repo         Repository
+	tx           postgres.TxManager
+	tokenService example_auth.Service
+	rbac         *auth.RBAC
+	log          logger.Logger
 }
 
 // NewExampleUserService คือโรงงานสร้าง Service
-func NewExampleUserService(repo Repository, jwtSecret string, log logger.Logger) Service {
-	return &service{repo: repo, jwtSecret: jwtSecret, log: log}
+func NewExampleUserService(repo Repository, tx postgres.TxManager, tokenService example_auth.Service, rbac *auth.RBAC, log logger.Logger) Service {
+	return &service{repo: repo, tx: tx, tokenService: tokenService, rbac: rbac, log: log}
 }
 
 // --- Implementation ---
 
 // ✨ 2. แก้ไข "เมธอด" ให้รับ Domain object และ password ✨
-func (s *service) CreateUser(userToCreate *Domain, plainPassword string) (*Domain, error) {
-	// 1. ตรวจสอบ Logic ว่า email ซ้ำหรือไม่
-	// (ใช้ Email จาก Domain object ที่รับเข้ามา)
-	existingUser, err := s.repo.GetByEmail(userToCreate.Email)
-	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
-		return nil, custom_errors.SystemErrorWithDetails("ไม่สามารถตรวจสอบอีเมลได้", err.Error())
-	}
-	if existingUser != nil {
-		return nil, custom_errors.AlreadyExistsError("อีเมลนี้ถูกใช้งานแล้ว", nil)
-	}
-
-	// 2. Hash Password (ใช้ password ดิบๆ ที่รับเข้ามา)
+func (s *service) CreateUser(ctx context.Context, userToCreate *Domain, plainPassword string) (*Domain, error) {
+	// 1. Hash Password ก่อนเปิด Transaction (bcrypt ช้า ไม่ควรถือ Transaction ค้างไว้ระหว่างนั้น)
 	hashedPassword, err := auth.HashPassword(plainPassword)
 	if err != nil {
 		return nil, custom_errors.SystemErrorWithDetails("ไม่สามารถเข้ารหัสรหัสผ่านได้", err.Error())
 	}
 
-	// 3. เติมข้อมูลที่เหลือให้ Domain object ที่ได้รับมา
+	// 2. เติมข้อมูลที่เหลือให้ Domain object ที่ได้รับมา
 	userToCreate.PasswordHash = hashedPassword
 	userToCreate.Status = "active" // กำหนดค่าเริ่มต้นทางธุรกิจ
 	userToCreate.Role = "user"     // กำหนดค่าเริ่มต้นทางธุรกิจ
 
-	// 4. เรียกใช้ Repo เพื่อบันทึกข้อมูล
-	if err := s.repo.Create(userToCreate); err != nil {
+	// 3. ⭐️ "ตรวจ email ซ้ำ" กับ "บันทึก" ต้องอยู่ใน Transaction เดียวกัน (SERIALIZABLE)
+	// ถ้ามี 2 request สมัครด้วย email เดียวกันพร้อมกัน Postgres จะให้ตัวหนึ่งชน แล้ว TxManager จะลองใหม่ให้
+	// ซึ่งรอบใหม่จะเห็นว่า email ถูกใช้แล้ว (fn อาจถูกรันซ้ำ จึงสร้างจากสำเนาเสมอ)
+	var created Domain
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		existingUser, err := s.repo.GetByEmail(ctx, userToCreate.Email)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("check email: %w", err)
+		}
+		if existingUser != nil {
+			return custom_errors.AlreadyExistsError("อีเมลนี้ถูกใช้งานแล้ว", nil)
+		}
+
+		created = *userToCreate
+		if err := s.repo.Create(ctx, &created); err != nil {
+			if errors.Is(err, gorm.ErrDuplicatedKey) {
+				return custom_errors.AlreadyExistsError("อีเมลนี้ถูกใช้งานแล้ว", nil)
+			}
+			return err
+		}
+		return nil
+	}, postgres.WithIsolation(sql.LevelSerializable))
+	if err != nil {
+		if appErr, ok := err.(*custom_errors.AppError); ok {
+			return nil, appErr
+		}
 		return nil, custom_errors.SystemErrorWithDetails("ไม่สามารถสร้างผู้ใช้งานได้", err.Error())
 	}
-	s.log.Dumpf(logger.LevelSuccess, "Full user object after creation:", userToCreate)
-	// 5. คืนค่า Domain object ที่สมบูรณ์แล้ว (ตอนนี้มี ID, CreatedAt แล้ว) กลับไป
+
+	// 4. อัปเดตค่าที่ DB สร้างให้ (ID, CreatedAt) กลับไปที่ Domain object ที่ได้รับมา
+	*userToCreate = created
+	s.log.WithContext(ctx).Dumpf(logger.LevelSuccess, "Full user object after creation:", userToCreate)
+	// 5. คืนค่า Domain object ที่สมบูรณ์แล้วกลับไป
 	return userToCreate, nil
 }
 
-func (s *service) GetUserByID(id uint) (*Domain, error) {
+func (s *service) GetUserByID(ctx context.Context, id uint) (*Domain, error) {
+	// 0. ตรวจสิทธิ์: ดูข้อมูลตัวเองได้เสมอ แต่ถ้าดูของคนอื่นต้องมี permission
+	if err := s.rbac.RequireSelfOrPermission(ctx, id, PermissionUsersRead); err != nil {
+		return nil, err
+	}
+
 	// 1. สั่งงาน Repository ให้ไปหาข้อมูล
-	userDomain, err := s.repo.GetByID(id)
+	userDomain, err := s.repo.GetByID(ctx, id)
 
 	// 2. ⭐️ Service ทำหน้าที่ "ตีความ" Error! ⭐️
 	if err != nil {
@@ -88,7 +123,7 @@ func (s *service) GetUserByID(id uint) (*Domain, error) {
 }
 
 // ListUsersByPage handles page-based pagination and sorting.
-func (s *service) ListUsersByPage(limit, offset int, sort string) ([]*Domain, int, error) {
+func (s *service) ListUsersByPage(ctx context.Context, filter *UserFilter, limit, offset int, sort string) ([]*Domain, int, error) {
 	// 1. "แปลภาษาเข็มทิศ" และตรวจสอบความปลอดภัย
 	sortField, sortDirection, err := parseSortString(sort)
 	if err != nil {
@@ -97,7 +132,7 @@ func (s *service) ListUsersByPage(limit, offset int, sort string) ([]*Domain, in
 	}
 
 	// 2. เรียกใช้ Repository เพื่อดึงข้อมูลและจำนวนทั้งหมด
-	userDomains, totalCount, repoErr := s.repo.ListByPage(limit, offset, sortField, sortDirection)
+	userDomains, totalCount, repoErr := s.repo.ListByPage(ctx, filter, limit, offset, sortField, sortDirection)
 	if repoErr != nil {
 		return nil, 0, custom_errors.SystemErrorWithDetails("เกิดข้อผิดพลาดในการดึงข้อมูลผู้ใช้", repoErr.Error())
 	}
@@ -105,37 +140,303 @@ func (s *service) ListUsersByPage(limit, offset int, sort string) ([]*Domain, in
 	return userDomains, totalCount, nil
 }
 
-// ListUsersByCursor handles cursor-based pagination and sorting.
-func (s *service) ListUsersByCursor(cursor string, limit int, sort string) ([]*Domain, string, bool, error) {
-	// ⭐️⭐️⭐️ หมายเหตุ: การ Implement Cursor-based Pagination จริงๆ นั้นซับซ้อนมาก
-	// จะต้องมีการเข้ารหัส/ถอดรหัส cursor (เช่น base64 ของ ID หรือ Timestamp)
-	// และ Logic การ query ใน Repository ก็จะซับซ้อนกว่านี้มาก
-	//
-	// โค้ดด้านล่างนี้เป็นแค่ "โครงสร้างตัวอย่าง" เพื่อให้เห็นภาพรวมเท่านั้นนะ!
-	// ⭐️⭐️⭐️
-
+// ListUsersByCursor handles cursor-based (keyset) pagination and sorting.
+// cursor ว่าง = หน้าแรก, ส่ง next_cursor/prev_cursor ที่ได้กลับมาเพื่อเลื่อนไปหน้าถัดไป/ก่อนหน้า
+func (s *service) ListUsersByCursor(ctx context.Context, filter *UserFilter, cursor string, limit int, sort string) ([]*Domain, *pagination.CursorPage, error) {
 	sortField, sortDirection, err := parseSortString(sort)
 	if err != nil {
-		return nil, "", false, custom_errors.ValidationError("Sort parameter ไม่ถูกต้อง", err.Error())
+		return nil, nil, custom_errors.ValidationError("Sort parameter ไม่ถูกต้อง", err.Error())
 	}
 
-	// (ในชีวิตจริง เราจะต้องถอดรหัส cursor ก่อน)
-	// lastID, _ := decodeCursor(cursor)
+	// 1. ถอดรหัส cursor (ถ้ามี) ให้กลายเป็นตำแหน่งที่จะอ่านต่อ
+	var position *CursorPosition
+	if cursor != "" {
+		decoded, err := pagination.DecodeCursor(cursor)
+		if err != nil {
+			return nil, nil, custom_errors.ValidationError("Cursor ไม่ถูกต้อง", err.Error())
+		}
+		// cursor ผูกกับ sort ที่ใช้ตอนสร้าง ถ้าเปลี่ยน sort ต้องเริ่มหน้าแรกใหม่
+		if decoded.SortField != sortField || decoded.SortDirection != sortDirection {
+			return nil, nil, custom_errors.ValidationError("Cursor ไม่ตรงกับรูปแบบการเรียงข้อมูลที่ใช้อยู่", "cursor was created with sort "+decoded.SortField+":"+decoded.SortDirection)
+		}
+		position, err = toCursorPosition(decoded)
+		if err != nil {
+			return nil, nil, custom_errors.ValidationError("Cursor ไม่ถูกต้อง", err.Error())
+		}
+	}
 
-	userDomains, repoErr := s.repo.ListByCursor(0, limit, sortField, sortDirection) // ส่ง lastID เข้าไป
+	// 2. ดึงเกินมา 1 แถว เพื่อดูว่ายังมีข้อมูลต่อจากนี้อีกไหม
+	userDomains, repoErr := s.repo.ListByCursor(ctx, filter, position, limit+1, sortField, sortDirection)
 	if repoErr != nil {
-		return nil, "", false, custom_errors.SystemErrorWithDetails("เกิดข้อผิดพลาดในการดึงข้อมูลผู้ใช้", repoErr.Error())
+		return nil, nil, custom_errors.SystemErrorWithDetails("เกิดข้อผิดพลาดในการดึงข้อมูลผู้ใช้", repoErr.Error())
+	}
+	hasExtra := len(userDomains) > limit
+	if hasExtra {
+		userDomains = userDomains[:limit]
+	}
+
+	// 3. ถ้าอ่านย้อนกลับ Repository จะคืนข้อมูลแบบกลับด้าน ต้องกลับคืนก่อนส่งออก
+	page := &pagination.CursorPage{}
+	if position != nil && position.Backward {
+		for i, j := 0, len(userDomains)-1; i < j; i, j = i+1, j-1 {
+			userDomains[i], userDomains[j] = userDomains[j], userDomains[i]
+		}
+		page.HasMore = true // เราย้อนมาจากหน้าถัดไป แปลว่าหน้าถัดไปมีแน่ๆ
+		page.HasPrevious = hasExtra
+	} else {
+		page.HasMore = hasExtra
+		page.HasPrevious = position != nil
+	}
+
+	// 4. สร้าง cursor จากแถวแรก/แถวสุดท้ายของหน้านี้
+	if len(userDomains) > 0 {
+		if page.HasMore {
+			page.NextCursor = newUserCursor(userDomains[len(userDomains)-1], sortField, sortDirection, false).Encode()
+		}
+		if page.HasPrevious {
+			page.PrevCursor = newUserCursor(userDomains[0], sortField, sortDirection, true).Encode()
+		}
+	}
+
+	return userDomains, page, nil
+}
+
+// dummyPasswordHash คือ bcrypt hash (cost เดียวกับ auth.HashPassword) ที่ใช้เทียบตอนไม่พบอีเมล
+const dummyPasswordHash = "$2a$10$R.du25NM8yWcRv3Q.IB2.ul1Y32jSFzHYXFpQ4tHRXL.meXc/24lS"
+
+// Login ตรวจสอบอีเมล/รหัสผ่าน แล้วออก Access Token + Refresh Token ให้ผู้ใช้
+func (s *service) Login(ctx context.Context, email, plainPassword string) (*Domain, *example_auth.TokenPair, error) {
+	// ⭐️ ใช้ข้อความเดียวกันทั้งกรณี "ไม่พบอีเมล" และ "รหัสผ่านผิด"
+	// เพื่อไม่ให้คนนอกใช้ endpoint นี้เดาได้ว่าอีเมลไหนมีอยู่ในระบบ
+	invalidCredentials := custom_errors.UnauthorizedError("อีเมลหรือรหัสผ่านไม่ถูกต้อง")
+
+	// 1. หา User จากอีเมล
+	user, err := s.repo.GetByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// เทียบกับ hash หลอกให้เสียเวลาเท่ากับกรณีรหัสผ่านผิด ไม่ให้จับเวลาตอบกลับแล้วรู้ว่าอีเมลไม่มีในระบบ
+			_ = auth.ComparePassword(dummyPasswordHash, plainPassword)
+			return nil, nil, invalidCredentials
+		}
+		return nil, nil, custom_errors.SystemErrorWithDetails("ไม่สามารถตรวจสอบข้อมูลผู้ใช้ได้", err.Error())
+	}
+
+	// 2. เทียบรหัสผ่านกับ bcrypt hash ที่เก็บไว้
+	if err := auth.ComparePassword(user.PasswordHash, plainPassword); err != nil {
+		return nil, nil, invalidCredentials
+	}
+
+	// 3. ตรวจสอบสถานะบัญชี (ผู้ใช้ที่ถูกระงับห้ามเข้าสู่ระบบ)
+	if user.Status != "active" {
+		return nil, nil, custom_errors.PermissionDeniedError("บัญชีผู้ใช้นี้ไม่สามารถเข้าสู่ระบบได้")
+	}
+
+	// 4. ออก Token คู่ใหม่ (เริ่ม session ใหม่)
+	tokenPair, err := s.tokenService.IssueTokens(ctx, toSubject(user))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// 5. บันทึกเวลาเข้าสู่ระบบล่าสุด
+	loginAt := time.Now()
+	if err := s.repo.UpdateLastLoginAt(ctx, user.ID, loginAt); err != nil {
+		return nil, nil, custom_errors.SystemErrorWithDetails("ไม่สามารถบันทึกเวลาเข้าสู่ระบบได้", err.Error())
+	}
+	user.LastLoginAt = &loginAt
+
+	s.log.WithContext(ctx).Info("User logged in", "user_id", user.ID)
+	return user, tokenPair, nil
+}
+
+// UpdateUser แก้ไขข้อมูลผู้ใช้ (ใช้ได้ทั้ง PUT และ PATCH เพราะ field ที่เป็น nil จะไม่ถูกแก้)
+func (s *service) UpdateUser(ctx context.Context, id uint, changes *UserUpdate) (*Domain, error) {
+	// 1. ตรวจสิทธิ์: แก้ของตัวเองได้ แต่ถ้าแก้ของคนอื่นต้องมี permission
+	if err := s.rbac.RequireSelfOrPermission(ctx, id, PermissionUsersUpdate); err != nil {
+		return nil, err
+	}
+	// การเปลี่ยน status/role เป็นเรื่องของผู้ดูแลระบบเท่านั้น (ห้ามตั้งตัวเองเป็น admin!)
+	if changes.Status != nil || changes.Role != nil {
+		if err := s.rbac.RequirePermission(ctx, PermissionUsersManage); err != nil {
+			return nil, err
+		}
+	}
+
+	// 2. หา User ตัวจริงก่อน
+	user, err := s.findUser(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. ถ้าเปลี่ยนอีเมล ต้องไม่ชนกับผู้ใช้คนอื่น
+	if changes.Email != nil && *changes.Email != user.Email {
+		existingUser, err := s.repo.GetByEmail(ctx, *changes.Email)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, custom_errors.SystemErrorWithDetails("ไม่สามารถตรวจสอบอีเมลได้", err.Error())
+		}
+		if existingUser != nil && existingUser.ID != user.ID {
+			return nil, custom_errors.AlreadyExistsError("อีเมลนี้ถูกใช้งานแล้ว", nil)
+		}
+	}
+
+	// 4. เอาค่าที่ส่งมาทับลงไป
+	if changes.Name != nil {
+		user.Name = *changes.Name
+	}
+	if changes.Email != nil {
+		user.Email = *changes.Email
+	}
+	if changes.Status != nil {
+		user.Status = *changes.Status
+	}
+	if changes.Role != nil {
+		user.Role = *changes.Role
+	}
+
+	// 5. บันทึก
+	if err := s.repo.Update(ctx, user); err != nil {
+		// กันกรณีมีคนชิงใช้อีเมลนี้ระหว่างขั้นตอนที่ 3 กับ 5
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, custom_errors.AlreadyExistsError("อีเมลนี้ถูกใช้งานแล้ว", nil)
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, custom_errors.NotFoundError(fmt.Sprintf("ไม่พบผู้ใช้งาน ID: %d", id))
+		}
+		return nil, custom_errors.SystemErrorWithDetails("ไม่สามารถแก้ไขข้อมูลผู้ใช้ได้", err.Error())
+	}
+
+	s.log.WithContext(ctx).Info("User updated", "user_id", user.ID)
+	return user, nil
+}
+
+// DeleteUser ลบผู้ใช้แบบ soft delete (ยังกู้คืนได้)
+func (s *service) DeleteUser(ctx context.Context, id uint) error {
+	if err := s.rbac.RequireSelfOrPermission(ctx, id, PermissionUsersDelete); err != nil {
+		return err
+	}
+
+	if err := s.repo.Delete(ctx, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return custom_errors.NotFoundError(fmt.Sprintf("ไม่พบผู้ใช้งาน ID: %d", id))
+		}
+		return custom_errors.SystemErrorWithDetails("ไม่สามารถลบผู้ใช้ได้", err.Error())
+	}
+
+	s.log.WithContext(ctx).Info("User soft-deleted", "user_id", id)
+	return nil
+}
+
+// RestoreUser กู้คืนผู้ใช้ที่ถูก soft delete
+func (s *service) RestoreUser(ctx context.Context, id uint) (*Domain, error) {
+	if err := s.rbac.RequirePermission(ctx, PermissionUsersRestore); err != nil {
+		return nil, err
+	}
+
+	// 1. ต้องเป็นผู้ใช้ที่มีอยู่จริง และถูกลบไปแล้วเท่านั้น
+	user, err := s.repo.GetByIDUnscoped(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, custom_errors.NotFoundError(fmt.Sprintf("ไม่พบผู้ใช้งาน ID: %d", id))
+		}
+		return nil, custom_errors.SystemErrorWithDetails("เกิดข้อผิดพลาดในการค้นหาข้อมูลผู้ใช้", err.Error())
+	}
+	if user.DeletedAt == nil {
+		return nil, custom_errors.ConflictError("ผู้ใช้นี้ยังไม่ได้ถูกลบ", map[string]interface{}{"id": id})
 	}
 
-	// (ในชีวิตจริง เราจะต้องสร้าง nextCursor และเช็ค hasMore จากข้อมูลที่ได้)
-	nextCursor := "next_cursor_placeholder"
-	hasMore := len(userDomains) == limit
+	// 2. กู้คืน (index unique_active_email จะกันไม่ให้มีอีเมลซ้ำกับผู้ใช้ active)
+	if err := s.repo.Restore(ctx, id); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, custom_errors.ConflictError(
+				"ไม่สามารถกู้คืนผู้ใช้ได้ เนื่องจากอีเมลนี้ถูกผู้ใช้อื่นใช้งานอยู่",
+				map[string]interface{}{"id": id, "email": user.Email},
+			)
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, custom_errors.ConflictError("ผู้ใช้นี้ถูกกู้คืนไปแล้ว", map[string]interface{}{"id": id})
+		}
+		return nil, custom_errors.SystemErrorWithDetails("ไม่สามารถกู้คืนผู้ใช้ได้", err.Error())
+	}
 
-	return userDomains, nextCursor, hasMore, nil
+	s.log.WithContext(ctx).Info("User restored", "user_id", id)
+	return s.findUser(ctx, id)
+}
+
+// HardDeleteUser ลบผู้ใช้ออกจากระบบถาวร (admin เท่านั้น)
+func (s *service) HardDeleteUser(ctx context.Context, id uint) error {
+	if err := auth.RequireRole(ctx, auth.RoleAdmin); err != nil {
+		return err
+	}
+
+	if err := s.repo.HardDelete(ctx, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return custom_errors.NotFoundError(fmt.Sprintf("ไม่พบผู้ใช้งาน ID: %d", id))
+		}
+		// FK ของ example_orders เป็น ON DELETE RESTRICT
+		if errors.Is(err, gorm.ErrForeignKeyViolated) {
+			return custom_errors.ConflictError("ไม่สามารถลบผู้ใช้ถาวรได้ เนื่องจากยังมีข้อมูลอื่นอ้างอิงอยู่", map[string]interface{}{"id": id})
+		}
+		return custom_errors.SystemErrorWithDetails("ไม่สามารถลบผู้ใช้ถาวรได้", err.Error())
+	}
+
+	s.log.WithContext(ctx).Warn("User permanently deleted", "user_id", id)
+	return nil
 }
 
 // --- Private Helper ---
 
+// newUserCursor สร้าง cursor ที่ชี้ไปที่ผู้ใช้คนนี้ ตาม field ที่ใช้เรียง
+func newUserCursor(d *Domain, sortField, sortDirection string, backward bool) pagination.Cursor {
+	c := pagination.Cursor{
+		SortField:     sortField,
+		SortDirection: sortDirection,
+		ID:            d.ID,
+		Backward:      backward,
+	}
+	switch sortField {
+	case "name":
+		c.Value = d.Name
+	case "email":
+		c.Value = d.Email
+	case "created_at":
+		c.Value = d.CreatedAt.UTC().Format(time.RFC3339Nano)
+	case "updated_at":
+		c.Value = d.UpdatedAt.UTC().Format(time.RFC3339Nano)
+	}
+	return c
+}
+
+// toCursorPosition แปลงค่าใน cursor กลับเป็นชนิดข้อมูลจริงของ field ที่ใช้เรียง
+func toCursorPosition(c *pagination.Cursor) (*CursorPosition, error) {
+	position := &CursorPosition{ID: c.ID, Backward: c.Backward}
+	switch c.SortField {
+	case "id":
+		// ใช้แค่ ID ก็พอ
+	case "name", "email":
+		position.SortValue = c.Value
+	case "created_at", "updated_at":
+		t, err := time.Parse(time.RFC3339Nano, c.Value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cursor time value: %w", err)
+		}
+		position.SortValue = t
+	default:
+		return nil, errors.New("sorting by this field is not allowed: " + c.SortField)
+	}
+	return position, nil
+}
+
+// findUser หาผู้ใช้ (ที่ยังไม่ถูกลบ) และแปลง error เป็น AppError ให้เรียบร้อย
+func (s *service) findUser(ctx context.Context, id uint) (*Domain, error) {
+	user, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, custom_errors.NotFoundError(fmt.Sprintf("ไม่พบผู้ใช้งาน ID: %d", id))
+		}
+		return nil, custom_errors.SystemErrorWithDetails("เกิดข้อผิดพลาดในการค้นหาข้อมูลผู้ใช้", err.Error())
+	}
+	return user, nil
+}
+
 // parseSortString คือ "นักแปลภาษาเข็มทิศ"
 // มันจะแกะ string "field:direction" ออกมา และตรวจสอบกับ "แผนที่" (whitelist)
 func parseSortString(sort string) (field string, direction string, err error) {
@@ -167,3 +468,36 @@ func parseSortString(sort string) (field string, direction string, err error) {
 
 	return field, direction, nil
 }
+
+// ====================================================================================
+// Subject Provider (ให้โมดูล example_auth ถามข้อมูลผู้ใช้ตอน refresh token)
+// ====================================================================================
+
+// subjectProvider implement example_auth.SubjectProvider โดยอ่านข้อมูลจาก Repository ของเรา
+type subjectProvider struct {
+	repo Repository
+}
+
+// NewSubjectProvider คือโรงงานสร้าง example_auth.SubjectProvider จาก Repository ของ User
+func NewSubjectProvider(repo Repository) example_auth.SubjectProvider {
+	return &subjectProvider{repo: repo}
+}
+
+func (p *subjectProvider) GetActiveSubject(ctx context.Context, userID uint) (*example_auth.Subject, error) {
+	user, err := p.repo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Status != "active" {
+		return nil, errors.New("user is not active")
+	}
+	return toSubject(user), nil
+}
+
+func toSubject(user *Domain) *example_auth.Subject {
+	return &example_auth.Subject{
+		UserID: user.ID,
+		Email:  user.Email,
+		Role:   user.Role,
+	}
+}
@@ -0,0 +1,149 @@
+package example_user
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"go-template/pkg/auth"
+	"go-template/pkg/custom_errors"
+	"go-template/pkg/logger"
+	"go-template/pkg/pagination"
+)
+
+func TestParseSortString(t *testing.T) {
+	tests := []struct {
+		sort          string
+		wantField     string
+		wantDirection string
+		wantErr       bool
+	}{
+		{sort: "id:asc", wantField: "id", wantDirection: "asc"},
+		{sort: "name:desc", wantField: "name", wantDirection: "desc"},
+		{sort: "email:asc", wantField: "email", wantDirection: "asc"},
+		{sort: "created_at:desc", wantField: "created_at", wantDirection: "desc"},
+		{sort: "updated_at:asc", wantField: "updated_at", wantDirection: "asc"},
+		{sort: "password_hash:asc", wantErr: true},
+		{sort: "name; DROP TABLE example_users:asc", wantErr: true},
+		{sort: "name:ASC", wantErr: true},
+		{sort: "name:sideways", wantErr: true},
+		{sort: "name", wantErr: true},
+		{sort: "name:asc:extra", wantErr: true},
+		{sort: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		field, direction, err := parseSortString(tt.sort)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseSortString(%q) error = %v, wantErr %v", tt.sort, err, tt.wantErr)
+			continue
+		}
+		if field != tt.wantField || direction != tt.wantDirection {
+			t.Errorf("parseSortString(%q) = (%q, %q), want (%q, %q)", tt.sort, field, direction, tt.wantField, tt.wantDirection)
+		}
+	}
+}
+
+func TestUserCursorRoundTrip(t *testing.T) {
+	user := &Domain{
+		ID:        42,
+		Name:      "สมชาย ใจดี",
+		Email:     "somchai@example.com",
+		CreatedAt: time.Date(2025, 1, 2, 3, 4, 5, 123456789, time.FixedZone("ICT", 7*60*60)),
+		UpdatedAt: time.Date(2025, 3, 4, 5, 6, 7, 0, time.UTC),
+	}
+
+	tests := []struct {
+		sortField string
+		backward  bool
+		want      interface{}
+	}{
+		{sortField: "id", want: nil},
+		{sortField: "name", want: user.Name},
+		{sortField: "email", backward: true, want: user.Email},
+		{sortField: "created_at", want: user.CreatedAt},
+		{sortField: "updated_at", backward: true, want: user.UpdatedAt},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sortField, func(t *testing.T) {
+			decoded, err := pagination.DecodeCursor(newUserCursor(user, tt.sortField, "asc", tt.backward).Encode())
+			if err != nil {
+				t.Fatal(err)
+			}
+			position, err := toCursorPosition(decoded)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if position.ID != user.ID || position.Backward != tt.backward {
+				t.Errorf("position = %+v, want ID %d backward %v", position, user.ID, tt.backward)
+			}
+			if want, ok := tt.want.(time.Time); ok {
+				got, ok := position.SortValue.(time.Time)
+				if !ok || !got.Equal(want) {
+					t.Errorf("SortValue = %#v, want %v", position.SortValue, want)
+				}
+				return
+			}
+			if position.SortValue != tt.want {
+				t.Errorf("SortValue = %#v, want %#v", position.SortValue, tt.want)
+			}
+		})
+	}
+}
+
+func TestToCursorPositionRejectsBadCursors(t *testing.T) {
+	tests := []struct {
+		name   string
+		cursor pagination.Cursor
+	}{
+		{name: "unknown sort field", cursor: pagination.Cursor{SortField: "password_hash", SortDirection: "asc", Value: "x", ID: 1}},
+		{name: "bad time value", cursor: pagination.Cursor{SortField: "created_at", SortDirection: "asc", Value: "yesterday", ID: 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := toCursorPosition(&tt.cursor); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestListUsersByCursorRejectsBadCursors(t *testing.T) {
+	s := &service{log: logger.NewSlogLogger()}
+
+	tests := []struct {
+		name   string
+		cursor string
+		sort   string
+	}{
+		{name: "garbage cursor", cursor: "not-a-cursor", sort: "id:asc"},
+		{name: "cursor from another sort field", cursor: pagination.Cursor{SortField: "name", SortDirection: "asc", Value: "a", ID: 1}.Encode(), sort: "email:asc"},
+		{name: "cursor from another direction", cursor: pagination.Cursor{SortField: "id", SortDirection: "asc", ID: 1}.Encode(), sort: "id:desc"},
+		{name: "unknown sort", cursor: "", sort: "password_hash:asc"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := s.ListUsersByCursor(context.Background(), nil, tt.cursor, 10, tt.sort)
+			appErr, ok := err.(*custom_errors.AppError)
+			if !ok || appErr.Code != custom_errors.ErrValidation {
+				t.Errorf("error = %v, want a validation error", err)
+			}
+		})
+	}
+}
+
+func TestDummyPasswordHashIsValidBcrypt(t *testing.T) {
+	// hash ต้องใช้ได้จริง ไม่อย่างนั้น bcrypt จะคืน error ทันทีและเวลาตอบกลับก็ต่างกันอีก
+	cost, err := bcrypt.Cost([]byte(dummyPasswordHash))
+	if err != nil {
+		t.Fatalf("dummyPasswordHash is not a bcrypt hash: %v", err)
+	}
+	if cost != bcrypt.DefaultCost {
+		t.Errorf("dummyPasswordHash cost = %d, want %d (same as auth.HashPassword)", cost, bcrypt.DefaultCost)
+	}
+	if err := auth.ComparePassword(dummyPasswordHash, "anything"); !errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		t.Errorf("ComparePassword(dummy) error = %v, want mismatch", err)
+	}
+}
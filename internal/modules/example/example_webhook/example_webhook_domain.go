@@ -0,0 +1,53 @@
+package example_webhook
+
+import (
+	"context"
+	"go-template/internal/adapters/secondary/shipping"
+	"go-template/internal/modules/example/example_order"
+	"time"
+)
+
+// Event คือ webhook 1 ครั้งที่ได้รับจากขนส่ง (เก็บ payload ดิบไว้เสมอเพื่อให้ replay ได้)
+type Event struct {
+	ID             uint
+	Provider       string
+	EventID        string
+	TrackingNumber string
+	Payload        []byte
+	Status         string
+	Attempts       int
+	LastError      string
+	ProcessedAt    *time.Time
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// Event statuses (ต้องตรงกับ CHECK constraint check_webhook_event_status)
+const (
+	EventStatusReceived   = "received"   // บันทึกแล้ว ยังไม่ได้ประมวลผล
+	EventStatusProcessing = "processing" // มีคำขอหนึ่งจองไปประมวลผลอยู่ (คำขออื่นห้ามประมวลผลซ้อน)
+	EventStatusProcessed  = "processed"  // ประมวลผลสำเร็จ (ได้รับซ้ำจะถูกข้าม)
+	EventStatusFailed     = "failed"     // ประมวลผลไม่สำเร็จ (ได้รับซ้ำหรือ replay จะประมวลผลใหม่)
+)
+
+// Permissions ของโมดูลนี้
+const (
+	PermissionWebhooksRead   = "webhooks:read"   // ดูรายการ webhook ที่ได้รับ
+	PermissionWebhooksReplay = "webhooks:replay" // สั่งประมวลผล webhook ใหม่
+)
+
+// Signature คือลายเซ็นที่แนบมากับ webhook (อ่านจาก Header)
+type Signature struct {
+	Timestamp string
+	Value     string
+}
+
+// ProviderLookup คือ "พอร์ต" สำหรับหาขนส่งตามชื่อ (ปกติคือ *shipping.Registry)
+type ProviderLookup interface {
+	Get(name string) (shipping.Provider, error)
+}
+
+// ShipmentUpdater คือ "พอร์ต" ที่โมดูลนี้ใช้ส่งสถานะพัสดุต่อให้โมดูล Order
+type ShipmentUpdater interface {
+	ApplyShipmentUpdate(ctx context.Context, update *example_order.ShipmentUpdate) error
+}
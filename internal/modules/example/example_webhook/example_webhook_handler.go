@@ -0,0 +1,203 @@
+package example_webhook
+
+import (
+	"go-template/internal/adapters/primary/http/middleware"
+	"go-template/pkg/auth"
+	"go-template/pkg/custom_errors"
+	"go-template/pkg/logger"
+	"go-template/pkg/response"
+	"go-template/pkg/validator"
+	"go-template/pkg/webhook"
+	"strings"
+	"time"
+
+	govalidator "github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v3"
+)
+
+// ====================================================================================
+// DTOs (Data Transfer Objects)
+// ====================================================================================
+
+type ReceiveParams struct {
+	Provider string `uri:"provider" validate:"required,max=50"`
+}
+
+type EventIDParams struct {
+	ID uint `uri:"id" validate:"required,gte=1"`
+}
+
+type ListEventsQuery struct {
+	Status *string `query:"status" validate:"omitempty,oneof=received processing processed failed"`
+	Limit  *int    `query:"limit" validate:"omitempty,gte=1,lte=100"`
+	Page   *int    `query:"page" validate:"omitempty,gte=1"`
+	Offset *int    `query:"offset" validate:"omitempty,gte=0"`
+}
+
+// ReceiveResponse คือสิ่งที่ตอบกลับขนส่ง (สั้นๆ พอให้รู้ว่ารับแล้ว)
+type ReceiveResponse struct {
+	EventID   string `json:"event_id"`
+	Status    string `json:"status"`
+	Duplicate bool   `json:"duplicate"`
+}
+
+type EventResponse struct {
+	ID             uint       `json:"id"`
+	Provider       string     `json:"provider"`
+	EventID        string     `json:"event_id"`
+	TrackingNumber string     `json:"tracking_number,omitempty"`
+	Status         string     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	LastError      string     `json:"last_error,omitempty"`
+	Payload        string     `json:"payload"`
+	ProcessedAt    *time.Time `json:"processed_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// ====================================================================================
+// Handler
+// ====================================================================================
+
+// handler คือ struct ที่ทำงานจริง
+type handler struct {
+	service         Service
+	log             logger.Logger
+	bangkokLocation *time.Location
+	validator       *govalidator.Validate
+}
+
+// NewExampleWebhookHandler คือโรงงานสร้าง Handler
+func NewExampleWebhookHandler(service Service, log logger.Logger, bangkokLocation *time.Location, validator *govalidator.Validate) *handler {
+	return &handler{
+		service:         service,
+		log:             log,
+		bangkokLocation: bangkokLocation,
+		validator:       validator,
+	}
+}
+
+// --- Handler Methods ---
+
+func (h *handler) Receive(c fiber.Ctx) error {
+	params := new(ReceiveParams)
+	if err := c.Bind().URI(params); err != nil {
+		return response.Error(c, custom_errors.ValidationError("provider ไม่ถูกต้อง", err.Error()))
+	}
+	if validationResult := validator.Validate(h.validator, params); !validationResult.IsValid {
+		return response.Error(c, custom_errors.ValidationError("provider ไม่ถูกต้อง", validationResult.Errors))
+	}
+
+	signature := &Signature{
+		Timestamp: c.Get(webhook.HeaderTimestamp),
+		Value:     c.Get(webhook.HeaderSignature),
+	}
+	// ⭐️ ต้องใช้ body ดิบตามที่ได้รับทุกไบต์ (ห้าม Bind แล้ว marshal ใหม่ ไม่งั้นลายเซ็นจะไม่ตรง)
+	// และต้อง copy ออกมา เพราะ buffer ของ fasthttp จะถูกนำกลับไปใช้ซ้ำหลังจบ request
+	payload := append([]byte(nil), c.Body()...)
+
+	event, duplicate, serviceErr := h.service.Receive(c, strings.ToLower(params.Provider), signature, payload)
+	if serviceErr != nil {
+		return response.Error(c, serviceErr.(*custom_errors.AppError))
+	}
+
+	responsePayload := &ReceiveResponse{
+		EventID:   event.EventID,
+		Status:    event.Status,
+		Duplicate: duplicate,
+	}
+	return response.Success(c, fiber.StatusOK, "Webhook received", responsePayload, nil)
+}
+
+func (h *handler) ListEvents(c fiber.Ctx) error {
+	query := new(ListEventsQuery)
+	if err := c.Bind().Query(query); err != nil {
+		appErr := custom_errors.InvalidFormatError("Query parameter ไม่ถูกต้อง", err.Error())
+		return response.Error(c, appErr)
+	}
+
+	if validationResult := validator.Validate(h.validator, query); !validationResult.IsValid {
+		appErr := custom_errors.ValidationError("Query parameter ไม่ถูกต้อง", validationResult.Errors)
+		return response.Error(c, appErr)
+	}
+
+	status := ""
+	if query.Status != nil {
+		status = *query.Status
+	}
+	limit := 10
+	if query.Limit != nil {
+		limit = *query.Limit
+	}
+	offset := 0
+	if query.Offset != nil {
+		offset = *query.Offset
+	} else if query.Page != nil {
+		offset = (*query.Page - 1) * limit
+	}
+
+	events, totalCount, serviceErr := h.service.ListEvents(c, status, limit, offset)
+	if serviceErr != nil {
+		return response.Error(c, serviceErr.(*custom_errors.AppError))
+	}
+
+	responsePayloads := make([]*EventResponse, 0, len(events))
+	for _, e := range events {
+		responsePayloads = append(responsePayloads, h.toResponse(e))
+	}
+	pagination := response.NewPagePagination(totalCount, limit, offset)
+	return response.Success(c, fiber.StatusOK, "Webhook events retrieved successfully", responsePayloads, pagination)
+}
+
+func (h *handler) Replay(c fiber.Ctx) error {
+	params := new(EventIDParams)
+	if err := c.Bind().URI(params); err != nil {
+		return response.Error(c, custom_errors.ValidationError("ID ที่ส่งมาไม่ถูกต้อง", fiber.Map{"id": "must be a positive integer"}))
+	}
+	if validationResult := validator.Validate(h.validator, params); !validationResult.IsValid {
+		return response.Error(c, custom_errors.ValidationError("ID ที่ส่งมาไม่ถูกต้อง", validationResult.Errors))
+	}
+
+	event, serviceErr := h.service.Replay(c, params.ID)
+	if serviceErr != nil {
+		return response.Error(c, serviceErr.(*custom_errors.AppError))
+	}
+
+	return response.Success(c, fiber.StatusOK, "Webhook event replayed", h.toResponse(event), nil)
+}
+
+// RegisterRoutes ลงทะเบียน routes ทั้งหมดของโมดูลนี้
+// route รับ webhook เป็น public (ยืนยันตัวตนด้วยลายเซ็น HMAC แทน JWT) ส่วน route ดู/replay ต้องเข้าสู่ระบบ
+func (h *handler) RegisterRoutes(router fiber.Router, authMiddleware fiber.Handler, rbac *auth.RBAC) {
+	webhookRouter := router.Group("/webhooks")
+
+	// --- Public routes (ตรวจลายเซ็นใน Service) ---
+	webhookRouter.Post("/carriers/:provider", h.Receive)
+
+	// --- Protected routes ---
+	webhookRouter.Get("/carrier-events", authMiddleware, middleware.RequirePermission(rbac, PermissionWebhooksRead), h.ListEvents)
+	webhookRouter.Post("/carrier-events/:id/replay", authMiddleware, middleware.RequirePermission(rbac, PermissionWebhooksReplay), h.Replay)
+}
+
+// --- Private Helpers ---
+
+func (h *handler) toResponse(e *Event) *EventResponse {
+	var processedAt *time.Time
+	if e.ProcessedAt != nil {
+		t := e.ProcessedAt.In(h.bangkokLocation)
+		processedAt = &t
+	}
+	return &EventResponse{
+		ID:             e.ID,
+		Provider:       e.Provider,
+		EventID:        e.EventID,
+		TrackingNumber: e.TrackingNumber,
+		Status:         e.Status,
+		Attempts:       e.Attempts,
+		LastError:      e.LastError,
+		Payload:        string(e.Payload),
+		ProcessedAt:    processedAt,
+		CreatedAt:      e.CreatedAt.In(h.bangkokLocation),
+		UpdatedAt:      e.UpdatedAt.In(h.bangkokLocation),
+	}
+}
@@ -0,0 +1,192 @@
+package example_webhook
+
+import (
+	"context"
+	"errors"
+	"go-template/pkg/logger"
+	"go-template/pkg/platform/postgres"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrEventNotClaimed ถูกคืนจาก MarkResult เมื่อ event ไม่ได้อยู่ในสถานะ processing แล้ว
+// (เช่น ค้างนานจนมีคำขออื่นจองต่อไป) ผลของเราจึงไม่ถูกบันทึกทับ
+var ErrEventNotClaimed = errors.New("webhook event is not claimed for processing")
+
+// Repository คือ "สัญญา" ที่ Service จะเรียกใช้
+type Repository interface {
+	Create(ctx context.Context, e *Event) error
+	GetByID(ctx context.Context, id uint) (*Event, error)
+	GetByProviderEventID(ctx context.Context, provider, eventID string) (*Event, error)
+	Claim(ctx context.Context, e *Event, staleBefore time.Time) (bool, error)
+	MarkResult(ctx context.Context, e *Event) error
+	List(ctx context.Context, status string, limit, offset int) ([]*Event, int, error)
+}
+
+// Model คือ "ชุดเกราะ" สำหรับ GORM (ตาราง example_carrier_webhook_events)
+type Model struct {
+	ID             uint      `gorm:"primarykey"`
+	CreatedAt      time.Time `gorm:"not null"`
+	UpdatedAt      time.Time `gorm:"not null"`
+	Provider       string    `gorm:"not null"`
+	EventID        string    `gorm:"not null"`
+	TrackingNumber *string
+	Payload        string `gorm:"type:text;not null"`
+	Status         string `gorm:"not null;default:received"`
+	Attempts       int    `gorm:"not null;default:0"`
+	LastError      *string
+	ProcessedAt    *time.Time
+}
+
+func (Model) TableName() string {
+	return "example_carrier_webhook_events"
+}
+
+// repository คือ struct ที่ทำงานจริง
+type repository struct {
+	db  *gorm.DB
+	log logger.Logger
+}
+
+// NewExampleWebhookRepository คือโรงงานสร้าง Repository
+func NewExampleWebhookRepository(db *gorm.DB, log logger.Logger) Repository {
+	return &repository{db: db, log: log}
+}
+
+// --- Implementation ---
+
+// Create บันทึก webhook ใหม่ ถ้า (provider, event_id) ซ้ำจะได้ gorm.ErrDuplicatedKey
+func (r *repository) Create(ctx context.Context, e *Event) error {
+	gormModel := toGORM(e)
+	if err := postgres.DB(ctx, r.db).Create(gormModel).Error; err != nil {
+		return err
+	}
+	*e = *gormModel.toDomain()
+	return nil
+}
+
+func (r *repository) GetByID(ctx context.Context, id uint) (*Event, error) {
+	var gormModel Model
+	if err := postgres.DB(ctx, r.db).First(&gormModel, id).Error; err != nil {
+		return nil, err
+	}
+	return gormModel.toDomain(), nil
+}
+
+// GetByProviderEventID อ่านจาก primary เสมอ เพราะใช้ตรวจ event ซ้ำ (replica ที่ตามไม่ทันจะทำให้ประมวลผลซ้ำได้)
+func (r *repository) GetByProviderEventID(ctx context.Context, provider, eventID string) (*Event, error) {
+	var gormModel Model
+	if err := postgres.DB(postgres.WithPrimary(ctx), r.db).Where("provider = ? AND event_id = ?", provider, eventID).First(&gormModel).Error; err != nil {
+		return nil, err
+	}
+	return gormModel.toDomain(), nil
+}
+
+// Claim "จอง" event ไปประมวลผลแบบ atomic (เปลี่ยนเป็น processing และนับจำนวนครั้งที่ประมวลผล)
+// จองได้เฉพาะ event ที่ received/failed หรือ processing ที่ค้างมาตั้งแต่ก่อน staleBefore (คนที่จองไว้น่าจะล่มไปแล้ว)
+// คืน false เมื่อมีคำขออื่นจองไปก่อนหรือประมวลผลสำเร็จไปแล้ว
+func (r *repository) Claim(ctx context.Context, e *Event, staleBefore time.Time) (bool, error) {
+	result := postgres.DB(ctx, r.db).Model(&Model{}).
+		Where("id = ? AND (status IN ? OR (status = ? AND updated_at < ?))",
+			e.ID, []string{EventStatusReceived, EventStatusFailed}, EventStatusProcessing, staleBefore).
+		Updates(map[string]interface{}{
+			"status":   EventStatusProcessing,
+			"attempts": gorm.Expr("attempts + 1"),
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+	e.Status = EventStatusProcessing
+	e.Attempts++
+	return true, nil
+}
+
+// MarkResult บันทึกผลการประมวลผล (status, last_error, processed_at) ของ event ที่เราจองไว้
+// ⭐️ อัปเดตเฉพาะแถวที่ยังเป็น processing เท่านั้น จึงไม่มีทางเปลี่ยน event ที่ processed แล้วกลับเป็น failed
+func (r *repository) MarkResult(ctx context.Context, e *Event) error {
+	result := postgres.DB(ctx, r.db).Model(&Model{}).
+		Where("id = ? AND status = ?", e.ID, EventStatusProcessing).
+		Updates(map[string]interface{}{
+			"status":       e.Status,
+			"last_error":   nullableString(e.LastError),
+			"processed_at": e.ProcessedAt,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrEventNotClaimed
+	}
+	return nil
+}
+
+// List ดึงรายการ webhook (ใหม่สุดขึ้นก่อน) status ว่าง = ทุกสถานะ
+func (r *repository) List(ctx context.Context, status string, limit, offset int) ([]*Event, int, error) {
+	var gormModels []Model
+	var totalCount int64
+
+	query := postgres.DB(ctx, r.db).Model(&Model{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if err := query.Count(&totalCount).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := query.Order("id desc").Limit(limit).Offset(offset).Find(&gormModels).Error; err != nil {
+		return nil, 0, err
+	}
+
+	events := make([]*Event, 0, len(gormModels))
+	for _, model := range gormModels {
+		events = append(events, model.toDomain())
+	}
+	return events, int(totalCount), nil
+}
+
+// --- Translators ---
+
+func toGORM(e *Event) *Model {
+	return &Model{
+		Provider:       e.Provider,
+		EventID:        e.EventID,
+		TrackingNumber: nullableString(e.TrackingNumber),
+		Payload:        string(e.Payload),
+		Status:         e.Status,
+		Attempts:       e.Attempts,
+		LastError:      nullableString(e.LastError),
+		ProcessedAt:    e.ProcessedAt,
+	}
+}
+
+func (m *Model) toDomain() *Event {
+	event := &Event{
+		ID:          m.ID,
+		Provider:    m.Provider,
+		EventID:     m.EventID,
+		Payload:     []byte(m.Payload),
+		Status:      m.Status,
+		Attempts:    m.Attempts,
+		ProcessedAt: m.ProcessedAt,
+		CreatedAt:   m.CreatedAt,
+		UpdatedAt:   m.UpdatedAt,
+	}
+	if m.TrackingNumber != nil {
+		event.TrackingNumber = *m.TrackingNumber
+	}
+	if m.LastError != nil {
+		event.LastError = *m.LastError
+	}
+	return event
+}
+
+func nullableString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
@@ -0,0 +1,238 @@
+package example_webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-template/internal/adapters/secondary/shipping"
+	"go-template/internal/modules/example/example_order"
+	"go-template/pkg/auth"
+	"go-template/pkg/custom_errors"
+	"go-template/pkg/logger"
+	"go-template/pkg/webhook"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// processingTimeout คือเวลาที่ event ค้างสถานะ processing ได้ก่อนถือว่าคนที่จองไว้ล่มไปแล้ว (ให้คำขอใหม่จองต่อได้)
+const processingTimeout = 5 * time.Minute
+
+// Service คือ "สัญญา" ที่ Handler จะเรียกใช้
+type Service interface {
+	// Receive ตรวจลายเซ็น บันทึก และประมวลผล webhook
+	// คืน duplicate = true เมื่อเป็นเหตุการณ์ที่เคยประมวลผลสำเร็จไปแล้ว (ไม่ทำซ้ำ)
+	// ถ้ามีคำขออื่นกำลังประมวลผลเหตุการณ์เดียวกันอยู่จะคืน 409 ให้ขนส่งส่งมาใหม่ภายหลัง
+	// ถ้าประมวลผลไม่สำเร็จเพราะข้อมูล (เช่น หา Order ไม่เจอ) จะคืน Event สถานะ failed โดยไม่มี error
+	// error จะมีเฉพาะกรณีที่ควรให้ขนส่งส่งมาใหม่ (ระบบเราขัดข้อง)
+	Receive(ctx context.Context, provider string, signature *Signature, payload []byte) (event *Event, duplicate bool, err error)
+	Replay(ctx context.Context, id uint) (*Event, error)
+	ListEvents(ctx context.Context, status string, limit, offset int) ([]*Event, int, error)
+}
+
+// service คือ struct ที่ทำงานจริง
+type service struct {
+	repo      Repository
+	providers ProviderLookup
+	updater   ShipmentUpdater
+	secrets   map[string]string // provider -> webhook secret
+	tolerance time.Duration
+	rbac      *auth.RBAC
+	log       logger.Logger
+}
+
+// NewExampleWebhookService คือโรงงานสร้าง Service
+// ขนส่งที่ไม่มี secret ใน secrets จะรับ webhook ไม่ได้ (ตอบ 404)
+func NewExampleWebhookService(repo Repository, providers ProviderLookup, updater ShipmentUpdater, secrets map[string]string, tolerance time.Duration, rbac *auth.RBAC, log logger.Logger) Service {
+	return &service{
+		repo:      repo,
+		providers: providers,
+		updater:   updater,
+		secrets:   secrets,
+		tolerance: tolerance,
+		rbac:      rbac,
+		log:       log,
+	}
+}
+
+// --- Implementation ---
+
+func (s *service) Receive(ctx context.Context, provider string, signature *Signature, payload []byte) (*Event, bool, error) {
+	// 1. ต้องเป็นขนส่งที่เปิดรับ webhook ไว้
+	secret := s.secrets[provider]
+	if secret == "" {
+		return nil, false, custom_errors.NotFoundError("ไม่รองรับ webhook ของขนส่งนี้")
+	}
+	parser, err := s.parserFor(provider)
+	if err != nil {
+		return nil, false, err
+	}
+
+	// 2. ตรวจลายเซ็นก่อนเชื่ออะไรใน payload ทั้งสิ้น
+	if err := webhook.Verify(secret, signature.Timestamp, signature.Value, payload, s.tolerance, time.Now()); err != nil {
+		s.log.WithContext(ctx).Warn("Rejected carrier webhook", "provider", provider, "reason", err.Error())
+		return nil, false, custom_errors.UnauthorizedError("ลายเซ็นของ webhook ไม่ถูกต้องหรือหมดอายุ")
+	}
+
+	// 3. แปลง payload
+	parsed, err := parser.ParseWebhook(payload)
+	if err != nil {
+		return nil, false, custom_errors.InvalidFormatError("payload ของ webhook ไม่ถูกต้อง", err.Error())
+	}
+
+	// 4. บันทึก payload ดิบพร้อม "จอง" ไปประมวลผลในคำสั่งเดียว (event id ซ้ำ = เคยได้รับแล้ว)
+	event := &Event{
+		Provider:       provider,
+		EventID:        parsed.EventID,
+		TrackingNumber: parsed.TrackingNumber,
+		Payload:        payload,
+		Status:         EventStatusProcessing,
+		Attempts:       1,
+	}
+	if err := s.repo.Create(ctx, event); err != nil {
+		if !errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, false, custom_errors.SystemErrorWithDetails("ไม่สามารถบันทึก webhook ได้", err.Error())
+		}
+
+		event, err = s.repo.GetByProviderEventID(ctx, provider, parsed.EventID)
+		if err != nil {
+			return nil, false, custom_errors.SystemErrorWithDetails("ไม่สามารถอ่าน webhook ที่บันทึกไว้ได้", err.Error())
+		}
+		if event.Status == EventStatusProcessed {
+			s.log.WithContext(ctx).Info("Duplicate carrier webhook ignored", "provider", provider, "event_id", parsed.EventID)
+			return event, true, nil
+		}
+
+		// เคยได้รับแต่ยังไม่สำเร็จ -> ถือว่าขนส่งส่งมาใหม่ ต้องจองให้ได้ก่อนถึงจะประมวลผลอีกรอบ
+		claimed, err := s.repo.Claim(ctx, event, time.Now().Add(-processingTimeout))
+		if err != nil {
+			return nil, false, custom_errors.SystemErrorWithDetails("ไม่สามารถจอง webhook เพื่อประมวลผลได้", err.Error())
+		}
+		if !claimed {
+			// มีคำขออื่นจองไปก่อน: ถ้าเขาเพิ่งทำสำเร็จก็ถือว่าซ้ำ ไม่งั้นให้ขนส่งส่งมาใหม่ภายหลัง
+			current, err := s.repo.GetByProviderEventID(ctx, provider, parsed.EventID)
+			if err != nil {
+				return nil, false, custom_errors.SystemErrorWithDetails("ไม่สามารถอ่าน webhook ที่บันทึกไว้ได้", err.Error())
+			}
+			if current.Status == EventStatusProcessed {
+				s.log.WithContext(ctx).Info("Duplicate carrier webhook ignored", "provider", provider, "event_id", parsed.EventID)
+				return current, true, nil
+			}
+			return nil, false, custom_errors.ConflictError("webhook นี้กำลังถูกประมวลผลอยู่ กรุณาส่งใหม่ภายหลัง", nil)
+		}
+	}
+
+	// 5. ประมวลผล
+	if err := s.process(ctx, event, parsed); err != nil {
+		return event, false, err
+	}
+	return event, false, nil
+}
+
+// Replay ประมวลผล webhook ที่บันทึกไว้ใหม่อีกครั้ง (ใช้ payload ดิบเดิม ลายเซ็นถูกตรวจไปแล้วตอนรับ)
+// replay ได้เฉพาะ event ที่ยังไม่สำเร็จ หรือค้าง processing นานเกิน processingTimeout
+func (s *service) Replay(ctx context.Context, id uint) (*Event, error) {
+	if err := s.rbac.RequirePermission(ctx, PermissionWebhooksReplay); err != nil {
+		return nil, err
+	}
+
+	event, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, custom_errors.NotFoundError(fmt.Sprintf("ไม่พบ webhook ID: %d", id))
+		}
+		return nil, custom_errors.SystemErrorWithDetails("เกิดข้อผิดพลาดในการค้นหา webhook", err.Error())
+	}
+
+	parser, err := s.parserFor(event.Provider)
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := parser.ParseWebhook(event.Payload)
+	if err != nil {
+		return nil, custom_errors.InvalidFormatError("payload ของ webhook ที่บันทึกไว้ไม่ถูกต้อง", err.Error())
+	}
+
+	// จองก่อนประมวลผล (กันไม่ให้ชนกับ webhook ที่ขนส่งส่งมาพร้อมกัน หรือ replay ซ้ำจากอีกคน)
+	claimed, err := s.repo.Claim(ctx, event, time.Now().Add(-processingTimeout))
+	if err != nil {
+		return nil, custom_errors.SystemErrorWithDetails("ไม่สามารถจอง webhook เพื่อประมวลผลได้", err.Error())
+	}
+	if !claimed {
+		if event.Status == EventStatusProcessed {
+			return nil, custom_errors.ConflictError("webhook นี้ประมวลผลสำเร็จไปแล้ว", nil)
+		}
+		return nil, custom_errors.ConflictError("webhook นี้กำลังถูกประมวลผลอยู่", nil)
+	}
+
+	if err := s.process(ctx, event, parsed); err != nil {
+		return nil, err
+	}
+	s.log.WithContext(ctx).Info("Carrier webhook replayed", "id", event.ID, "provider", event.Provider, "event_id", event.EventID, "status", event.Status)
+	return event, nil
+}
+
+func (s *service) ListEvents(ctx context.Context, status string, limit, offset int) ([]*Event, int, error) {
+	if err := s.rbac.RequirePermission(ctx, PermissionWebhooksRead); err != nil {
+		return nil, 0, err
+	}
+
+	events, totalCount, err := s.repo.List(ctx, status, limit, offset)
+	if err != nil {
+		return nil, 0, custom_errors.SystemErrorWithDetails("เกิดข้อผิดพลาดในการดึงรายการ webhook", err.Error())
+	}
+	return events, totalCount, nil
+}
+
+// --- Private Helpers ---
+
+// process ส่งสถานะพัสดุต่อให้โมดูล Order แล้วบันทึกผลลงใน event
+// คืน error เฉพาะเมื่อระบบเราขัดข้อง (5xx) ความผิดพลาดจากข้อมูลจะถูกบันทึกเป็น failed เฉยๆ
+func (s *service) process(ctx context.Context, event *Event, parsed *shipping.WebhookEvent) error {
+	processErr := s.updater.ApplyShipmentUpdate(ctx, &example_order.ShipmentUpdate{
+		Provider:       event.Provider,
+		TrackingNumber: parsed.TrackingNumber,
+		Status:         parsed.Status,
+		OccurredAt:     parsed.OccurredAt,
+		Reference:      fmt.Sprintf("%s webhook %s", event.Provider, event.EventID),
+	})
+
+	now := time.Now()
+	if processErr == nil {
+		event.Status = EventStatusProcessed
+		event.LastError = ""
+		event.ProcessedAt = &now
+	} else {
+		event.Status = EventStatusFailed
+		event.LastError = processErr.Error()
+		s.log.WithContext(ctx).Warn("Carrier webhook processing failed", "provider", event.Provider, "event_id", event.EventID, "error", processErr.Error())
+	}
+
+	if err := s.repo.MarkResult(ctx, event); err != nil {
+		if errors.Is(err, ErrEventNotClaimed) {
+			s.log.WithContext(ctx).Warn("Carrier webhook was claimed by another request before its result was saved", "provider", event.Provider, "event_id", event.EventID)
+		}
+		return custom_errors.SystemErrorWithDetails("ไม่สามารถบันทึกผลการประมวลผล webhook ได้", err.Error())
+	}
+
+	if appErr, ok := processErr.(*custom_errors.AppError); ok && appErr.HTTPStatus < 500 {
+		return nil
+	}
+	if processErr != nil {
+		return custom_errors.SystemErrorWithDetails("ประมวลผล webhook ไม่สำเร็จ กรุณาส่งใหม่", processErr.Error())
+	}
+	return nil
+}
+
+// parserFor หาขนส่งที่รองรับ webhook ตามชื่อ
+func (s *service) parserFor(provider string) (shipping.WebhookParser, error) {
+	p, err := s.providers.Get(provider)
+	if err != nil {
+		return nil, custom_errors.NotFoundError("ไม่รองรับ webhook ของขนส่งนี้")
+	}
+	parser, ok := p.(shipping.WebhookParser)
+	if !ok {
+		return nil, custom_errors.NotFoundError("ไม่รองรับ webhook ของขนส่งนี้")
+	}
+	return parser, nil
+}
@@ -0,0 +1,25 @@
+package auth
+
+import "context"
+
+// claimsContextKey คือ key แบบ private สำหรับเก็บ JWTClaims ไว้ใน context
+// (ใช้ struct type เพื่อไม่ให้ชนกับ key ของ package อื่น)
+type claimsContextKey struct{}
+
+// ClaimsContextKey คือ key ที่ Middleware ใช้ฝาก JWTClaims ไว้กับ request
+// (fiber.Ctx เป็น context.Context และ Value() จะอ่านจาก Locals ให้เรา)
+var ClaimsContextKey = claimsContextKey{}
+
+// WithClaims คืน context ใหม่ที่แนบ JWTClaims ไว้
+func WithClaims(ctx context.Context, claims *JWTClaims) context.Context {
+	return context.WithValue(ctx, ClaimsContextKey, claims)
+}
+
+// ClaimsFromContext ดึง JWTClaims ออกจาก context (ถ้ามี)
+func ClaimsFromContext(ctx context.Context) (*JWTClaims, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	claims, ok := ctx.Value(ClaimsContextKey).(*JWTClaims)
+	return claims, ok && claims != nil
+}
@@ -4,6 +4,7 @@ import (
 	"crypto/rand"
 	"encoding/base64"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -18,16 +19,80 @@ type JWTClaims struct {
 	jwt.RegisteredClaims
 }
 
+// DefaultAccessTokenTTL คืออายุของ Access Token เมื่อไม่ได้ตั้งค่าไว้ใน config
+// (ตั้งให้สั้นไว้ เพราะ Access Token ถูก revoke ไม่ได้ ต้องใช้ Refresh Token ขอใหม่แทน)
+const DefaultAccessTokenTTL = 15 * time.Minute
+
 // AuthService handles authentication operations
+// ทำงานได้ 2 โหมด:
+//   - HS256: เซ็นและตรวจด้วย JWTSecret ดอกเดียว (ค่าเริ่มต้น)
+//   - Asymmetric (RS256/EdDSA): เซ็นด้วย private key ของ ActiveKeyID และตรวจด้วย public key ตาม kid
+//     ทำให้ service อื่นตรวจ Token ได้จาก JWKS โดยไม่ต้องถือความลับของเรา
 type AuthService struct {
-	secretKey []byte
+	secretKey      []byte
+	accessTokenTTL time.Duration
+
+	activeKey *signingKey            // nil = โหมด HS256
+	keys      map[string]*signingKey // kid -> key (ใช้ตรวจ)
+}
+
+// Options คือค่าที่ AuthService ต้องใช้ (main แปลงมาจาก config.AuthConfig ให้ pkg/auth ไม่ต้องรู้จัก config)
+type Options struct {
+	JWTSecret      string
+	AccessTokenTTL time.Duration // <= 0 = DefaultAccessTokenTTL
+
+	// ActiveKeyID คือ kid ของกุญแจที่ใช้ "เซ็น" Token ใหม่ (ว่าง = HS256 กับ JWTSecret)
+	ActiveKeyID string
+	// SigningKeys คือกุญแจทั้งหมดที่ยังใช้ "ตรวจ" Token ได้
+	SigningKeys []SigningKeyOptions
 }
 
-// NewAuthService creates a new auth service
-func NewAuthService(secretKey string) *AuthService {
-	return &AuthService{
-		secretKey: []byte(secretKey),
+// NewAuthService creates a new auth service from the auth options
+func NewAuthService(cfg Options) (*AuthService, error) {
+	accessTokenTTL := cfg.AccessTokenTTL
+	if accessTokenTTL <= 0 {
+		accessTokenTTL = DefaultAccessTokenTTL
+	}
+
+	a := &AuthService{
+		secretKey:      []byte(cfg.JWTSecret),
+		accessTokenTTL: accessTokenTTL,
+		keys:           make(map[string]*signingKey, len(cfg.SigningKeys)),
+	}
+
+	for _, keyCfg := range cfg.SigningKeys {
+		key, err := loadSigningKey(keyCfg)
+		if err != nil {
+			return nil, err
+		}
+		if _, exists := a.keys[key.kid]; exists {
+			return nil, fmt.Errorf("duplicate signing key kid %q", key.kid)
+		}
+		a.keys[key.kid] = key
 	}
+
+	if cfg.ActiveKeyID == "" {
+		if len(a.secretKey) == 0 {
+			return nil, fmt.Errorf("auth.jwtSecret is required when auth.activeKeyId is not set")
+		}
+		return a, nil
+	}
+
+	activeKey, ok := a.keys[cfg.ActiveKeyID]
+	if !ok {
+		return nil, fmt.Errorf("active signing key %q is not configured in auth.signingKeys", cfg.ActiveKeyID)
+	}
+	if activeKey.privateKey == nil {
+		return nil, fmt.Errorf("active signing key %q has no privateKeyFile", cfg.ActiveKeyID)
+	}
+	a.activeKey = activeKey
+
+	return a, nil
+}
+
+// AccessTokenTTL returns the lifetime of access tokens issued by this service
+func (a *AuthService) AccessTokenTTL() time.Duration {
+	return a.accessTokenTTL
 }
 
 // HashPassword hashes a password using bcrypt
@@ -44,29 +109,33 @@ func (a *AuthService) CheckPassword(password, hash string) bool {
 
 // GenerateToken generates a JWT token
 func (a *AuthService) GenerateToken(userID uint, email, role string) (string, error) {
+	now := time.Now()
 	claims := JWTClaims{
 		UserID: userID,
 		Email:  email,
 		Role:   role,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
+			ID:        GenerateRandomKey(),
+			ExpiresAt: jwt.NewNumericDate(now.Add(a.accessTokenTTL)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(a.secretKey)
+	if a.activeKey == nil {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		return token.SignedString(a.secretKey)
+	}
+
+	// ⭐️ ใส่ kid ไว้ใน header ให้ฝั่งตรวจรู้ว่าต้องหยิบ public key ดอกไหนมาใช้
+	token := jwt.NewWithClaims(a.activeKey.method, claims)
+	token.Header["kid"] = a.activeKey.kid
+	return token.SignedString(a.activeKey.privateKey)
 }
 
 // ValidateToken validates a JWT token
 func (a *AuthService) ValidateToken(tokenString string) (*JWTClaims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return a.secretKey, nil
-	})
+	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, a.keyFunc)
 
 	if err != nil {
 		return nil, err
@@ -79,6 +148,54 @@ func (a *AuthService) ValidateToken(tokenString string) (*JWTClaims, error) {
 	return nil, fmt.Errorf("invalid token")
 }
 
+// keyFunc เลือกกุญแจที่ใช้ตรวจ Token ตามโหมดและ kid
+// และป้องกันการสลับ algorithm (เช่น ส่ง HS256 มาหลอกให้ใช้ public key เป็น secret)
+func (a *AuthService) keyFunc(token *jwt.Token) (interface{}, error) {
+	if a.activeKey == nil {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return a.secretKey, nil
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		return nil, fmt.Errorf("token is missing kid header")
+	}
+	key, ok := a.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key: %s", kid)
+	}
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method %v for key %s", token.Header["alg"], kid)
+	}
+	return key.publicKey, nil
+}
+
+// JWKS returns the public verification keys in JSON Web Key Set format.
+// ในโหมด HS256 จะคืนชุดว่าง (เราไม่เปิดเผย shared secret)
+func (a *AuthService) JWKS() (*JWKSet, error) {
+	set := &JWKSet{Keys: make([]JWK, 0, len(a.keys))}
+	if a.activeKey == nil {
+		return set, nil
+	}
+
+	kids := make([]string, 0, len(a.keys))
+	for kid := range a.keys {
+		kids = append(kids, kid)
+	}
+	sort.Strings(kids)
+
+	for _, kid := range kids {
+		jwk, err := a.keys[kid].toJWK()
+		if err != nil {
+			return nil, err
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set, nil
+}
+
 // GenerateRandomKey generates a random key for testing or initial setup
 func GenerateRandomKey() string {
 	bytes := make([]byte, 32)
@@ -0,0 +1,186 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Algorithms ที่รองรับ
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+// signingKey คือกุญแจ 1 ดอกที่ถูกอ้างถึงด้วย kid
+// ถ้ามี privateKey แปลว่าใช้ "เซ็น" ได้ ถ้ามีแค่ publicKey ก็ใช้ "ตรวจ" ได้อย่างเดียว
+type signingKey struct {
+	kid        string
+	method     jwt.SigningMethod
+	privateKey crypto.PrivateKey
+	publicKey  crypto.PublicKey
+}
+
+// SigningKeyOptions คือกุญแจ asymmetric 1 ดอก (RS256 หรือ EdDSA) ที่อ่านจากไฟล์ PEM
+type SigningKeyOptions struct {
+	KeyID          string
+	Algorithm      string // RS256 | EdDSA
+	PrivateKeyFile string // ไม่ต้องใส่ ถ้าเป็นกุญแจที่ใช้ตรวจอย่างเดียว
+	PublicKeyFile  string // ถ้าใส่คู่กับ PrivateKeyFile ต้องเป็นคู่เดียวกัน
+}
+
+// loadSigningKey อ่านกุญแจจากไฟล์ PEM ตามที่ตั้งค่าไว้
+func loadSigningKey(cfg SigningKeyOptions) (*signingKey, error) {
+	if cfg.KeyID == "" {
+		return nil, fmt.Errorf("signing key is missing kid")
+	}
+	if cfg.PrivateKeyFile == "" && cfg.PublicKeyFile == "" {
+		return nil, fmt.Errorf("signing key %q must have privateKeyFile or publicKeyFile", cfg.KeyID)
+	}
+
+	key := &signingKey{kid: cfg.KeyID}
+
+	switch normalizeAlgorithm(cfg.Algorithm) {
+	case AlgorithmRS256:
+		key.method = jwt.SigningMethodRS256
+		if cfg.PrivateKeyFile != "" {
+			pemBytes, err := os.ReadFile(cfg.PrivateKeyFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read private key %q: %w", cfg.KeyID, err)
+			}
+			privateKey, err := jwt.ParseRSAPrivateKeyFromPEM(pemBytes)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse RSA private key %q: %w", cfg.KeyID, err)
+			}
+			key.privateKey = privateKey
+			key.publicKey = &privateKey.PublicKey
+		}
+		if cfg.PublicKeyFile != "" {
+			pemBytes, err := os.ReadFile(cfg.PublicKeyFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read public key %q: %w", cfg.KeyID, err)
+			}
+			publicKey, err := jwt.ParseRSAPublicKeyFromPEM(pemBytes)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse RSA public key %q: %w", cfg.KeyID, err)
+			}
+			if err := key.setPublicKey(publicKey); err != nil {
+				return nil, err
+			}
+		}
+
+	case AlgorithmEdDSA:
+		key.method = jwt.SigningMethodEdDSA
+		if cfg.PrivateKeyFile != "" {
+			pemBytes, err := os.ReadFile(cfg.PrivateKeyFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read private key %q: %w", cfg.KeyID, err)
+			}
+			privateKey, err := jwt.ParseEdPrivateKeyFromPEM(pemBytes)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse Ed25519 private key %q: %w", cfg.KeyID, err)
+			}
+			key.privateKey = privateKey
+			key.publicKey = privateKey.(ed25519.PrivateKey).Public()
+		}
+		if cfg.PublicKeyFile != "" {
+			pemBytes, err := os.ReadFile(cfg.PublicKeyFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read public key %q: %w", cfg.KeyID, err)
+			}
+			publicKey, err := jwt.ParseEdPublicKeyFromPEM(pemBytes)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse Ed25519 public key %q: %w", cfg.KeyID, err)
+			}
+			if err := key.setPublicKey(publicKey); err != nil {
+				return nil, err
+			}
+		}
+
+	default:
+		return nil, fmt.Errorf("signing key %q has unsupported algorithm %q (must be %s or %s)",
+			cfg.KeyID, cfg.Algorithm, AlgorithmRS256, AlgorithmEdDSA)
+	}
+
+	return key, nil
+}
+
+// setPublicKey ใช้ public key จากไฟล์ ถ้าอ่าน private key มาแล้วต้องเป็นคู่เดียวกัน
+// ไม่อย่างนั้น Token ที่เราเซ็นจะตรวจไม่ผ่าน (และ JWKS จะแจก public key ผิดดอก)
+func (k *signingKey) setPublicKey(publicKey crypto.PublicKey) error {
+	if k.publicKey != nil {
+		derived, ok := k.publicKey.(interface{ Equal(crypto.PublicKey) bool })
+		if !ok || !derived.Equal(publicKey) {
+			return fmt.Errorf("public key of signing key %q does not match its private key", k.kid)
+		}
+	}
+	k.publicKey = publicKey
+	return nil
+}
+
+// ====================================================================================
+// JWKS (JSON Web Key Set)
+// ====================================================================================
+
+// JWK คือ public key 1 ดอกในรูปแบบ RFC 7517
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+
+	// Ed25519 (OKP)
+	Curve string `json:"crv,omitempty"`
+	X     string `json:"x,omitempty"`
+}
+
+// JWKSet คือชุดของ public keys ที่ service อื่นใช้ตรวจ Token ของเรา
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// toJWK แปลง public key ของเราเป็น JWK
+func (k *signingKey) toJWK() (JWK, error) {
+	jwk := JWK{KeyID: k.kid, Use: "sig", Algorithm: k.method.Alg()}
+
+	switch publicKey := k.publicKey.(type) {
+	case *rsa.PublicKey:
+		jwk.KeyType = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes())
+	case ed25519.PublicKey:
+		jwk.KeyType = "OKP"
+		jwk.Curve = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(publicKey)
+	default:
+		return JWK{}, fmt.Errorf("unsupported public key type for kid %q", k.kid)
+	}
+
+	return jwk, nil
+}
+
+// normalizeAlgorithm ทำให้ชื่อ algorithm ใน config ไม่สนตัวพิมพ์เล็ก/ใหญ่
+func normalizeAlgorithm(alg string) string {
+	switch strings.ToUpper(alg) {
+	case "", strings.ToUpper(AlgorithmHS256):
+		return AlgorithmHS256
+	case strings.ToUpper(AlgorithmRS256):
+		return AlgorithmRS256
+	case strings.ToUpper(AlgorithmEdDSA), "ED25519":
+		return AlgorithmEdDSA
+	default:
+		return alg
+	}
+}
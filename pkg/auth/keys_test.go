@@ -0,0 +1,113 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeKeyPair เขียน private/public key เป็นไฟล์ PEM ใน dir แล้วคืน path ทั้งสองไฟล์
+func writeKeyPair(t *testing.T, dir, name string, privateKey crypto.Signer) (string, string) {
+	t.Helper()
+	privateDER, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(privateKey.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	privatePath := filepath.Join(dir, name+".key")
+	publicPath := filepath.Join(dir, name+".pub")
+	if err := os.WriteFile(privatePath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(publicPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return privatePath, publicPath
+}
+
+func TestLoadSigningKeyPublicKeyMustMatch(t *testing.T) {
+	dir := t.TempDir()
+
+	newRSA := func() crypto.Signer {
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return key
+	}
+	newEd25519 := func() crypto.Signer {
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return key
+	}
+
+	rsaPrivate, rsaPublic := writeKeyPair(t, dir, "rsa", newRSA())
+	_, rsaOtherPublic := writeKeyPair(t, dir, "rsa-other", newRSA())
+	edPrivate, edPublic := writeKeyPair(t, dir, "ed", newEd25519())
+	_, edOtherPublic := writeKeyPair(t, dir, "ed-other", newEd25519())
+
+	tests := []struct {
+		name    string
+		opts    SigningKeyOptions
+		wantErr string
+	}{
+		{name: "RSA matching pair", opts: SigningKeyOptions{KeyID: "k", Algorithm: AlgorithmRS256, PrivateKeyFile: rsaPrivate, PublicKeyFile: rsaPublic}},
+		{name: "RSA private only", opts: SigningKeyOptions{KeyID: "k", Algorithm: AlgorithmRS256, PrivateKeyFile: rsaPrivate}},
+		{name: "RSA public only", opts: SigningKeyOptions{KeyID: "k", Algorithm: AlgorithmRS256, PublicKeyFile: rsaOtherPublic}},
+		{name: "RSA mismatched pair", opts: SigningKeyOptions{KeyID: "k", Algorithm: AlgorithmRS256, PrivateKeyFile: rsaPrivate, PublicKeyFile: rsaOtherPublic}, wantErr: "does not match"},
+		{name: "EdDSA matching pair", opts: SigningKeyOptions{KeyID: "k", Algorithm: AlgorithmEdDSA, PrivateKeyFile: edPrivate, PublicKeyFile: edPublic}},
+		{name: "EdDSA mismatched pair", opts: SigningKeyOptions{KeyID: "k", Algorithm: AlgorithmEdDSA, PrivateKeyFile: edPrivate, PublicKeyFile: edOtherPublic}, wantErr: "does not match"},
+		{name: "public key of another algorithm", opts: SigningKeyOptions{KeyID: "k", Algorithm: AlgorithmEdDSA, PrivateKeyFile: edPrivate, PublicKeyFile: rsaPublic}, wantErr: "failed to parse"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := loadSigningKey(tt.opts)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("loadSigningKey() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("loadSigningKey() error = %v", err)
+			}
+			if key.publicKey == nil {
+				t.Error("loadSigningKey() returned a key without a public key")
+			}
+		})
+	}
+}
+
+func TestNewAuthServiceFailsOnMismatchedKeyPair(t *testing.T) {
+	dir := t.TempDir()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	privatePath, _ := writeKeyPair(t, dir, "active", key)
+	_, otherPublic := writeKeyPair(t, dir, "other", other)
+
+	_, err = NewAuthService(Options{
+		ActiveKeyID: "active",
+		SigningKeys: []SigningKeyOptions{{KeyID: "active", Algorithm: AlgorithmRS256, PrivateKeyFile: privatePath, PublicKeyFile: otherPublic}},
+	})
+	if err == nil {
+		t.Fatal("NewAuthService() should refuse a public key that does not belong to the private key")
+	}
+}
@@ -0,0 +1,97 @@
+package auth
+
+import (
+	"context"
+	"strings"
+
+	"go-template/pkg/custom_errors"
+)
+
+// Roles ที่ระบบรู้จัก (ต้องตรงกับ CHECK constraint ของคอลัมน์ role ในตาราง example_users)
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+// PermissionAll คือ wildcard ที่ให้สิทธิ์ทุกอย่าง
+const PermissionAll = "*"
+
+// RBAC คือ "ตารางสิทธิ์" (Permission Matrix) ที่บอกว่า Role ไหนทำอะไรได้บ้าง
+// ปกติจะโหลดมาจาก config (auth.permissions)
+type RBAC struct {
+	permissions map[string]map[string]bool
+}
+
+// NewRBAC สร้าง RBAC จาก matrix รูปแบบ role -> []permission
+//
+//	admin: ["*"]
+//	user:  ["users:read", "orders:*"]
+func NewRBAC(matrix map[string][]string) *RBAC {
+	permissions := make(map[string]map[string]bool, len(matrix))
+	for role, perms := range matrix {
+		set := make(map[string]bool, len(perms))
+		for _, p := range perms {
+			set[strings.TrimSpace(p)] = true
+		}
+		permissions[strings.ToLower(role)] = set
+	}
+	return &RBAC{permissions: permissions}
+}
+
+// Can ตรวจว่า role นี้มี permission ที่ขอหรือไม่
+// รองรับ wildcard ทั้ง "*" (ทุกอย่าง) และ "resource:*" (ทุก action ของ resource นั้น)
+func (r *RBAC) Can(role, permission string) bool {
+	set, ok := r.permissions[strings.ToLower(role)]
+	if !ok {
+		return false
+	}
+	if set[PermissionAll] || set[permission] {
+		return true
+	}
+	if resource, _, found := strings.Cut(permission, ":"); found {
+		return set[resource+":*"]
+	}
+	return false
+}
+
+// RequirePermission ตรวจว่าผู้ใช้ใน ctx มี "ทุก" permission ที่ระบุ
+// ใช้ได้ทั้งใน Middleware และใน Service method
+func (r *RBAC) RequirePermission(ctx context.Context, permissions ...string) error {
+	claims, ok := ClaimsFromContext(ctx)
+	if !ok {
+		return custom_errors.UnauthorizedError("กรุณาเข้าสู่ระบบก่อนใช้งาน")
+	}
+	for _, p := range permissions {
+		if !r.Can(claims.Role, p) {
+			return custom_errors.PermissionDeniedError("คุณไม่มีสิทธิ์ดำเนินการนี้")
+		}
+	}
+	return nil
+}
+
+// RequireSelfOrPermission อนุญาตถ้าผู้ใช้เป็น "เจ้าของ" ข้อมูล (ownerID)
+// หรือมี permission ที่ระบุ (เช่น admin ดูข้อมูลของคนอื่นได้)
+func (r *RBAC) RequireSelfOrPermission(ctx context.Context, ownerID uint, permissions ...string) error {
+	claims, ok := ClaimsFromContext(ctx)
+	if !ok {
+		return custom_errors.UnauthorizedError("กรุณาเข้าสู่ระบบก่อนใช้งาน")
+	}
+	if claims.UserID == ownerID {
+		return nil
+	}
+	return r.RequirePermission(ctx, permissions...)
+}
+
+// RequireRole ตรวจว่าผู้ใช้ใน ctx มี role ใด role หนึ่งที่ระบุ
+func RequireRole(ctx context.Context, roles ...string) error {
+	claims, ok := ClaimsFromContext(ctx)
+	if !ok {
+		return custom_errors.UnauthorizedError("กรุณาเข้าสู่ระบบก่อนใช้งาน")
+	}
+	for _, role := range roles {
+		if strings.EqualFold(claims.Role, role) {
+			return nil
+		}
+	}
+	return custom_errors.PermissionDeniedError("คุณไม่มีสิทธิ์เข้าถึงส่วนนี้")
+}
@@ -0,0 +1,30 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// DefaultRefreshTokenTTL คืออายุของ Refresh Token เมื่อไม่ได้ตั้งค่าไว้ใน config
+const DefaultRefreshTokenTTL = 30 * 24 * time.Hour
+
+// GenerateOpaqueToken สร้าง Refresh Token แบบ "ทึบ" (ไม่มีข้อมูลข้างใน เป็นแค่ random bytes)
+// ตัว token ดิบจะถูกส่งให้ Client เท่านั้น ส่วนใน DB เราเก็บแค่ hash (ดู HashOpaqueToken)
+func GenerateOpaqueToken() (string, error) {
+	bytes := make([]byte, 32)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", fmt.Errorf("failed to generate opaque token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(bytes), nil
+}
+
+// HashOpaqueToken คืนค่า SHA-256 (hex) ของ token
+// ถ้า DB หลุดออกไป คนร้ายก็เอา hash ไปใช้แทน token จริงไม่ได้
+func HashOpaqueToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
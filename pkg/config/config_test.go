@@ -0,0 +1,49 @@
+package config
+
+import (
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestPostgresConfigBuildDSN(t *testing.T) {
+	cfg := PostgresConfig{
+		Host:            "db.internal",
+		Port:            "5432",
+		User:            "app@prod",
+		Password:        "p@ss:w/rd?#%",
+		DBName:          "orders",
+		SSLMode:         "require",
+		ConnectTimeout:  1500 * time.Millisecond,
+		ApplicationName: "go template",
+	}
+
+	u, err := url.Parse(cfg.BuildDSN())
+	if err != nil {
+		t.Fatalf("BuildDSN() is not a valid URL: %v", err)
+	}
+	password, _ := u.User.Password()
+	if u.User.Username() != cfg.User || password != cfg.Password {
+		t.Errorf("credentials = %q / %q, want %q / %q", u.User.Username(), password, cfg.User, cfg.Password)
+	}
+	if u.Host != "db.internal:5432" || u.Path != "/orders" {
+		t.Errorf("host/path = %q %q", u.Host, u.Path)
+	}
+	query := u.Query()
+	if query.Get("sslmode") != "require" || query.Get("connect_timeout") != "2" || query.Get("application_name") != "go template" {
+		t.Errorf("query = %v", query)
+	}
+
+	app, err := url.Parse(cfg.BuildAppDSN())
+	if err != nil {
+		t.Fatalf("BuildAppDSN() is not a valid URL: %v", err)
+	}
+	if got := app.Query().Get("statement_timeout"); got != "" {
+		t.Errorf("statement_timeout = %q, want none when not configured", got)
+	}
+	cfg.StatementTimeout = 30 * time.Second
+	app, _ = url.Parse(cfg.BuildAppDSN())
+	if got := app.Query().Get("statement_timeout"); got != "30000" {
+		t.Errorf("statement_timeout = %q, want 30000", got)
+	}
+}
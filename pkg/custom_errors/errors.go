@@ -20,6 +20,10 @@ const (
 	// Resource
 	ErrNotFound      = "NOT_FOUND"
 	ErrAlreadyExists = "ALREADY_EXISTS"
+	ErrConflict      = "CONFLICT"
+
+	// Business Rules
+	ErrInvalidStateTransition = "INVALID_STATE_TRANSITION"
 
 	// System
 	ErrSystem      = "SYSTEM_ERROR"
@@ -66,6 +70,14 @@ func UnauthorizedError(message string) *AppError {
 	return New(fiber.StatusUnauthorized, ErrUnauthorized, message) // 401
 }
 
+func InvalidTokenError(message string) *AppError {
+	return New(fiber.StatusUnauthorized, ErrInvalidToken, message) // 401
+}
+
+func TokenExpiredError(message string) *AppError {
+	return New(fiber.StatusUnauthorized, ErrTokenExpired, message) // 401
+}
+
 func PermissionDeniedError(message string) *AppError {
 	return New(fiber.StatusForbidden, ErrPermissionDenied, message) // 403
 }
@@ -91,6 +103,18 @@ func AlreadyExistsError(message string, details interface{}) *AppError {
 	return NewWithDetails(fiber.StatusConflict, ErrAlreadyExists, message, details) // 409
 }
 
+// ConflictError is for requests that clash with the current state of a resource.
+func ConflictError(message string, details interface{}) *AppError {
+	return NewWithDetails(fiber.StatusConflict, ErrConflict, message, details) // 409
+}
+
+// --- Business Rule Errors ---
+
+// InvalidStateTransitionError is for moving a resource into a state its state machine does not allow.
+func InvalidStateTransitionError(message string, details interface{}) *AppError {
+	return NewWithDetails(fiber.StatusUnprocessableEntity, ErrInvalidStateTransition, message, details) // 422
+}
+
 // --- System Errors ---
 
 // SystemError is for generic internal errors with a user-friendly message.
@@ -0,0 +1,96 @@
+package httpclient
+
+import (
+	"sync"
+	"time"
+)
+
+// สถานะของ Circuit Breaker
+const (
+	stateClosed   = "closed"    // ปกติ: ปล่อยทุก request ผ่าน
+	stateOpen     = "open"      // ตัดวงจร: ปฏิเสธทันทีโดยไม่ยิงออกไป
+	stateHalfOpen = "half_open" // ทดลอง: ปล่อยผ่านทีละ 1 request เพื่อดูว่าปลายทางหายดีหรือยัง
+)
+
+// circuitBreaker คือ "เบรกเกอร์" ของปลายทาง 1 host
+// ล้มติดกันครบ threshold ครั้ง -> ตัดวงจรไป openTimeout -> ลองใหม่ 1 ครั้ง -> สำเร็จก็กลับมาปกติ
+type circuitBreaker struct {
+	mu               sync.Mutex
+	state            string
+	failures         int
+	openedAt         time.Time
+	trialInFlight    bool
+	failureThreshold int
+	openTimeout      time.Duration
+	now              func() time.Time
+}
+
+func newCircuitBreaker(failureThreshold int, openTimeout time.Duration) *circuitBreaker {
+	return &circuitBreaker{
+		state:            stateClosed,
+		failureThreshold: failureThreshold,
+		openTimeout:      openTimeout,
+		now:              time.Now,
+	}
+}
+
+// Allow ตอบว่า request นี้ยิงออกไปได้หรือไม่
+func (b *circuitBreaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case stateOpen:
+		if b.now().Sub(b.openedAt) < b.openTimeout {
+			return false
+		}
+		b.state = stateHalfOpen
+		b.trialInFlight = true
+		return true
+	case stateHalfOpen:
+		if b.trialInFlight {
+			return false
+		}
+		b.trialInFlight = true
+		return true
+	default:
+		return true
+	}
+}
+
+// Success บันทึกว่าปลายทางตอบกลับปกติ
+func (b *circuitBreaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.state = stateClosed
+	b.failures = 0
+	b.trialInFlight = false
+}
+
+// Failure บันทึกว่าปลายทางล่ม (network error, 5xx, 429)
+func (b *circuitBreaker) Failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.trialInFlight = false
+	if b.state == stateHalfOpen || b.failures >= b.failureThreshold {
+		b.state = stateOpen
+		b.openedAt = b.now()
+	}
+}
+
+// Abort คืนสิทธิ์ทดลองของ half-open เมื่อ request ถูกยกเลิกเอง (เช่น ctx ถูก cancel) โดยไม่นับว่าสำเร็จหรือล้ม
+func (b *circuitBreaker) Abort() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.trialInFlight = false
+}
+
+// State คืนสถานะปัจจุบัน (ใช้ตอน log/debug)
+func (b *circuitBreaker) State() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
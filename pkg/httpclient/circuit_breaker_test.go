@@ -0,0 +1,120 @@
+package httpclient
+
+import (
+	"testing"
+	"time"
+)
+
+// fakeClock คือนาฬิกาที่เดินเมื่อเราสั่งเท่านั้น
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+func newTestBreaker(threshold int, openTimeout time.Duration) (*circuitBreaker, *fakeClock) {
+	clock := &fakeClock{now: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+	breaker := newCircuitBreaker(threshold, openTimeout)
+	breaker.now = clock.Now
+	return breaker, clock
+}
+
+func TestCircuitBreakerOpensAfterThreshold(t *testing.T) {
+	breaker, _ := newTestBreaker(3, time.Minute)
+
+	for i := 0; i < 2; i++ {
+		if !breaker.Allow() {
+			t.Fatalf("attempt %d: closed breaker should allow", i+1)
+		}
+		breaker.Failure()
+	}
+	if got := breaker.State(); got != stateClosed {
+		t.Fatalf("state after 2 failures = %q, want %q", got, stateClosed)
+	}
+
+	breaker.Failure()
+	if got := breaker.State(); got != stateOpen {
+		t.Fatalf("state after 3 failures = %q, want %q", got, stateOpen)
+	}
+	if breaker.Allow() {
+		t.Fatal("open breaker should reject")
+	}
+}
+
+func TestCircuitBreakerSuccessResetsFailures(t *testing.T) {
+	breaker, _ := newTestBreaker(3, time.Minute)
+
+	breaker.Failure()
+	breaker.Failure()
+	breaker.Success()
+	breaker.Failure()
+	breaker.Failure()
+	if got := breaker.State(); got != stateClosed {
+		t.Fatalf("state = %q, want %q (failures must be consecutive)", got, stateClosed)
+	}
+}
+
+func TestCircuitBreakerHalfOpen(t *testing.T) {
+	tests := []struct {
+		name      string
+		result    func(*circuitBreaker)
+		wantState string
+		wantAllow bool
+	}{
+		{name: "trial succeeds closes the breaker", result: (*circuitBreaker).Success, wantState: stateClosed, wantAllow: true},
+		{name: "trial fails re-opens the breaker", result: (*circuitBreaker).Failure, wantState: stateOpen, wantAllow: false},
+		{name: "trial aborted allows another trial", result: (*circuitBreaker).Abort, wantState: stateHalfOpen, wantAllow: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			breaker, clock := newTestBreaker(1, time.Minute)
+			breaker.Failure()
+
+			clock.Advance(time.Minute - time.Second)
+			if breaker.Allow() {
+				t.Fatal("breaker should stay open until openTimeout has passed")
+			}
+
+			clock.Advance(time.Second)
+			if !breaker.Allow() {
+				t.Fatal("breaker should allow one trial after openTimeout")
+			}
+			if got := breaker.State(); got != stateHalfOpen {
+				t.Fatalf("state = %q, want %q", got, stateHalfOpen)
+			}
+			if breaker.Allow() {
+				t.Fatal("half-open breaker should allow only one trial at a time")
+			}
+
+			tt.result(breaker)
+			if got := breaker.State(); got != tt.wantState {
+				t.Errorf("state = %q, want %q", got, tt.wantState)
+			}
+			if got := breaker.Allow(); got != tt.wantAllow {
+				t.Errorf("Allow() = %v, want %v", got, tt.wantAllow)
+			}
+		})
+	}
+}
+
+func TestCircuitBreakerReopenRestartsTimeout(t *testing.T) {
+	breaker, clock := newTestBreaker(1, time.Minute)
+	breaker.Failure()
+
+	clock.Advance(time.Minute)
+	if !breaker.Allow() {
+		t.Fatal("breaker should allow a trial after openTimeout")
+	}
+	breaker.Failure()
+
+	clock.Advance(time.Minute - time.Second)
+	if breaker.Allow() {
+		t.Fatal("re-opened breaker should wait a full openTimeout again")
+	}
+	clock.Advance(time.Second)
+	if !breaker.Allow() {
+		t.Fatal("breaker should allow a trial after the new openTimeout")
+	}
+}
@@ -0,0 +1,332 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"go-template/pkg/config"
+	"go-template/pkg/custom_errors"
+	"go-template/pkg/logger"
+	"go-template/pkg/requestid"
+)
+
+// ค่าเริ่มต้นเมื่อไม่ได้ตั้งค่าไว้ใน config
+const (
+	DefaultTimeout                 = 10 * time.Second
+	DefaultInitialBackoff          = 200 * time.Millisecond
+	DefaultMaxBackoff              = 5 * time.Second
+	DefaultMaxRetryAfter           = 30 * time.Second
+	DefaultBreakerFailureThreshold = 5
+	DefaultBreakerOpenTimeout      = 30 * time.Second
+)
+
+// ขนาดสูงสุดของ body จากปลายทางที่จะแนบไปกับ error (กัน log บวม)
+const maxErrorBodyBytes = 1024
+
+// IdempotencyKeyHeader คือ header ที่บอกปลายทางว่า request ที่ key ซ้ำกันคือคำสั่งเดียวกัน
+// request ที่มี header นี้จะถูกลองซ้ำได้แม้จะเป็น POST/PATCH
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// ErrCircuitOpen ถูกใช้เป็นสาเหตุเมื่อเบรกเกอร์ของ host นั้นตัดวงจรอยู่
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// retryContextKey คือ key แบบ private สำหรับ WithRetry
+type retryContextKey struct{}
+
+// WithRetry คืน context ที่อนุญาตให้ลองซ้ำ request ที่ไม่ใช่ idempotent (POST/PATCH)
+// ใช้กับ endpoint ที่เป็น POST แต่แค่อ่านข้อมูล เช่น ขอราคาหรือเช็คสถานะพัสดุ
+func WithRetry(ctx context.Context) context.Context {
+	return context.WithValue(ctx, retryContextKey{}, true)
+}
+
+// Client คือ HTTP Client กลางสำหรับ Secondary Adapter ทุกตัว
+// มี timeout, retry แบบ exponential backoff + jitter, เคารพ Retry-After และ circuit breaker แยกราย host
+// ⭐️ ลองซ้ำเฉพาะ method ที่ idempotent (GET/HEAD/PUT/DELETE/OPTIONS) เท่านั้น
+// POST/PATCH ส่งครั้งเดียว เว้นแต่มี Idempotency-Key หรือผู้เรียกอนุญาตด้วย WithRetry
+// (ไม่งั้น timeout หลังปลายทางทำงานไปแล้วจะกลายเป็นการสั่งซ้ำ เช่น จองขนส่ง 2 ครั้ง)
+// error ทุกตัวที่คืนออกไปเป็น *custom_errors.AppError (EXTERNAL_API_ERROR) ที่มีรายละเอียดจากปลายทาง
+type Client struct {
+	name       string // ชื่อบริการปลายทาง (เช่น "dhl") ใช้ใน log และ error details
+	httpClient *http.Client
+	cfg        config.HTTPClientConfig
+	log        logger.Logger
+
+	mu       sync.Mutex
+	breakers map[string]*circuitBreaker
+}
+
+// New คือโรงงานสร้าง Client (ค่าไหนไม่ได้ตั้งจะใช้ค่าเริ่มต้น ยกเว้น MaxRetries ที่ 0 แปลว่าไม่ลองซ้ำ)
+func New(name string, cfg config.HTTPClientConfig, log logger.Logger) *Client {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultTimeout
+	}
+	if cfg.MaxRetries < 0 {
+		cfg.MaxRetries = 0
+	}
+	if cfg.InitialBackoff <= 0 {
+		cfg.InitialBackoff = DefaultInitialBackoff
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = DefaultMaxBackoff
+	}
+	if cfg.MaxRetryAfter <= 0 {
+		cfg.MaxRetryAfter = DefaultMaxRetryAfter
+	}
+	if cfg.BreakerFailureThreshold <= 0 {
+		cfg.BreakerFailureThreshold = DefaultBreakerFailureThreshold
+	}
+	if cfg.BreakerOpenTimeout <= 0 {
+		cfg.BreakerOpenTimeout = DefaultBreakerOpenTimeout
+	}
+
+	return &Client{
+		name:       name,
+		httpClient: &http.Client{Timeout: cfg.Timeout},
+		cfg:        cfg,
+		log:        log,
+		breakers:   make(map[string]*circuitBreaker),
+	}
+}
+
+// Do ส่ง request พร้อม retry และ circuit breaker
+// ถ้าสำเร็จ (2xx) จะคืน *http.Response ที่ผู้เรียกต้อง Close body เอง
+// request ที่มี body ต้องสร้างด้วย http.NewRequestWithContext + bytes.Reader (เพื่อให้มี GetBody สำหรับส่งซ้ำ)
+func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	breaker := c.breakerFor(req.URL.Host)
+	maxAttempts := 1
+	if isRetryableRequest(req) {
+		maxAttempts += c.cfg.MaxRetries
+	}
+
+	var lastStatus int
+	var lastBody string
+	var lastErr error
+	attempts := 0
+
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		attempts = attempt
+		if !breaker.Allow() {
+			return nil, c.newError(req, "ปลายทางไม่พร้อมให้บริการชั่วคราว", attempt-1, lastStatus, lastBody, ErrCircuitOpen)
+		}
+
+		attemptReq, err := cloneRequest(req)
+		if err != nil {
+			breaker.Abort()
+			return nil, c.newError(req, "ไม่สามารถสร้าง request ได้", attempt-1, 0, "", err)
+		}
+
+		resp, err := c.httpClient.Do(attemptReq)
+		if err != nil {
+			if ctx.Err() != nil {
+				breaker.Abort()
+				return nil, c.newError(req, "การเรียกปลายทางถูกยกเลิก", attempt, 0, "", ctx.Err())
+			}
+			breaker.Failure()
+			lastStatus, lastBody, lastErr = 0, "", err
+		} else if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			breaker.Success()
+			return resp, nil
+		} else {
+			body := readErrorBody(resp)
+			if !isRetryableStatus(resp.StatusCode) {
+				// 4xx อื่นๆ แปลว่าปลายทางยังปกติดี แค่ไม่รับ request นี้ -> ไม่ลองซ้ำ และไม่นับเป็นความล้มเหลวของ host
+				breaker.Success()
+				return nil, c.newError(req, fmt.Sprintf("ปลายทางตอบกลับด้วยสถานะ %d", resp.StatusCode), attempt, resp.StatusCode, body, nil)
+			}
+			breaker.Failure()
+			lastStatus, lastBody, lastErr = resp.StatusCode, body, nil
+		}
+
+		// ครบจำนวนครั้งแล้ว หรือเบรกเกอร์เพิ่งตัดวงจร (รอไปก็ถูกปฏิเสธอยู่ดี)
+		if attempt == maxAttempts || breaker.State() == stateOpen {
+			break
+		}
+
+		wait := c.backoff(attempt)
+		if resp != nil {
+			if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok && retryAfter > wait {
+				// ปลายทางขอให้รอนานเกินไป (หรือนานกว่าเวลาที่ผู้เรียกเหลืออยู่) -> ยอมแพ้เลยดีกว่ารอเปล่าๆ
+				if retryAfter > c.cfg.MaxRetryAfter || exceedsDeadline(ctx, retryAfter) {
+					break
+				}
+				wait = retryAfter
+			}
+		}
+		c.log.Warn("Outbound request failed, retrying",
+			"service", c.name, "method", req.Method, "host", req.URL.Host,
+			"attempt", attempt, "status", lastStatus, "wait", wait.String(), "breaker", breaker.State())
+
+		select {
+		case <-ctx.Done():
+			return nil, c.newError(req, "การเรียกปลายทางถูกยกเลิก", attempt, lastStatus, lastBody, ctx.Err())
+		case <-time.After(wait):
+		}
+	}
+
+	message := "ไม่สามารถเชื่อมต่อปลายทางได้"
+	if lastStatus != 0 {
+		message = fmt.Sprintf("ปลายทางตอบกลับด้วยสถานะ %d", lastStatus)
+	}
+	return nil, c.newError(req, message, attempts, lastStatus, lastBody, lastErr)
+}
+
+// DoJSON คือทางลัดสำหรับ API แบบ JSON: แปลง in เป็น body, ส่ง, แล้ว decode ผลลัพธ์ลง out
+// (in หรือ out เป็น nil ได้)
+func (c *Client) DoJSON(ctx context.Context, method, url string, headers map[string]string, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		encoded, err := json.Marshal(in)
+		if err != nil {
+			return custom_errors.SystemErrorWithDetails("ไม่สามารถแปลงข้อมูลเป็น JSON ได้", err.Error())
+		}
+		body = bytes.NewReader(encoded)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return custom_errors.SystemErrorWithDetails("ไม่สามารถสร้าง request ได้", err.Error())
+	}
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if out == nil {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return c.newError(req, "ปลายทางตอบกลับด้วยข้อมูลที่อ่านไม่ได้", 1, resp.StatusCode, "", err)
+	}
+	return nil
+}
+
+// --- Private Helpers ---
+
+func (c *Client) breakerFor(host string) *circuitBreaker {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	breaker, ok := c.breakers[host]
+	if !ok {
+		breaker = newCircuitBreaker(c.cfg.BreakerFailureThreshold, c.cfg.BreakerOpenTimeout)
+		c.breakers[host] = breaker
+	}
+	return breaker
+}
+
+// backoff คำนวณเวลารอแบบ exponential + full jitter: สุ่มระหว่าง 0 ถึง min(max, initial * 2^(attempt-1))
+// (jitter ช่วยไม่ให้ client ทุกตัวรุมยิงซ้ำพร้อมกันตอนปลายทางเพิ่งฟื้น)
+func (c *Client) backoff(attempt int) time.Duration {
+	ceiling := c.cfg.InitialBackoff << (attempt - 1)
+	if ceiling <= 0 || ceiling > c.cfg.MaxBackoff {
+		ceiling = c.cfg.MaxBackoff
+	}
+	return time.Duration(rand.Int64N(int64(ceiling) + 1))
+}
+
+func (c *Client) newError(req *http.Request, message string, attempts, status int, body string, cause error) *custom_errors.AppError {
+	details := map[string]interface{}{
+		"service":  c.name,
+		"method":   req.Method,
+		"url":      req.URL.Redacted(),
+		"attempts": attempts,
+	}
+	if status != 0 {
+		details["upstream_status"] = status
+	}
+	if body != "" {
+		details["upstream_body"] = body
+	}
+	if cause != nil {
+		details["cause"] = cause.Error()
+	}
+	return custom_errors.ExternalAPIError(fmt.Sprintf("%s: %s", c.name, message), details)
+}
+
+// cloneRequest สร้าง request ใหม่สำหรับแต่ละรอบ (body ของ request เดิมถูกอ่านหมดไปแล้วในรอบก่อน)
+func cloneRequest(req *http.Request) (*http.Request, error) {
+	clone := req.Clone(req.Context())
+	// ⭐️ ส่ง Request ID ต่อไปยังปลายทาง เพื่อให้ไล่ log ข้ามระบบได้ (ถ้าผู้เรียกตั้งเองแล้วจะไม่ทับ)
+	if id := requestid.FromContext(req.Context()); id != "" && clone.Header.Get(requestid.Header) == "" {
+		clone.Header.Set(requestid.Header, id)
+	}
+	if req.Body != nil && req.Body != http.NoBody {
+		if req.GetBody == nil {
+			return nil, errors.New("request body cannot be replayed: use a bytes.Reader or strings.Reader body")
+		}
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		clone.Body = body
+	}
+	return clone, nil
+}
+
+func exceedsDeadline(ctx context.Context, wait time.Duration) bool {
+	deadline, ok := ctx.Deadline()
+	return ok && time.Until(deadline) < wait
+}
+
+// isRetryableRequest บอกว่าส่ง request นี้ซ้ำได้โดยไม่ทำให้ปลายทางทำงานซ้ำ
+func isRetryableRequest(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, http.MethodOptions:
+		return true
+	}
+	if req.Header.Get(IdempotencyKeyHeader) != "" {
+		return true
+	}
+	allowed, _ := req.Context().Value(retryContextKey{}).(bool)
+	return allowed
+}
+
+func isRetryableStatus(status int) bool {
+	return status == http.StatusTooManyRequests || status >= 500
+}
+
+// readErrorBody อ่าน body ของ response ที่ล้มเหลว (ไม่เกิน maxErrorBodyBytes) แล้วปิดทิ้ง
+func readErrorBody(resp *http.Response) string {
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodyBytes))
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return string(body)
+}
+
+// parseRetryAfter รองรับทั้งแบบจำนวนวินาที ("120") และแบบวันที่ HTTP ("Wed, 21 Oct 2025 07:28:00 GMT")
+// now คือเวลาปัจจุบันที่ใช้เทียบกับแบบวันที่
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		if wait := t.Sub(now); wait > 0 {
+			return wait, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
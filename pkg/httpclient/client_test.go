@@ -0,0 +1,262 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go-template/pkg/config"
+	"go-template/pkg/logger"
+)
+
+// newTestClient สร้าง Client ที่ backoff สั้นมาก (ไม่ให้ test รอนาน) และเบรกเกอร์ไม่ตัดระหว่าง test
+func newTestClient(maxRetries int) *Client {
+	return New("test", config.HTTPClientConfig{
+		MaxRetries:              maxRetries,
+		InitialBackoff:          time.Millisecond,
+		MaxBackoff:              time.Millisecond,
+		BreakerFailureThreshold: 100,
+	}, logger.NewSlogLogger())
+}
+
+// failingServer ตอบ 503 ทุกครั้ง และนับจำนวนครั้งที่ถูกเรียก
+func failingServer(t *testing.T) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func TestDoRetriesOnlyIdempotentRequests(t *testing.T) {
+	tests := []struct {
+		name      string
+		method    string
+		header    string
+		withRetry bool
+		wantCalls int32
+	}{
+		{name: "GET is retried", method: http.MethodGet, wantCalls: 3},
+		{name: "HEAD is retried", method: http.MethodHead, wantCalls: 3},
+		{name: "PUT is retried", method: http.MethodPut, wantCalls: 3},
+		{name: "DELETE is retried", method: http.MethodDelete, wantCalls: 3},
+		{name: "OPTIONS is retried", method: http.MethodOptions, wantCalls: 3},
+		{name: "POST is sent once", method: http.MethodPost, wantCalls: 1},
+		{name: "PATCH is sent once", method: http.MethodPatch, wantCalls: 1},
+		{name: "POST with Idempotency-Key is retried", method: http.MethodPost, header: "key-1", wantCalls: 3},
+		{name: "POST with WithRetry is retried", method: http.MethodPost, withRetry: true, wantCalls: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, calls := failingServer(t)
+			client := newTestClient(2)
+
+			ctx := context.Background()
+			if tt.withRetry {
+				ctx = WithRetry(ctx)
+			}
+			req, err := http.NewRequestWithContext(ctx, tt.method, server.URL, strings.NewReader("{}"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.header != "" {
+				req.Header.Set(IdempotencyKeyHeader, tt.header)
+			}
+
+			if _, err := client.Do(req); err == nil {
+				t.Fatal("expected an error from a failing server")
+			}
+			if got := calls.Load(); got != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestNewMaxRetries(t *testing.T) {
+	tests := []struct {
+		name       string
+		maxRetries int
+		wantCalls  int32
+	}{
+		{name: "zero disables retries", maxRetries: 0, wantCalls: 1},
+		{name: "negative is treated as zero", maxRetries: -1, wantCalls: 1},
+		{name: "positive retries that many times", maxRetries: 3, wantCalls: 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, calls := failingServer(t)
+			client := newTestClient(tt.maxRetries)
+
+			req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := client.Do(req); err == nil {
+				t.Fatal("expected an error from a failing server")
+			}
+			if got := calls.Load(); got != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 10, 21, 7, 28, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		value    string
+		wantWait time.Duration
+		wantOK   bool
+	}{
+		{name: "empty", value: "", wantOK: false},
+		{name: "seconds", value: "120", wantWait: 120 * time.Second, wantOK: true},
+		{name: "zero seconds", value: "0", wantWait: 0, wantOK: true},
+		{name: "negative seconds", value: "-1", wantOK: false},
+		{name: "garbage", value: "soon", wantOK: false},
+		{name: "http date in the future", value: now.Add(90 * time.Second).Format(http.TimeFormat), wantWait: 90 * time.Second, wantOK: true},
+		{name: "http date in the past", value: now.Add(-time.Minute).Format(http.TimeFormat), wantWait: 0, wantOK: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wait, ok := parseRetryAfter(tt.value, now)
+			if ok != tt.wantOK || wait != tt.wantWait {
+				t.Errorf("parseRetryAfter(%q) = (%s, %v), want (%s, %v)", tt.value, wait, ok, tt.wantWait, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestBackoffStaysWithinCeiling(t *testing.T) {
+	client := New("test", config.HTTPClientConfig{
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     time.Second,
+	}, logger.NewSlogLogger())
+
+	ceilings := map[int]time.Duration{
+		1:  100 * time.Millisecond,
+		2:  200 * time.Millisecond,
+		3:  400 * time.Millisecond,
+		4:  800 * time.Millisecond,
+		5:  time.Second,
+		64: time.Second, // shift ล้นต้องไม่ทำให้ติดลบ
+	}
+	for attempt, ceiling := range ceilings {
+		for i := 0; i < 200; i++ {
+			if wait := client.backoff(attempt); wait < 0 || wait > ceiling {
+				t.Fatalf("backoff(%d) = %s, want between 0 and %s", attempt, wait, ceiling)
+			}
+		}
+	}
+}
+
+func TestDoRetryAfter(t *testing.T) {
+	tests := []struct {
+		name       string
+		retryAfter string
+		timeout    time.Duration
+		wantCalls  int32
+	}{
+		{name: "short Retry-After is honoured", retryAfter: "0", wantCalls: 3},
+		{name: "Retry-After above maxRetryAfter gives up", retryAfter: "60", wantCalls: 1},
+		{name: "Retry-After beyond the caller deadline gives up", retryAfter: "2", timeout: time.Second, wantCalls: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls.Add(1)
+				w.Header().Set("Retry-After", tt.retryAfter)
+				w.WriteHeader(http.StatusTooManyRequests)
+			}))
+			defer server.Close()
+
+			client := New("test", config.HTTPClientConfig{
+				MaxRetries:              2,
+				InitialBackoff:          time.Millisecond,
+				MaxBackoff:              time.Millisecond,
+				MaxRetryAfter:           5 * time.Second,
+				BreakerFailureThreshold: 100,
+			}, logger.NewSlogLogger())
+
+			ctx := context.Background()
+			if tt.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.timeout)
+				defer cancel()
+			}
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := client.Do(req); err == nil {
+				t.Fatal("expected an error from a rate-limited server")
+			}
+			if got := calls.Load(); got != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestDoDoesNotRetryClientErrors(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	client := newTestClient(3)
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Do(req); err == nil {
+		t.Fatal("expected an error for a 400 response")
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("calls = %d, want 1", got)
+	}
+	if got := client.breakerFor(req.URL.Host).State(); got != stateClosed {
+		t.Errorf("breaker state = %q, want %q (4xx is not a host failure)", got, stateClosed)
+	}
+}
+
+func TestDoStopsWhenBreakerOpens(t *testing.T) {
+	server, calls := failingServer(t)
+	client := New("test", config.HTTPClientConfig{
+		MaxRetries:              5,
+		InitialBackoff:          time.Millisecond,
+		MaxBackoff:              time.Millisecond,
+		BreakerFailureThreshold: 2,
+		BreakerOpenTimeout:      time.Minute,
+	}, logger.NewSlogLogger())
+
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Do(req); err == nil {
+		t.Fatal("expected an error from a failing server")
+	}
+	if got := calls.Load(); got != 2 {
+		t.Fatalf("calls = %d, want 2 (retries stop once the breaker opens)", got)
+	}
+
+	_, err = client.Do(req)
+	if err == nil || !strings.Contains(err.Error(), "ชั่วคราว") {
+		t.Fatalf("expected a circuit-open error, got %v", err)
+	}
+	if got := calls.Load(); got != 2 {
+		t.Errorf("calls = %d, want 2 (open breaker must not reach the server)", got)
+	}
+}
@@ -0,0 +1,68 @@
+package logger
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// fieldsContextKey คือ key แบบ private สำหรับเก็บ key-value ประจำ request ไว้ใน context
+type fieldsContextKey struct{}
+
+// FieldsContextKey คือ key ที่ Middleware ใช้ฝาก key-value ประจำ request (เช่น request_id, user_id)
+// (fiber.Ctx เป็น context.Context และ Value() จะอ่านจาก Locals ให้เรา เช่น c.Locals(logger.FieldsContextKey, fields))
+var FieldsContextKey = fieldsContextKey{}
+
+// WithFields คืน context ใหม่ที่มี key-value เพิ่มจากของเดิม
+// Logger ที่เรียก WithContext(ctx) หรือ FromContext(ctx) จะแนบค่าเหล่านี้ไปกับทุกบรรทัด
+func WithFields(ctx context.Context, args ...any) context.Context {
+	return context.WithValue(ctx, FieldsContextKey, AppendFields(ctx, args...))
+}
+
+// AppendFields คืน slice ใหม่ของ key-value ใน ctx ต่อด้วย args (ไม่แก้ slice เดิมใน ctx)
+// ใช้ตอนต้องฝากค่าผ่าน fiber Locals ซึ่งสร้าง context ใหม่ไม่ได้
+func AppendFields(ctx context.Context, args ...any) []any {
+	existing := FieldsFromContext(ctx)
+	fields := make([]any, 0, len(existing)+len(args))
+	fields = append(fields, existing...)
+	return append(fields, args...)
+}
+
+// FieldsFromContext ดึง key-value ประจำ request ออกจาก context (คืน nil ถ้าไม่มี)
+func FieldsFromContext(ctx context.Context) []any {
+	if ctx == nil {
+		return nil
+	}
+	fields, _ := ctx.Value(FieldsContextKey).([]any)
+	return fields
+}
+
+// defaultLogger คือนักข่าวประจำระบบ สำหรับโค้ดที่ไม่ได้ถือ Logger ไว้เอง
+var defaultLogger atomic.Value // เก็บ loggerHolder
+
+type loggerHolder struct{ logger Logger }
+
+// SetDefault ตั้งนักข่าวประจำระบบ (main.go เรียกครั้งเดียวหลังสร้าง Logger)
+func SetDefault(l Logger) {
+	if l == nil {
+		return
+	}
+	defaultLogger.Store(loggerHolder{logger: l})
+}
+
+// Default คืนนักข่าวประจำระบบ (ถ้ายังไม่ได้ตั้งจะใช้ Slog Logger)
+func Default() Logger {
+	if holder, ok := defaultLogger.Load().(loggerHolder); ok {
+		return holder.logger
+	}
+	l := NewSlogLogger()
+	if defaultLogger.CompareAndSwap(nil, loggerHolder{logger: l}) {
+		return l
+	}
+	return defaultLogger.Load().(loggerHolder).logger
+}
+
+// FromContext คืนนักข่าวประจำระบบที่แนบ key-value ของ request นี้ไว้แล้ว
+// เทียบเท่ากับ Default().WithContext(ctx)
+func FromContext(ctx context.Context) Logger {
+	return Default().WithContext(ctx)
+}
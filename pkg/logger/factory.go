@@ -0,0 +1,136 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"go-template/pkg/config"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// รูปแบบและปลายทางของ log ที่ตั้งค่าได้ใน config
+const (
+	FormatPretty = "pretty"
+	FormatJSON   = "json"
+	FormatLogfmt = "logfmt"
+
+	OutputStdout = "stdout"
+	OutputFile   = "file"
+)
+
+// New คือโรงงานสร้าง Logger ตาม config.LoggingConfig
+// คืน LevelVar กลับไปด้วย เพื่อให้เปลี่ยนระดับ log ตอนระบบทำงานอยู่ได้ (Logger ลูกทุกตัวใช้ตัวเดียวกัน)
+// และ io.Closer ที่ต้องเรียกตอน shutdown เพื่อปิดไฟล์ log
+func New(cfg config.LoggingConfig) (Logger, *slog.LevelVar, io.Closer, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, nil, nil, err
+	}
+
+	level := new(slog.LevelVar)
+	initial, err := ParseLevel(cfg.Level)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	level.Set(initial)
+
+	writer, closer, toTerminal := newWriter(cfg)
+	redactor := NewRedactor(cfg.Redaction)
+
+	switch strings.ToLower(cfg.Format) {
+	case FormatPretty:
+		// ⭐️ ใส่สีเฉพาะตอนพิมพ์ลง terminal อย่างเดียว ไม่งั้นไฟล์ log จะเต็มไปด้วยรหัส ANSI
+		return newPrettyLogger(writer, level, toTerminal, redactor), level, closer, nil
+	case FormatLogfmt:
+		return newSlogLogger(slog.NewTextHandler(writer, &slog.HandlerOptions{Level: level}), redactor), level, closer, nil
+	default:
+		return newSlogLogger(slog.NewJSONHandler(writer, &slog.HandlerOptions{Level: level}), redactor), level, closer, nil
+	}
+}
+
+// ParseLevel แปลงชื่อระดับ (debug, info, warn, error) เป็น slog.Level (ว่าง = info)
+func ParseLevel(name string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return slog.LevelInfo, fmt.Errorf("unknown log level %q (debug, info, warn, error)", name)
+	}
+}
+
+// LevelName แปลง slog.Level กลับเป็นชื่อที่ใช้ใน config
+func LevelName(level slog.Level) string {
+	switch {
+	case level <= slog.LevelDebug:
+		return "debug"
+	case level <= slog.LevelInfo:
+		return "info"
+	case level <= slog.LevelWarn:
+		return "warn"
+	default:
+		return "error"
+	}
+}
+
+// newWriter รวมปลายทางทั้งหมดเป็น writer เดียว (ว่าง = stdout)
+// toTerminal = true เมื่อเขียนลง stdout อย่างเดียว
+func newWriter(cfg config.LoggingConfig) (io.Writer, io.Closer, bool) {
+	outputs := cfg.Outputs
+	if len(outputs) == 0 {
+		outputs = []string{OutputStdout}
+	}
+
+	var writers []io.Writer
+	var closer io.Closer = nopCloser{}
+	toTerminal := true
+	for _, output := range outputs {
+		switch strings.ToLower(output) {
+		case OutputStdout:
+			writers = append(writers, os.Stdout)
+		case OutputFile:
+			file := &lumberjack.Logger{
+				Filename:   cfg.File.Path,
+				MaxSize:    cfg.File.MaxSizeMB,
+				MaxAge:     cfg.File.MaxAgeDays,
+				MaxBackups: cfg.File.MaxBackups,
+				Compress:   cfg.File.Compress,
+				LocalTime:  true,
+			}
+			writers = append(writers, file)
+			closer = file
+			toTerminal = false
+		}
+	}
+	if len(writers) == 1 {
+		return writers[0], closer, toTerminal
+	}
+	return io.MultiWriter(writers...), closer, toTerminal
+}
+
+// dumpLevel แปลงระดับแบบ string ที่ Dumpf รับ (LevelDebug, LevelSuccess, ...) เป็น slog.Level
+func dumpLevel(level string) (slog.Level, bool) {
+	switch strings.ToUpper(level) {
+	case LevelDebug:
+		return slog.LevelDebug, true
+	case LevelInfo, LevelSuccess:
+		return slog.LevelInfo, true
+	case LevelWarn:
+		return slog.LevelWarn, true
+	case LevelError:
+		return slog.LevelError, true
+	}
+	return slog.LevelInfo, false
+}
+
+type nopCloser struct{}
+
+func (nopCloser) Close() error { return nil }
@@ -1,5 +1,7 @@
 package logger
 
+import "context"
+
 // Logger คือ "สัญญาใจ" หรือ Interface ที่นักข่าวทุกคนต้องทำตาม
 // ไม่ว่าจะเป็นนักข่าวสายสวยงาม หรือสายโปรดักชัน ก็ต้องมี 4 ความสามารถนี้
 type Logger interface {
@@ -22,6 +24,13 @@ type Logger interface {
 	Dump(data interface{})
 
 	Dumpf(level string, msg string, data interface{})
+
+	// With คืน Logger ลูกที่แนบ key-value เหล่านี้ไปกับทุกบรรทัด (เช่น "order_id", id)
+	With(args ...any) Logger
+
+	// WithContext คืน Logger ลูกที่แนบ key-value ประจำ request จาก ctx (เช่น request_id, user_id)
+	// ถ้า ctx ไม่มีอะไรแนบไว้จะคืนตัวเดิม
+	WithContext(ctx context.Context) Logger
 }
 
 const (
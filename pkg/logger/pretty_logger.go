@@ -2,9 +2,13 @@
 package logger
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log"
+	"log/slog"
+	"os"
 	"runtime"
 	"strings"
 )
@@ -20,10 +24,24 @@ const (
 	ColorPurple = "\033[95m"
 )
 
-type prettyLogger struct{}
+type prettyLogger struct {
+	out    *log.Logger
+	level  *slog.LevelVar // ใช้ร่วมกับ Logger ลูกทุกตัว เปลี่ยนที่เดียวมีผลทั้งหมด
+	colors bool
+	attrs  []any // key-value ที่ผูกไว้ผ่าน With (ปิดข้อมูลลับแล้ว)
+	// redactor ปิดข้อมูลลับใน args และ Dump ก่อนพิมพ์ (nil = ไม่ปิด)
+	redactor *Redactor
+}
 
+// NewPrettyLogger คือโรงงานสร้างนักข่าวสายสวยงาม พิมพ์ทุกระดับ (รวม Debug) ลง Standard Error แบบมีสี
 func NewPrettyLogger() Logger {
-	return &prettyLogger{}
+	level := new(slog.LevelVar)
+	level.Set(slog.LevelDebug)
+	return newPrettyLogger(os.Stderr, level, true, DefaultRedactor())
+}
+
+func newPrettyLogger(w io.Writer, level *slog.LevelVar, colors bool, redactor *Redactor) *prettyLogger {
+	return &prettyLogger{out: log.New(w, "", log.LstdFlags), level: level, colors: colors, redactor: redactor}
 }
 
 // ✨ อัปเกรด getFileInfo ให้ฉลาดขึ้น
@@ -34,8 +52,8 @@ func getFileInfo() string {
 		if !ok {
 			break
 		}
-		// ถ้าเจอไฟล์ที่ไม่ได้อยู่ใน package logger ก็คือไฟล์ที่เรียกเราจริงๆ!
-		if !strings.Contains(file, "pkg/logger") {
+		// ถ้าเจอไฟล์ที่ไม่ได้อยู่ใน package logger (หรือ logsink ที่ห่อเราไว้) ก็คือไฟล์ที่เรียกเราจริงๆ!
+		if !strings.Contains(file, "pkg/logger") && !strings.Contains(file, "pkg/logsink") {
 			parts := strings.Split(file, "/")
 			return fmt.Sprintf("%s:%d", parts[len(parts)-1], line)
 		}
@@ -43,6 +61,35 @@ func getFileInfo() string {
 	return "???:0"
 }
 
+// With คืนนักข่าวคนใหม่ที่จำ key-value เหล่านี้ไว้ (ตัวเดิมไม่ถูกแก้)
+func (l *prettyLogger) With(args ...any) Logger {
+	attrs := make([]any, 0, len(l.attrs)+len(args))
+	attrs = append(attrs, l.attrs...)
+	attrs = append(attrs, l.redactor.Args(args)...)
+	return &prettyLogger{out: l.out, level: l.level, colors: l.colors, attrs: attrs, redactor: l.redactor}
+}
+
+// WithContext คืนนักข่าวที่จำ key-value ประจำ request จาก ctx ไว้
+func (l *prettyLogger) WithContext(ctx context.Context) Logger {
+	fields := FieldsFromContext(ctx)
+	if len(fields) == 0 {
+		return l
+	}
+	return l.With(fields...)
+}
+
+// formatArgs รวม key-value ที่ผูกไว้กับ args ของบรรทัดนี้ แล้วจัดรูปแบบ
+func (l *prettyLogger) formatArgs(args ...any) string {
+	args = l.redactor.Args(args)
+	if len(l.attrs) == 0 {
+		return formatArgs(args...)
+	}
+	all := make([]any, 0, len(l.attrs)+len(args))
+	all = append(all, l.attrs...)
+	all = append(all, args...)
+	return formatArgs(all...)
+}
+
 // ✨ อัปเกรดให้เข้าใจ key-value pairs แบบ slog
 func formatArgs(args ...any) string {
 	if len(args) == 0 {
@@ -63,62 +110,83 @@ func formatArgs(args ...any) string {
 }
 
 func (l *prettyLogger) Debug(msg string, args ...any) {
+	if !l.enabled(slog.LevelDebug) {
+		return
+	}
 	location := getFileInfo()
-	formattedArgs := formatArgs(args...)
-	log.Printf("%s🐛 DEBUG %s: %s%s%s", ColorBlue, location, msg, formattedArgs, ColorReset)
+	formattedArgs := l.formatArgs(args...)
+	l.print(ColorBlue, fmt.Sprintf("🐛 DEBUG %s: %s%s", location, l.redactor.String(msg), formattedArgs))
 }
 
 func (l *prettyLogger) Info(msg string, args ...any) {
+	if !l.enabled(slog.LevelInfo) {
+		return
+	}
 	location := getFileInfo()
-	formattedArgs := formatArgs(args...)
-	log.Printf("%sℹ️  INFO  %s: %s%s%s", ColorCyan, location, msg, formattedArgs, ColorReset)
+	formattedArgs := l.formatArgs(args...)
+	l.print(ColorCyan, fmt.Sprintf("ℹ️  INFO  %s: %s%s", location, l.redactor.String(msg), formattedArgs))
 }
 
 func (l *prettyLogger) Success(msg string, args ...any) {
+	if !l.enabled(slog.LevelInfo) {
+		return
+	}
 	location := getFileInfo()
-	formattedArgs := formatArgs(args...)
-	log.Printf("%s✅ SUCCESS %s: %s%s%s", ColorGreen, location, msg, formattedArgs, ColorReset)
+	formattedArgs := l.formatArgs(args...)
+	l.print(ColorGreen, fmt.Sprintf("✅ SUCCESS %s: %s%s", location, l.redactor.String(msg), formattedArgs))
 }
 
 func (l *prettyLogger) Warn(msg string, args ...any) {
+	if !l.enabled(slog.LevelWarn) {
+		return
+	}
 	location := getFileInfo()
-	formattedArgs := formatArgs(args...)
-	log.Printf("%s⚠️  WARN  %s: %s%s%s", ColorYellow, location, msg, formattedArgs, ColorReset)
+	formattedArgs := l.formatArgs(args...)
+	l.print(ColorYellow, fmt.Sprintf("⚠️  WARN  %s: %s%s", location, l.redactor.String(msg), formattedArgs))
 }
 
 func (l *prettyLogger) Error(msg string, err error, args ...any) {
+	if !l.enabled(slog.LevelError) {
+		return
+	}
 	location := getFileInfo()
 	// สำหรับ Error เราจะเพิ่ม field 'err' เข้าไปใน args ด้วย
 	allArgs := append(args, "err", err)
-	formattedArgs := formatArgs(allArgs...)
-	log.Printf("%s❌ ERROR %s: %s%s%s", ColorRed, location, msg, formattedArgs, ColorReset)
+	formattedArgs := l.formatArgs(allArgs...)
+	l.print(ColorRed, fmt.Sprintf("❌ ERROR %s: %s%s", location, l.redactor.String(msg), formattedArgs))
 }
 
 func (l *prettyLogger) Print(msg string) {
+	if !l.enabled(slog.LevelDebug) {
+		return
+	}
 	location := getFileInfo()
-	// เลือกสีตาม Level
-	color := ColorPurple
-	log.Printf("%s🔍 Print  %s: %s\n%s%s", color, location, msg, ColorReset)
+	l.print(ColorPurple, fmt.Sprintf("🔍 Print  %s: %s%s\n", location, l.redactor.String(msg), l.formatArgs()))
 }
 
 func (l *prettyLogger) Dump(data interface{}) {
+	if !l.enabled(slog.LevelDebug) {
+		return
+	}
 	location := getFileInfo()
 	// แปลง object เป็น JSON สวยๆ
-	jsonBytes, err := json.MarshalIndent(data, "", "  ")
+	jsonBytes, err := json.MarshalIndent(l.redactor.Value(data), "", "  ")
 	if err != nil {
 		l.Error("Failed to dump data", err)
 		return
 	}
-	color := ColorPurple
-
-	log.Printf("%s🔍 DUMP  %s: %s\n%s%s", color, location, string(jsonBytes), ColorReset)
+	l.print(ColorPurple, fmt.Sprintf("🔍 DUMP  %s:%s %s\n", location, l.formatArgs(), string(jsonBytes)))
 }
 
 func (l *prettyLogger) Dumpf(level string, msg string, data interface{}) {
+	slogLevel, ok := dumpLevel(level)
+	if !ok || !l.enabled(slogLevel) {
+		return
+	}
 	location := getFileInfo()
 
 	// แปลง object เป็น JSON สวยๆ
-	jsonBytes, err := json.MarshalIndent(data, "", "  ")
+	jsonBytes, err := json.MarshalIndent(l.redactor.Value(data), "", "  ")
 	if err != nil {
 		l.Error("Failed to dump data", err)
 		return
@@ -137,5 +205,19 @@ func (l *prettyLogger) Dumpf(level string, msg string, data interface{}) {
 	case LevelSuccess:
 		color = ColorGreen
 	}
-	log.Printf("%s🔍 DUMP_F  %s: %s\n%s%s", color, location, msg, string(jsonBytes), ColorReset)
+	l.print(color, fmt.Sprintf("🔍 DUMP_F  %s: %s%s\n%s", location, l.redactor.String(msg), l.formatArgs(), string(jsonBytes)))
+}
+
+// enabled บอกว่าระดับนี้ควรถูกพิมพ์ไหม (อ่านจาก LevelVar ทุกครั้ง จึงเปลี่ยนตอนรันได้)
+func (l *prettyLogger) enabled(level slog.Level) bool {
+	return level >= l.level.Level()
+}
+
+// print เขียน 1 บรรทัดออกไป (ใส่สีเฉพาะตอนเขียนลง terminal)
+func (l *prettyLogger) print(color, line string) {
+	if l.colors {
+		l.out.Print(color + line + ColorReset)
+		return
+	}
+	l.out.Print(line)
 }
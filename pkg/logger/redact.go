@@ -0,0 +1,274 @@
+package logger
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"go-template/pkg/config"
+)
+
+// RedactedValue คือค่าที่ใช้แทนข้อมูลลับใน log
+const RedactedValue = "[REDACTED]"
+
+// DefaultRedactKeys คือชื่อ key/field ที่ถือว่าเป็นความลับเมื่อไม่ได้ตั้งค่าไว้
+// (เทียบแบบ "มีคำนี้อยู่ในชื่อ" ไม่สนตัวพิมพ์และ _ - เช่น "token" จะครอบคลุม access_token, TokenHash)
+var DefaultRedactKeys = []string{"password", "token", "secret", "authorization", "apikey", "cookie"}
+
+// ขุดลึกสุดกี่ชั้น (กัน struct ที่ชี้วนกลับหากัน)
+const maxRedactDepth = 10
+
+var (
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+	// เบอร์ไทย (08x-xxx-xxxx, +66 8x xxx xxxx, 02-xxx-xxxx) และเบอร์สากลแบบ E.164 (+xxxxxxxxxxx)
+	phonePattern = regexp.MustCompile(`(?:\+66[- ]?|\b0)\d{1,2}[- ]?\d{3}[- ]?\d{3,4}\b|\+\d{8,15}\b`)
+)
+
+// Redactor คือ "ปากกาลบคำผิด" ที่ปิดข้อมูลลับก่อนถูกเขียนลง log
+// - field ใน struct ที่มี tag `log:"redact"` จะถูกแทนด้วย [REDACTED] เสมอ
+// - key ของ args/map/field ที่ตรงกับ key pattern จะถูกแทนด้วย [REDACTED]
+// - อีเมลและเบอร์โทรที่อยู่ใน string จะถูกปิดบางส่วน (เช่น j***@example.com, ******5678)
+// ใช้ตัวเดียวกันทั้งใน prettyLogger, slogLogger และ logsink เพื่อให้ผลเหมือนกันทุกปลายทาง
+type Redactor struct {
+	keys       []string
+	maskEmails bool
+	maskPhones bool
+}
+
+// NewRedactor คือโรงงานสร้าง Redactor จาก config (Disabled = ไม่ปิดอะไรเลย คืน nil)
+func NewRedactor(cfg config.RedactionConfig) *Redactor {
+	if cfg.Disabled {
+		return nil
+	}
+	keys := cfg.Keys
+	if len(keys) == 0 {
+		keys = DefaultRedactKeys
+	}
+	normalized := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if key = normalizeKey(key); key != "" {
+			normalized = append(normalized, key)
+		}
+	}
+	return &Redactor{keys: normalized, maskEmails: cfg.MaskEmails, maskPhones: cfg.MaskPhones}
+}
+
+// DefaultRedactor คือ Redactor ที่ใช้ key pattern มาตรฐานและปิดทั้งอีเมลและเบอร์โทร
+func DefaultRedactor() *Redactor {
+	return NewRedactor(config.RedactionConfig{MaskEmails: true, MaskPhones: true})
+}
+
+// Args ปิดข้อมูลลับใน key-value pairs แบบ slog (คืน slice ใหม่ ไม่แก้ของเดิม)
+func (r *Redactor) Args(args []any) []any {
+	if r == nil || len(args) == 0 {
+		return args
+	}
+	out := make([]any, len(args))
+	for i := 0; i < len(args); i += 2 {
+		out[i] = args[i]
+		if i+1 >= len(args) {
+			break
+		}
+		if key, ok := args[i].(string); ok && r.IsSensitiveKey(key) {
+			out[i+1] = RedactedValue
+			continue
+		}
+		out[i+1] = r.Value(args[i+1])
+	}
+	return out
+}
+
+// Value คืนสำเนาของ v ที่ปิดข้อมูลลับแล้ว
+// struct/map/slice จะถูกแปลงเป็น map[string]any / []any (ใช้ชื่อตาม json tag) เพื่อให้ Dump ออกมาเหมือน JSON เดิม
+func (r *Redactor) Value(v any) any {
+	if r == nil {
+		return v
+	}
+	return r.value(reflect.ValueOf(v), 0)
+}
+
+// IsSensitiveKey บอกว่าชื่อ key นี้เป็นความลับไหม
+func (r *Redactor) IsSensitiveKey(key string) bool {
+	if r == nil {
+		return false
+	}
+	key = normalizeKey(key)
+	for _, pattern := range r.keys {
+		if strings.Contains(key, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// String ปิดอีเมลและเบอร์โทรที่อยู่ในข้อความ
+func (r *Redactor) String(s string) string {
+	if r == nil {
+		return s
+	}
+	if r.maskEmails {
+		s = emailPattern.ReplaceAllStringFunc(s, maskEmail)
+	}
+	if r.maskPhones {
+		s = phonePattern.ReplaceAllStringFunc(s, maskPhone)
+	}
+	return s
+}
+
+var (
+	errorType         = reflect.TypeOf((*error)(nil)).Elem()
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+func (r *Redactor) value(v reflect.Value, depth int) any {
+	if !v.IsValid() {
+		return nil
+	}
+	if depth > maxRedactDepth {
+		return "[MAX_DEPTH]"
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+	}
+
+	// error: เก็บเป็นข้อความ (ที่ปิดอีเมล/เบอร์โทรแล้ว)
+	if v.Type().Implements(errorType) && v.CanInterface() {
+		return r.String(v.Interface().(error).Error())
+	}
+	// ชนิดที่รู้วิธีแปลงตัวเองเป็น JSON/ข้อความอยู่แล้ว (time.Time, decimal.Decimal, ...) ปล่อยไว้ตามเดิม
+	if v.Kind() != reflect.Pointer && v.CanInterface() &&
+		(v.Type().Implements(jsonMarshalerType) || v.Type().Implements(textMarshalerType)) {
+		return v.Interface()
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		return r.value(v.Elem(), depth+1)
+	case reflect.String:
+		return r.String(v.String())
+	case reflect.Struct:
+		out := make(map[string]any, v.NumField())
+		r.structFields(v, out, depth)
+		return out
+	case reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+		out := make(map[string]any, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			key := fmt.Sprint(iter.Key().Interface())
+			if r.IsSensitiveKey(key) {
+				out[key] = RedactedValue
+				continue
+			}
+			out[key] = r.value(iter.Value(), depth+1)
+		}
+		return out
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return fmt.Sprintf("[%d bytes]", v.Len()) // ไม่พิมพ์ []byte ดิบ (อาจเป็น payload/กุญแจ)
+		}
+		out := make([]any, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			out[i] = r.value(v.Index(i), depth+1)
+		}
+		return out
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		return v.Type().String()
+	}
+	if v.CanInterface() {
+		return v.Interface()
+	}
+	return nil
+}
+
+// structFields ใส่ field ที่ export แล้วของ struct ลงใน out (embedded struct ถูกแผ่ออกมาแบบเดียวกับ encoding/json)
+func (r *Redactor) structFields(v reflect.Value, out map[string]any, depth int) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		// embedded struct ที่ชนิดไม่ได้ export ยังต้องแผ่ field ที่ export ออกมาแบบเดียวกับ encoding/json
+		if !field.IsExported() && !(field.Anonymous && field.Type.Kind() == reflect.Struct) {
+			continue
+		}
+		name, skip := jsonFieldName(field)
+		if skip {
+			continue
+		}
+
+		fieldValue := v.Field(i)
+		if field.Anonymous && name == "" {
+			embedded := fieldValue
+			if embedded.Kind() == reflect.Pointer {
+				if embedded.IsNil() {
+					continue
+				}
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct && !embedded.Type().Implements(jsonMarshalerType) {
+				r.structFields(embedded, out, depth)
+				continue
+			}
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		if field.Tag.Get("log") == "redact" || r.IsSensitiveKey(name) || r.IsSensitiveKey(field.Name) {
+			out[name] = RedactedValue
+			continue
+		}
+		out[name] = r.value(fieldValue, depth+1)
+	}
+}
+
+// jsonFieldName อ่านชื่อจาก json tag ("" = ใช้ชื่อ field, skip = json:"-")
+func jsonFieldName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", true
+	}
+	name, _, _ := strings.Cut(tag, ",")
+	return name, false
+}
+
+func normalizeKey(key string) string {
+	key = strings.ToLower(key)
+	key = strings.ReplaceAll(key, "_", "")
+	return strings.ReplaceAll(key, "-", "")
+}
+
+// maskEmail เหลือตัวอักษรแรกของชื่อและโดเมนไว้ เช่น john.doe@example.com -> j***@example.com
+func maskEmail(email string) string {
+	local, domain, found := strings.Cut(email, "@")
+	if !found || local == "" {
+		return RedactedValue
+	}
+	return local[:1] + "***@" + domain
+}
+
+// maskPhone เหลือ 4 หลักท้ายไว้ เช่น 081-234-5678 -> ******5678
+func maskPhone(phone string) string {
+	digits := make([]byte, 0, len(phone))
+	for i := 0; i < len(phone); i++ {
+		if phone[i] >= '0' && phone[i] <= '9' {
+			digits = append(digits, phone[i])
+		}
+	}
+	if len(digits) <= 4 {
+		return strings.Repeat("*", len(digits))
+	}
+	return strings.Repeat("*", len(digits)-4) + string(digits[len(digits)-4:])
+}
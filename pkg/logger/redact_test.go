@@ -0,0 +1,289 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"go-template/pkg/config"
+)
+
+func TestRedactorIsSensitiveKey(t *testing.T) {
+	r := DefaultRedactor()
+	tests := []struct {
+		key  string
+		want bool
+	}{
+		{key: "password", want: true},
+		{key: "Password", want: true},
+		{key: "password_hash", want: true},
+		{key: "PasswordHash", want: true},
+		{key: "new-password", want: true},
+		{key: "access_token", want: true},
+		{key: "TokenHash", want: true},
+		{key: "refreshToken", want: true},
+		{key: "client_secret", want: true},
+		{key: "Authorization", want: true},
+		{key: "api_key", want: true},
+		{key: "X-API-Key", want: true},
+		{key: "Set-Cookie", want: true},
+		{key: "email", want: false},
+		{key: "user_id", want: false},
+		{key: "tokenizer_version", want: true}, // เทียบแบบ "มีคำนี้อยู่ในชื่อ" จึงปิดเกินไว้ก่อน
+		{key: "status", want: false},
+		{key: "", want: false},
+	}
+	for _, tt := range tests {
+		if got := r.IsSensitiveKey(tt.key); got != tt.want {
+			t.Errorf("IsSensitiveKey(%q) = %v, want %v", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestNewRedactor(t *testing.T) {
+	t.Run("disabled returns nil and nil passes values through", func(t *testing.T) {
+		r := NewRedactor(config.RedactionConfig{Disabled: true})
+		if r != nil {
+			t.Fatalf("NewRedactor(disabled) = %+v, want nil", r)
+		}
+		args := []any{"password", "hunter2"}
+		if got := r.Args(args); !reflect.DeepEqual(got, args) {
+			t.Errorf("nil Args() = %v, want %v", got, args)
+		}
+		if got := r.String("john@example.com"); got != "john@example.com" {
+			t.Errorf("nil String() = %q", got)
+		}
+	})
+
+	t.Run("custom keys replace the defaults", func(t *testing.T) {
+		r := NewRedactor(config.RedactionConfig{Keys: []string{"National_ID", " "}})
+		if !r.IsSensitiveKey("national-id") || !r.IsSensitiveKey("NationalID") {
+			t.Error("custom key should match regardless of case, _ and -")
+		}
+		if r.IsSensitiveKey("password") {
+			t.Error("custom keys should replace the default list")
+		}
+	})
+
+	t.Run("email and phone masking follow the config", func(t *testing.T) {
+		r := NewRedactor(config.RedactionConfig{})
+		const s = "john@example.com 081-234-5678"
+		if got := r.String(s); got != s {
+			t.Errorf("String() = %q, want unchanged when masking is off", got)
+		}
+	})
+}
+
+func TestRedactorString(t *testing.T) {
+	r := DefaultRedactor()
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "email", in: "login by john.doe@example.com failed", want: "login by j***@example.com failed"},
+		{name: "plus address", in: "a+tag@sub.example.co.th", want: "a***@sub.example.co.th"},
+		{name: "two emails", in: "a@x.io,b@y.io", want: "a***@x.io,b***@y.io"},
+		{name: "thai mobile with dashes", in: "call 081-234-5678", want: "call ******5678"},
+		{name: "thai mobile without separators", in: "0812345678", want: "******5678"},
+		{name: "thai landline", in: "02-123-4567", want: "*****4567"},
+		{name: "+66 format", in: "+66 81 234 5678", want: "*******5678"},
+		{name: "E.164", in: "+447911123456", want: "********3456"},
+		{name: "order number is not a phone", in: "ORD-20250101-0001", want: "ORD-20250101-0001"},
+		{name: "short numbers are left alone", in: "id 12345 qty 3", want: "id 12345 qty 3"},
+		{name: "thai text", in: "สั่งซื้อสำเร็จ", want: "สั่งซื้อสำเร็จ"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := r.String(tt.in); got != tt.want {
+				t.Errorf("String(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactorArgs(t *testing.T) {
+	r := DefaultRedactor()
+	in := []any{
+		"user_id", 7,
+		"password", "hunter2",
+		"Authorization", "Bearer abc",
+		"email", "john@example.com",
+		"headers", map[string]string{"X-Api-Key": "k", "Accept": "json"},
+		"dangling",
+	}
+	want := []any{
+		"user_id", 7,
+		"password", RedactedValue,
+		"Authorization", RedactedValue,
+		"email", "j***@example.com",
+		"headers", map[string]any{"X-Api-Key": RedactedValue, "Accept": "json"},
+		"dangling",
+	}
+
+	got := r.Args(in)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Args() =\n  %#v\nwant\n  %#v", got, want)
+	}
+	if in[3] != "hunter2" {
+		t.Error("Args() must not modify the caller's slice")
+	}
+}
+
+type redactAddress struct {
+	Line  string `json:"line"`
+	Phone string `json:"phone"`
+}
+
+type redactAudit struct {
+	CreatedBy string `json:"created_by"`
+	Version   int    `json:"version"`
+}
+
+type redactUser struct {
+	redactAudit
+	ID           uint              `json:"id"`
+	Email        string            `json:"email"`
+	PasswordHash string            `json:"-"`
+	Pin          string            `json:"pin" log:"redact"`
+	Note         string            `log:"redact"`
+	APIKey       string            `json:"key"` // ชื่อ json ไม่ตรง pattern แต่ชื่อ field ตรง
+	Address      *redactAddress    `json:"address"`
+	Tags         []string          `json:"tags"`
+	Meta         map[string]any    `json:"meta"`
+	Avatar       []byte            `json:"avatar"`
+	Nested       map[string]string `json:"nested"`
+	CreatedAt    time.Time         `json:"created_at"`
+	private      string
+}
+
+func TestRedactorValueStruct(t *testing.T) {
+	r := DefaultRedactor()
+	createdAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	user := &redactUser{
+		redactAudit:  redactAudit{CreatedBy: "admin@example.com", Version: 3},
+		ID:           1,
+		Email:        "john@example.com",
+		PasswordHash: "$2a$10$hash",
+		Pin:          "1234",
+		Note:         "secret note",
+		APIKey:       "k-123",
+		Address:      &redactAddress{Line: "1 Sukhumvit", Phone: "0812345678"},
+		Tags:         []string{"vip", "jane@example.com"},
+		Meta:         map[string]any{"refresh_token": "rt", "inner": map[string]any{"client_secret": "s", "ok": 1}},
+		Avatar:       []byte{1, 2, 3},
+		CreatedAt:    createdAt,
+		private:      "hidden",
+	}
+
+	want := map[string]any{
+		"created_by": "a***@example.com",
+		"version":    3,
+		"id":         uint(1),
+		"email":      "j***@example.com",
+		"pin":        RedactedValue,
+		"Note":       RedactedValue,
+		"key":        RedactedValue,
+		"address":    map[string]any{"line": "1 Sukhumvit", "phone": "******5678"},
+		"tags":       []any{"vip", "j***@example.com"},
+		"meta": map[string]any{
+			"refresh_token": RedactedValue,
+			"inner":         map[string]any{"client_secret": RedactedValue, "ok": 1},
+		},
+		"avatar":     "[3 bytes]",
+		"nested":     nil,
+		"created_at": createdAt,
+	}
+
+	got := r.Value(user)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Value() =\n  %#v\nwant\n  %#v", got, want)
+	}
+	if user.Pin != "1234" || user.Address.Phone != "0812345678" {
+		t.Error("Value() must not modify the original struct")
+	}
+}
+
+func TestRedactorValueOtherKinds(t *testing.T) {
+	r := DefaultRedactor()
+	tests := []struct {
+		name string
+		in   any
+		want any
+	}{
+		{name: "nil", in: nil, want: nil},
+		{name: "nil pointer", in: (*redactUser)(nil), want: nil},
+		{name: "number", in: 42, want: 42},
+		{name: "error message is masked", in: errors.New("user john@example.com not found"), want: "user j***@example.com not found"},
+		{name: "slice of structs", in: []redactAddress{{Phone: "02-123-4567"}}, want: []any{map[string]any{"line": "", "phone": "*****4567"}}},
+		{name: "func", in: func() {}, want: "func()"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := r.Value(tt.in); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Value() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactorValueStopsAtMaxDepth(t *testing.T) {
+	type node struct {
+		Next *node `json:"next"`
+	}
+	loop := &node{}
+	loop.Next = loop
+
+	got := DefaultRedactor().Value(loop)
+	for depth := 0; ; depth++ {
+		m, ok := got.(map[string]any)
+		if !ok {
+			if got != "[MAX_DEPTH]" {
+				t.Fatalf("at depth %d got %#v, want [MAX_DEPTH]", depth, got)
+			}
+			return
+		}
+		if depth > maxRedactDepth+1 {
+			t.Fatal("self-referencing struct was not cut off")
+		}
+		got = m["next"]
+	}
+}
+
+func TestLoggersRedactOutput(t *testing.T) {
+	level := new(slog.LevelVar)
+	level.Set(slog.LevelDebug)
+
+	newLoggers := map[string]func(*bytes.Buffer) Logger{
+		"json": func(b *bytes.Buffer) Logger {
+			return newSlogLogger(slog.NewJSONHandler(b, &slog.HandlerOptions{Level: level}), DefaultRedactor())
+		},
+		"logfmt": func(b *bytes.Buffer) Logger {
+			return newSlogLogger(slog.NewTextHandler(b, &slog.HandlerOptions{Level: level}), DefaultRedactor())
+		},
+		"pretty": func(b *bytes.Buffer) Logger { return newPrettyLogger(b, level, false, DefaultRedactor()) },
+	}
+	for name, newLogger := range newLoggers {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			log := newLogger(&buf)
+			log.With("api_key", "with-secret").Info("login john@example.com", "password", "hunter2", "phone", "0812345678")
+			log.Dump(&redactUser{Pin: "9999", Email: "jane@example.com"})
+			log.Error("failed", errors.New("token for john@example.com expired"), "token_hash", "abc123")
+
+			out := buf.String()
+			for _, leaked := range []string{"with-secret", "hunter2", "0812345678", "john@example.com", "jane@example.com", "9999", "abc123"} {
+				if strings.Contains(out, leaked) {
+					t.Errorf("output leaks %q:\n%s", leaked, out)
+				}
+			}
+			if !strings.Contains(out, RedactedValue) {
+				t.Errorf("output has no %s marker:\n%s", RedactedValue, out)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@
 package logger
 
 import (
+	"context"
 	"log/slog"
 	"os"
 )
@@ -9,59 +10,69 @@ import (
 // slogLogger คือนักข่าวสายโปรดักชัน ที่รายงานทุกอย่างเป็น JSON
 type slogLogger struct {
 	logger *slog.Logger
+	// redactor ปิดข้อมูลลับใน args และ Dump ก่อนส่งให้ handler (nil = ไม่ปิด)
+	redactor *Redactor
 }
 
 // NewSlogLogger คือโรงงานสร้างนักข่าวสายโปรดักชัน
-// มันจะสร้าง Logger ที่พิมพ์ JSON ออกไปที่ Standard Output
+// มันจะสร้าง Logger ที่พิมพ์ JSON ระดับ Info ขึ้นไปออกไปที่ Standard Output
+// (ถ้าต้องการกำหนดระดับ/รูปแบบ/ปลายทางเอง ให้ใช้ New กับ config.LoggingConfig)
 func NewSlogLogger() Logger {
-	return &slogLogger{
-		logger: slog.New(slog.NewJSONHandler(os.Stdout, nil)),
-	}
+	return newSlogLogger(slog.NewJSONHandler(os.Stdout, nil), DefaultRedactor())
+}
+
+func newSlogLogger(handler slog.Handler, redactor *Redactor) *slogLogger {
+	return &slogLogger{logger: slog.New(handler), redactor: redactor}
 }
 
 // --- Implementation of Logger interface ---
 
+func (l *slogLogger) With(args ...any) Logger {
+	return &slogLogger{logger: l.logger.With(l.redactor.Args(args)...), redactor: l.redactor}
+}
+
+func (l *slogLogger) WithContext(ctx context.Context) Logger {
+	fields := FieldsFromContext(ctx)
+	if len(fields) == 0 {
+		return l
+	}
+	return l.With(fields...)
+}
+
 func (l *slogLogger) Debug(msg string, args ...any) {
-	l.logger.Debug(msg, args...)
+	l.logger.Debug(l.redactor.String(msg), l.redactor.Args(args)...)
 }
 
 func (l *slogLogger) Info(msg string, args ...any) {
-	l.logger.Info(msg, args...)
+	l.logger.Info(l.redactor.String(msg), l.redactor.Args(args)...)
 }
 
 func (l *slogLogger) Warn(msg string, args ...any) {
-	l.logger.Warn(msg, args...)
+	l.logger.Warn(l.redactor.String(msg), l.redactor.Args(args)...)
 }
 
 func (l *slogLogger) Error(msg string, err error, args ...any) {
 	// slog จะฉลาดพอที่จะรู้ว่าถ้ามี key ชื่อ "err" มันจะแสดงผลให้สวยงามเป็นพิเศษ
 	allArgs := append(args, "err", err)
-	l.logger.Error(msg, allArgs...)
+	l.logger.Error(l.redactor.String(msg), l.redactor.Args(allArgs)...)
 }
 
 func (l *slogLogger) Success(msg string, args ...any) {
-	l.logger.Info(msg, args...)
+	l.logger.Info(l.redactor.String(msg), l.redactor.Args(args)...)
 }
 
 func (l *slogLogger) Print(msg string) {
-	l.logger.Debug(msg)
+	l.logger.Debug(l.redactor.String(msg))
 }
 
 func (l *slogLogger) Dump(data interface{}) {
-	l.logger.Debug("dump_data", data)
+	l.logger.Debug("dump", "dump_data", l.redactor.Value(data))
 }
 
 func (l *slogLogger) Dumpf(level string, msg string, data interface{}) {
-	switch level {
-	case LevelDebug:
-		l.logger.Debug(msg, "dumpf_data", data)
-	case LevelInfo:
-		l.logger.Info(msg, "dumpf_data", data)
-	case LevelWarn:
-		l.logger.Warn(msg, "dumpf_data", data)
-	case LevelError:
-		l.logger.Error(msg, "dumpf_data", data)
-	case LevelSuccess:
-		l.logger.Info(msg, "dumpf_data", data)
+	slogLevel, ok := dumpLevel(level)
+	if !ok {
+		return
 	}
+	l.logger.Log(context.Background(), slogLevel, l.redactor.String(msg), "dumpf_data", l.redactor.Value(data))
 }
@@ -0,0 +1,108 @@
+package logsink
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"go-template/pkg/logger"
+)
+
+// sinkLogger คือ logger.Logger ที่ส่งทุกบรรทัดต่อให้ Logger ปกติ (stdout/file) แล้ว "สำเนา"
+// บรรทัดที่ระดับถึง MinLevel ลง Sink ด้วย
+type sinkLogger struct {
+	next  logger.Logger
+	sink  *Sink
+	attrs []any // key-value ที่ผูกไว้ผ่าน With/WithContext (next ก็ผูกไว้แล้วเหมือนกัน)
+}
+
+// Logger ห่อ next ให้สำเนา log ลงฐานข้อมูลด้วย
+// ⭐️ Logger ที่ได้ควรใช้กับโค้ดทั้งระบบ แต่ "ห้าม" ส่งกลับเข้าไปเป็น fallback ของ Sink เอง
+func (s *Sink) Logger(next logger.Logger) logger.Logger {
+	return &sinkLogger{next: next, sink: s}
+}
+
+func (l *sinkLogger) Debug(msg string, args ...any) {
+	l.next.Debug(msg, args...)
+	l.record(slog.LevelDebug, msg, nil, args)
+}
+
+func (l *sinkLogger) Info(msg string, args ...any) {
+	l.next.Info(msg, args...)
+	l.record(slog.LevelInfo, msg, nil, args)
+}
+
+func (l *sinkLogger) Warn(msg string, args ...any) {
+	l.next.Warn(msg, args...)
+	l.record(slog.LevelWarn, msg, nil, args)
+}
+
+func (l *sinkLogger) Error(msg string, err error, args ...any) {
+	l.next.Error(msg, err, args...)
+	l.record(slog.LevelError, msg, err, args)
+}
+
+func (l *sinkLogger) Success(msg string, args ...any) {
+	l.next.Success(msg, args...)
+	l.record(slog.LevelInfo, msg, nil, args)
+}
+
+func (l *sinkLogger) Print(msg string) {
+	l.next.Print(msg)
+	l.record(slog.LevelDebug, msg, nil, nil)
+}
+
+func (l *sinkLogger) Dump(data interface{}) {
+	l.next.Dump(data)
+	l.record(slog.LevelDebug, "dump", nil, []any{"dump_data", data})
+}
+
+func (l *sinkLogger) Dumpf(level string, msg string, data interface{}) {
+	l.next.Dumpf(level, msg, data)
+	switch strings.ToUpper(level) {
+	case logger.LevelDebug:
+		l.record(slog.LevelDebug, msg, nil, []any{"dumpf_data", data})
+	case logger.LevelInfo, logger.LevelSuccess:
+		l.record(slog.LevelInfo, msg, nil, []any{"dumpf_data", data})
+	case logger.LevelWarn:
+		l.record(slog.LevelWarn, msg, nil, []any{"dumpf_data", data})
+	case logger.LevelError:
+		l.record(slog.LevelError, msg, nil, []any{"dumpf_data", data})
+	}
+}
+
+func (l *sinkLogger) With(args ...any) logger.Logger {
+	attrs := make([]any, 0, len(l.attrs)+len(args))
+	attrs = append(attrs, l.attrs...)
+	attrs = append(attrs, args...)
+	return &sinkLogger{next: l.next.With(args...), sink: l.sink, attrs: attrs}
+}
+
+func (l *sinkLogger) WithContext(ctx context.Context) logger.Logger {
+	fields := logger.FieldsFromContext(ctx)
+	if len(fields) == 0 {
+		return l
+	}
+	return l.With(fields...)
+}
+
+// record สำเนาบรรทัดนี้ลง Sink (ถ้าระดับถึง)
+func (l *sinkLogger) record(level slog.Level, msg string, err error, args []any) {
+	if !l.sink.Enabled(level) {
+		return
+	}
+	all := args
+	if len(l.attrs) > 0 {
+		all = make([]any, 0, len(l.attrs)+len(args))
+		all = append(all, l.attrs...)
+		all = append(all, args...)
+	}
+	// ⭐️ ปิดข้อมูลลับด้วยกฎเดียวกับ Logger ปกติ ก่อนเก็บลง DB (ค่าที่อยู่ใน DB ลบยากกว่าไฟล์ log มาก)
+	redactor := l.sink.redactor
+	var errText string
+	if err != nil {
+		errText = redactor.String(err.Error())
+	}
+	l.sink.enqueue(record{log: newLogModel(time.Now(), levelName(level), redactor.String(msg), errText, redactor.Args(all))})
+}
@@ -0,0 +1,149 @@
+package logsink
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// LogModel คือ "ชุดเกราะ" สำหรับ GORM (ตาราง app_logs ใน Logs Database)
+type LogModel struct {
+	ID        uint      `gorm:"primarykey"`
+	LoggedAt  time.Time `gorm:"not null"`
+	Level     string    `gorm:"not null"`
+	Message   string    `gorm:"not null"`
+	RequestID *string
+	UserID    *uint
+	Error     *string
+	Attrs     []byte `gorm:"type:jsonb;not null"`
+}
+
+func (LogModel) TableName() string {
+	return "app_logs"
+}
+
+// AccessModel คือ "ชุดเกราะ" สำหรับ GORM (ตาราง http_access_logs ใน Logs Database)
+type AccessModel struct {
+	ID        uint      `gorm:"primarykey"`
+	LoggedAt  time.Time `gorm:"not null"`
+	RequestID *string
+	UserID    *uint
+	Method    string  `gorm:"not null"`
+	Path      string  `gorm:"not null"`
+	Status    int     `gorm:"not null"`
+	LatencyMS float64 `gorm:"column:latency_ms;not null"`
+	IP        *string `gorm:"column:ip"`
+	UserAgent *string
+}
+
+func (AccessModel) TableName() string {
+	return "http_access_logs"
+}
+
+// AccessRecord คือข้อมูลของ HTTP request 1 ครั้งที่ middleware ส่งมาให้เก็บ
+type AccessRecord struct {
+	Time      time.Time
+	RequestID string
+	UserID    uint // 0 = ไม่ได้เข้าสู่ระบบ
+	Method    string
+	Path      string
+	Status    int
+	Latency   time.Duration
+	IP        string
+	UserAgent string
+}
+
+func (r *AccessRecord) toModel() *AccessModel {
+	m := &AccessModel{
+		LoggedAt:  r.Time,
+		RequestID: nullableString(r.RequestID),
+		Method:    r.Method,
+		Path:      r.Path,
+		Status:    r.Status,
+		LatencyMS: float64(r.Latency.Microseconds()) / 1000,
+		IP:        nullableString(r.IP),
+		UserAgent: nullableString(r.UserAgent),
+	}
+	if r.UserID != 0 {
+		userID := r.UserID
+		m.UserID = &userID
+	}
+	return m
+}
+
+// newLogModel แปลง key-value ของ Logger เป็นแถวของ app_logs
+// request_id และ user_id ถูกแยกออกมาเป็นคอลัมน์เพื่อให้ค้นหาได้เร็ว ที่เหลือเก็บเป็น JSONB
+func newLogModel(at time.Time, level, msg, errText string, args []any) *LogModel {
+	m := &LogModel{LoggedAt: at, Level: level, Message: msg, Error: nullableString(errText)}
+
+	attrs := make(map[string]any, len(args)/2)
+	for i := 0; i < len(args); i += 2 {
+		key := fmt.Sprint(args[i])
+		var value any = "(MISSING)"
+		if i+1 < len(args) {
+			value = args[i+1]
+		}
+		switch key {
+		case "request_id":
+			if id := fmt.Sprint(value); id != "" {
+				m.RequestID = &id
+			}
+			continue
+		case "user_id":
+			if userID, ok := toUint(value); ok {
+				m.UserID = &userID
+				continue
+			}
+		}
+		attrs[key] = jsonValue(value)
+	}
+
+	encoded, marshalErr := json.Marshal(attrs)
+	if marshalErr != nil {
+		// ค่าบางตัวแปลงเป็น JSON ไม่ได้ (เช่น channel, func) ก็เก็บเป็นข้อความแทน
+		for key, value := range attrs {
+			attrs[key] = fmt.Sprint(value)
+		}
+		encoded, _ = json.Marshal(attrs)
+	}
+	m.Attrs = encoded
+	return m
+}
+
+// jsonValue แปลงค่าที่ json.Marshal ทำได้ไม่ดีให้อ่านรู้เรื่อง
+func jsonValue(value any) any {
+	switch v := value.(type) {
+	case error:
+		return v.Error()
+	case time.Time:
+		return v
+	case time.Duration:
+		return v.String()
+	case fmt.Stringer:
+		return v.String()
+	}
+	return value
+}
+
+func toUint(value any) (uint, bool) {
+	switch v := value.(type) {
+	case uint:
+		return v, true
+	case uint32:
+		return uint(v), true
+	case uint64:
+		return uint(v), true
+	case int:
+		return uint(v), v >= 0
+	case int64:
+		return uint(v), v >= 0
+	}
+	return 0, false
+}
+
+func nullableString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
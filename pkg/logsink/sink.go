@@ -0,0 +1,234 @@
+package logsink
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go-template/pkg/config"
+	"go-template/pkg/logger"
+
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// ค่าเริ่มต้นเมื่อไม่ได้ตั้งค่าไว้ใน config
+const (
+	DefaultBufferSize    = 10000
+	DefaultBatchSize     = 500
+	DefaultFlushInterval = 2 * time.Second
+
+	DropNewest = "drop_newest"
+	DropOldest = "drop_oldest"
+)
+
+// ErrClosed ถูกคืนจาก Close เมื่อถูกปิดไปแล้ว
+var ErrClosed = errors.New("log sink is already closed")
+
+// record คือรายการ 1 ชิ้นใน buffer (มีได้อย่างใดอย่างหนึ่ง)
+type record struct {
+	log    *LogModel
+	access *AccessModel
+}
+
+// Sink คือ "ท่อ" ที่ส่ง log และ HTTP access record ลง Logs Database แบบ async
+// รายการจะถูกพักไว้ใน buffer ที่มีขนาดจำกัด แล้วมี goroutine เดียวคอยเขียนลง DB เป็น batch
+// ⭐️ ผู้เรียกไม่ต้องรอ DB เลย: ถ้า buffer เต็มจะทิ้งรายการตาม DropPolicy แล้วนับไว้ แทนการทำให้ request ช้าลง
+type Sink struct {
+	db       *gorm.DB
+	cfg      config.LogSinkConfig
+	minLevel slog.Level
+	// fallback คือ Logger ปกติ (stdout/file) ใช้รายงานปัญหาของ Sink เอง
+	// ห้ามเป็น Logger ที่ห่อด้วย Sink ไม่งั้น error ตอนเขียน DB จะวนกลับเข้ามาไม่รู้จบ
+	fallback logger.Logger
+	// redactor ปิดข้อมูลลับก่อนเขียนลง DB (nil = ไม่ปิด)
+	redactor *logger.Redactor
+
+	buffer  chan record
+	mu      sync.RWMutex // ป้องกันการส่งเข้า buffer ที่ถูกปิดไปแล้ว
+	closed  bool
+	done    chan struct{}
+	dropped atomic.Int64
+}
+
+// New คือโรงงานสร้าง Sink และเริ่ม goroutine ที่เขียนลง DB ทันที
+// ต้องเรียก Close ตอน shutdown เพื่อเขียนรายการที่ค้างอยู่ให้หมด
+// redactor ควรสร้างจาก config เดียวกับ Logger ปกติ (logger.NewRedactor(cfg.Logging.Redaction)) เพื่อให้ทุกปลายทางปิดเหมือนกัน
+func New(db *gorm.DB, cfg config.LogSinkConfig, redactor *logger.Redactor, fallback logger.Logger) (*Sink, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	if cfg.BufferSize <= 0 {
+		cfg.BufferSize = DefaultBufferSize
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = DefaultBatchSize
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = DefaultFlushInterval
+	}
+	if cfg.DropPolicy == "" {
+		cfg.DropPolicy = DropNewest
+	}
+	if cfg.MinLevel == "" {
+		cfg.MinLevel = "warn"
+	}
+	minLevel, err := logger.ParseLevel(cfg.MinLevel)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Sink{
+		// query ของ Sink เองไม่ต้องถูก log (ไม่งั้นทุก batch จะสร้าง log ใหม่ขึ้นมาอีก)
+		db:       db.Session(&gorm.Session{Logger: gormlogger.Discard}),
+		cfg:      cfg,
+		minLevel: minLevel,
+		fallback: fallback,
+		redactor: redactor,
+		buffer:   make(chan record, cfg.BufferSize),
+		done:     make(chan struct{}),
+	}
+	go s.run()
+	return s, nil
+}
+
+// Enabled บอกว่าระดับนี้ควรถูกเก็บลง DB ไหม
+func (s *Sink) Enabled(level slog.Level) bool {
+	return level >= s.minLevel
+}
+
+// AccessLogEnabled บอกว่าต้องเก็บ HTTP access record ด้วยไหม
+func (s *Sink) AccessLogEnabled() bool {
+	return s.cfg.AccessLog
+}
+
+// RecordAccess ฝาก HTTP access record 1 รายการไว้ให้เขียนลง DB
+func (s *Sink) RecordAccess(rec *AccessRecord) {
+	if !s.cfg.AccessLog {
+		return
+	}
+	s.enqueue(record{access: rec.toModel()})
+}
+
+// Dropped คืนจำนวนรายการที่ถูกทิ้งไปเพราะ buffer เต็มตั้งแต่เริ่มระบบ
+func (s *Sink) Dropped() int64 {
+	return s.dropped.Load()
+}
+
+// Close หยุดรับรายการใหม่ แล้วรอให้รายการที่ค้างใน buffer ถูกเขียนลง DB จนหมด (หรือจน ctx หมดเวลา)
+func (s *Sink) Close(ctx context.Context) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return ErrClosed
+	}
+	s.closed = true
+	close(s.buffer)
+	s.mu.Unlock()
+
+	select {
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// enqueue ใส่รายการลง buffer โดยไม่รอเด็ดขาด
+func (s *Sink) enqueue(r record) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed {
+		s.dropped.Add(1)
+		return
+	}
+
+	select {
+	case s.buffer <- r:
+		return
+	default:
+	}
+
+	if s.cfg.DropPolicy == DropOldest {
+		// ทิ้งรายการเก่าสุดออก 1 ชิ้นเพื่อให้มีที่ว่าง (ถ้าแย่งกันหลาย goroutine อาจยังเต็มอยู่ ก็ทิ้งตัวใหม่แทน)
+		select {
+		case <-s.buffer:
+			s.dropped.Add(1)
+		default:
+		}
+		select {
+		case s.buffer <- r:
+			return
+		default:
+		}
+	}
+	s.dropped.Add(1)
+}
+
+// run คือ goroutine เดียวที่อ่านจาก buffer แล้วเขียนลง DB เป็น batch
+// จะเขียนเมื่อครบ BatchSize หรือครบ FlushInterval แล้วแต่อย่างไหนถึงก่อน
+func (s *Sink) run() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	logs := make([]*LogModel, 0, s.cfg.BatchSize)
+	accesses := make([]*AccessModel, 0, s.cfg.BatchSize)
+	var reportedDropped int64
+
+	flush := func() {
+		if len(logs) > 0 {
+			s.write("app_logs", logs, len(logs))
+			logs = logs[:0]
+		}
+		if len(accesses) > 0 {
+			s.write("http_access_logs", accesses, len(accesses))
+			accesses = accesses[:0]
+		}
+		// รายงานจำนวนที่ถูกทิ้งผ่าน Logger ปกติ (ไม่รายงานทุกชิ้น กัน log ท่วม)
+		if dropped := s.dropped.Load(); dropped > reportedDropped {
+			s.fallback.Warn("Log sink buffer full, entries dropped", "dropped", dropped-reportedDropped, "total_dropped", dropped, "policy", s.cfg.DropPolicy)
+			reportedDropped = dropped
+		}
+	}
+
+	for {
+		select {
+		case r, ok := <-s.buffer:
+			if !ok {
+				flush()
+				return
+			}
+			if r.log != nil {
+				logs = append(logs, r.log)
+			}
+			if r.access != nil {
+				accesses = append(accesses, r.access)
+			}
+			if len(logs) >= s.cfg.BatchSize || len(accesses) >= s.cfg.BatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+// write เขียน batch ลง DB ถ้าไม่สำเร็จจะรายงานแล้วทิ้ง batch นั้นไป (ไม่ retry เพื่อไม่ให้ buffer ค้าง)
+func (s *Sink) write(table string, rows any, count int) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := s.db.WithContext(ctx).Table(table).Create(rows).Error; err != nil {
+		s.fallback.Error("Failed to write log batch to logs database", err, "table", table, "rows", count)
+	}
+}
+
+// levelName แปลง slog.Level เป็นค่าที่ตาราง app_logs ยอมรับ
+func levelName(level slog.Level) string {
+	return strings.ToUpper(logger.LevelName(level))
+}
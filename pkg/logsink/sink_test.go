@@ -0,0 +1,203 @@
+package logsink
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"go-template/pkg/config"
+	"go-template/pkg/logger"
+
+	gormpostgres "gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// --- Fake driver: ส่งจำนวนแถวของทุก INSERT ออกทาง channel ---
+
+type insert struct {
+	table string
+	rows  int
+}
+
+type fakeConnector struct{ inserts chan insert }
+type fakeConn struct{ inserts chan insert }
+
+func (c fakeConnector) Connect(context.Context) (driver.Conn, error) { return fakeConn(c), nil }
+func (c fakeConnector) Driver() driver.Driver                        { return nil }
+func (fakeConn) Prepare(string) (driver.Stmt, error)                 { return nil, errors.New("not supported") }
+func (fakeConn) Close() error                                        { return nil }
+func (fakeConn) Begin() (driver.Tx, error)                           { return fakeTx{}, nil }
+
+func (c fakeConn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	c.record(query)
+	return emptyRows{}, nil
+}
+
+func (c fakeConn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	c.record(query)
+	return driver.RowsAffected(0), nil
+}
+
+// record นับแถวจาก INSERT INTO "table" (...) VALUES (...),(...)
+func (c fakeConn) record(query string) {
+	if !strings.HasPrefix(query, "INSERT INTO") {
+		return
+	}
+	table := strings.Trim(strings.Fields(query)[2], `"`)
+	c.inserts <- insert{table: table, rows: strings.Count(query, "),(") + 1}
+}
+
+// fakeTx รองรับ Transaction ที่ GORM เปิดครอบ Create ให้อัตโนมัติ
+type fakeTx struct{}
+
+func (fakeTx) Commit() error   { return nil }
+func (fakeTx) Rollback() error { return nil }
+
+type emptyRows struct{}
+
+func (emptyRows) Columns() []string         { return nil }
+func (emptyRows) Close() error              { return nil }
+func (emptyRows) Next([]driver.Value) error { return io.EOF }
+
+func newTestSink(t *testing.T, cfg config.LogSinkConfig) (*Sink, chan insert) {
+	t.Helper()
+	inserts := make(chan insert, 100)
+	db, err := gorm.Open(gormpostgres.New(gormpostgres.Config{Conn: sql.OpenDB(fakeConnector{inserts: inserts})}), &gorm.Config{
+		Logger:               gormlogger.Discard,
+		DisableAutomaticPing: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sink, err := New(db, cfg, nil, logger.NewSlogLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sink, inserts
+}
+
+func logRecord(message string) record {
+	return record{log: &LogModel{LoggedAt: time.Now(), Level: "WARN", Message: message, Attrs: []byte("{}")}}
+}
+
+func waitInsert(t *testing.T, inserts chan insert, timeout time.Duration) insert {
+	t.Helper()
+	select {
+	case got := <-inserts:
+		return got
+	case <-time.After(timeout):
+		t.Fatal("no batch was written")
+		return insert{}
+	}
+}
+
+func assertNoInsert(t *testing.T, inserts chan insert) {
+	t.Helper()
+	select {
+	case got := <-inserts:
+		t.Fatalf("unexpected batch %+v", got)
+	default:
+	}
+}
+
+// --- Tests ---
+
+func TestSinkDropPolicy(t *testing.T) {
+	tests := []struct {
+		policy string
+		want   []string // รายการที่เหลือใน buffer
+	}{
+		{policy: DropNewest, want: []string{"1", "2"}},
+		{policy: DropOldest, want: []string{"4", "5"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			// ไม่เริ่ม run() เพื่อให้ buffer เต็มค้างไว้
+			s := &Sink{cfg: config.LogSinkConfig{DropPolicy: tt.policy}, buffer: make(chan record, 2)}
+			for _, message := range []string{"1", "2", "3", "4", "5"} {
+				s.enqueue(logRecord(message))
+			}
+
+			if got := s.Dropped(); got != 3 {
+				t.Errorf("Dropped() = %d, want 3", got)
+			}
+			close(s.buffer)
+			var kept []string
+			for r := range s.buffer {
+				kept = append(kept, r.log.Message)
+			}
+			if strings.Join(kept, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("buffer = %v, want %v", kept, tt.want)
+			}
+		})
+	}
+}
+
+func TestSinkFlushesAtBatchSize(t *testing.T) {
+	s, inserts := newTestSink(t, config.LogSinkConfig{BatchSize: 3, FlushInterval: time.Hour})
+	defer s.Close(context.Background())
+
+	s.enqueue(logRecord("1"))
+	s.enqueue(logRecord("2"))
+	time.Sleep(20 * time.Millisecond)
+	assertNoInsert(t, inserts)
+
+	s.enqueue(logRecord("3"))
+	if got := waitInsert(t, inserts, time.Second); got != (insert{table: "app_logs", rows: 3}) {
+		t.Errorf("batch = %+v, want 3 rows in app_logs", got)
+	}
+}
+
+func TestSinkFlushesAtInterval(t *testing.T) {
+	s, inserts := newTestSink(t, config.LogSinkConfig{AccessLog: true, BatchSize: 100, FlushInterval: 20 * time.Millisecond})
+	defer s.Close(context.Background())
+
+	s.enqueue(logRecord("1"))
+	s.enqueue(logRecord("2"))
+	s.RecordAccess(&AccessRecord{Time: time.Now(), Method: "GET", Path: "/health", Status: 200})
+
+	got := map[string]int{}
+	for i := 0; i < 2; i++ {
+		batch := waitInsert(t, inserts, time.Second)
+		got[batch.table] += batch.rows
+	}
+	if got["app_logs"] != 2 || got["http_access_logs"] != 1 {
+		t.Errorf("rows written = %v, want 2 app_logs and 1 http_access_logs", got)
+	}
+}
+
+func TestSinkCloseDrainsBuffer(t *testing.T) {
+	s, inserts := newTestSink(t, config.LogSinkConfig{BatchSize: 100, FlushInterval: time.Hour})
+
+	for i := 0; i < 5; i++ {
+		s.enqueue(logRecord("pending"))
+	}
+	if err := s.Close(context.Background()); err != nil {
+		t.Fatalf("Close() = %v", err)
+	}
+	// Close รอจนเขียนเสร็จแล้ว batch จึงต้องอยู่ใน channel ทันที
+	select {
+	case got := <-inserts:
+		if got.rows != 5 {
+			t.Errorf("batch on close = %+v, want 5 rows", got)
+		}
+	default:
+		t.Fatal("Close() returned before the buffer was written")
+	}
+
+	if err := s.Close(context.Background()); !errors.Is(err, ErrClosed) {
+		t.Errorf("second Close() = %v, want ErrClosed", err)
+	}
+
+	s.enqueue(logRecord("too late"))
+	if got := s.Dropped(); got != 1 {
+		t.Errorf("Dropped() after close = %d, want 1", got)
+	}
+	assertNoInsert(t, inserts)
+}
@@ -0,0 +1,29 @@
+package metrics
+
+import (
+	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/middleware/adaptor"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// DefaultPath คือ path ของ endpoint เมื่อไม่ได้ตั้งค่าไว้
+const DefaultPath = "/metrics"
+
+// Registry คือ "สมุดรวม" metric ทั้งหมดของระบบ
+// แยกจาก prometheus.DefaultRegisterer เพื่อให้รู้แน่ว่ามีอะไรถูกเปิดออกไปบ้าง
+// package อื่นลงทะเบียน metric ของตัวเองผ่าน Registry.MustRegister (ปกติทำใน init หรือ sync.Once)
+var Registry = prometheus.NewRegistry()
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// Handler คืน fiber.Handler ที่พิมพ์ metric ทั้งหมดในรูปแบบที่ Prometheus ดึงไปได้
+func Handler() fiber.Handler {
+	return adaptor.HTTPHandler(promhttp.HandlerFor(Registry, promhttp.HandlerOpts{}))
+}
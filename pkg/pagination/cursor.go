@@ -0,0 +1,54 @@
+// pkg/pagination/cursor.go
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+)
+
+// ErrInvalidCursor ถูกคืนเมื่อ cursor ที่ Client ส่งมาถอดรหัสไม่ได้
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor คือ "ที่คั่นหนังสือ" ของ Keyset Pagination
+// มันจำค่าของ field ที่ใช้เรียง (Value) + ID ของแถวนั้น (ใช้ตัดสินเมื่อค่าเท่ากัน)
+// Client จะเห็นเป็นแค่ string ทึบๆ (base64) ไม่ต้องรู้โครงสร้างข้างใน
+type Cursor struct {
+	SortField     string `json:"f"`
+	SortDirection string `json:"d"`
+	Value         string `json:"v,omitempty"`
+	ID            uint   `json:"id"`
+	// Backward = true คือ cursor สำหรับย้อนกลับไปหน้าก่อนหน้า
+	Backward bool `json:"b,omitempty"`
+}
+
+// Encode แปลง Cursor เป็น string สำหรับส่งให้ Client
+func (c Cursor) Encode() string {
+	jsonBytes, _ := json.Marshal(c) // struct นี้ marshal ไม่มีทาง error
+	return base64.RawURLEncoding.EncodeToString(jsonBytes)
+}
+
+// DecodeCursor แปลง string ที่ Client ส่งมากลับเป็น Cursor
+func DecodeCursor(encoded string) (*Cursor, error) {
+	jsonBytes, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var c Cursor
+	if err := json.Unmarshal(jsonBytes, &c); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if c.SortField == "" || c.ID == 0 {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+// CursorPage คือผลลัพธ์ด้าน "การนำทาง" ของการดึงข้อมูลแบบ cursor หนึ่งหน้า
+type CursorPage struct {
+	NextCursor  string // ว่าง = ไม่มีหน้าถัดไป
+	PrevCursor  string // ว่าง = ไม่มีหน้าก่อนหน้า
+	HasMore     bool
+	HasPrevious bool
+}
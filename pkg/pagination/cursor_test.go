@@ -0,0 +1,57 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		cursor Cursor
+	}{
+		{name: "forward by id", cursor: Cursor{SortField: "id", SortDirection: "asc", ID: 42}},
+		{name: "forward by name", cursor: Cursor{SortField: "name", SortDirection: "desc", Value: "สมชาย ใจดี", ID: 7}},
+		{name: "backward by created_at", cursor: Cursor{SortField: "created_at", SortDirection: "asc", Value: "2025-01-02T03:04:05.123456789Z", ID: 9, Backward: true}},
+		{name: "value with url-unsafe characters", cursor: Cursor{SortField: "email", SortDirection: "asc", Value: "a+b/c?d=e&f@example.com", ID: 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoded, err := DecodeCursor(tt.cursor.Encode())
+			if err != nil {
+				t.Fatalf("DecodeCursor() error = %v", err)
+			}
+			if *decoded != tt.cursor {
+				t.Errorf("DecodeCursor() = %+v, want %+v", *decoded, tt.cursor)
+			}
+		})
+	}
+}
+
+func TestDecodeCursorRejectsMalformedInput(t *testing.T) {
+	encode := func(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) }
+
+	tests := []struct {
+		name    string
+		encoded string
+	}{
+		{name: "empty", encoded: ""},
+		{name: "not base64", encoded: "!!!not-base64!!!"},
+		{name: "padded standard base64", encoded: base64.StdEncoding.EncodeToString([]byte(`{"f":"id","d":"asc","id":1}`)) + "="},
+		{name: "base64 of garbage", encoded: encode("garbage")},
+		{name: "json array", encoded: encode(`[1,2,3]`)},
+		{name: "missing sort field", encoded: encode(`{"d":"asc","id":1}`)},
+		{name: "missing id", encoded: encode(`{"f":"id","d":"asc"}`)},
+		{name: "zero id", encoded: encode(`{"f":"id","d":"asc","id":0}`)},
+		{name: "negative id", encoded: encode(`{"f":"id","d":"asc","id":-1}`)},
+		{name: "id of the wrong type", encoded: encode(`{"f":"id","d":"asc","id":"1"}`)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := DecodeCursor(tt.encoded); !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("DecodeCursor(%q) error = %v, want %v", tt.encoded, err, ErrInvalidCursor)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package postgres
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// sessionContextKey / primaryContextKey คือ key แบบ private สำหรับเก็บสถานะการเลือก DB ไว้ใน context
+type sessionContextKey struct{}
+type primaryContextKey struct{}
+
+// SessionContextKey คือ key ที่ Middleware ใช้ฝาก Session ไว้กับ request
+// (fiber.Ctx เป็น context.Context และ Value() จะอ่านจาก Locals ให้เรา)
+var SessionContextKey = sessionContextKey{}
+
+// Session จำว่างานนี้ (ปกติคือ 1 HTTP request) เขียนลง primary ไปแล้วหรือยัง
+// เมื่อเขียนแล้ว การอ่านหลังจากนั้นที่ใช้ ctx เดียวกันจะไปที่ primary แทน replica
+// เพื่อไม่ให้อ่านได้ข้อมูลเก่าที่ replica ยังตามไม่ทัน (read-your-writes)
+type Session struct {
+	wrote atomic.Bool
+}
+
+// NewSession สร้าง Session ใหม่ (ยังไม่เคยเขียน)
+func NewSession() *Session {
+	return &Session{}
+}
+
+// Wrote บอกว่างานนี้เขียนลง primary ไปแล้วหรือยัง
+func (s *Session) Wrote() bool {
+	return s.wrote.Load()
+}
+
+// WithSession คืน context ใหม่ที่มี Session (ใช้กับงานที่ไม่ได้มาจาก HTTP request เช่น background job)
+func WithSession(ctx context.Context) context.Context {
+	return context.WithValue(ctx, SessionContextKey, NewSession())
+}
+
+// WithPrimary คืน context ที่บังคับให้ทุก query ใช้ primary
+// ใช้กับการอ่านที่ต้องได้ค่าล่าสุดเสมอ เช่น ตรวจ token ซ้ำ หรือเช็ค idempotency key
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryContextKey{}, true)
+}
+
+func sessionFromContext(ctx context.Context) *Session {
+	if ctx == nil {
+		return nil
+	}
+	session, _ := ctx.Value(SessionContextKey).(*Session)
+	return session
+}
+
+// mustUsePrimary บอกว่า query ที่ใช้ ctx นี้ห้ามไป replica
+func mustUsePrimary(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	if forced, _ := ctx.Value(primaryContextKey{}).(bool); forced {
+		return true
+	}
+	session := sessionFromContext(ctx)
+	return session != nil && session.Wrote()
+}
@@ -0,0 +1,198 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+
+	"go-template/pkg/config"
+	"go-template/pkg/logger"
+)
+
+// DefaultSlowThreshold คือเวลาที่ถือว่า query ช้าเมื่อไม่ได้ตั้งค่าไว้
+const DefaultSlowThreshold = 200 * time.Millisecond
+
+// QueryLogMode คือระดับการ log SQL query (เรียงจากน้อยไปมาก)
+type QueryLogMode int
+
+const (
+	QueryLogOff    QueryLogMode = iota // ไม่ log query เลย (metrics ยังเก็บอยู่)
+	QueryLogErrors                     // เฉพาะ query ที่ error
+	QueryLogSlow                       // query ที่ error + query ที่ช้ากว่า threshold
+	QueryLogAll                        // ทุก query
+)
+
+// ParseQueryLogMode แปลงชื่อโหมด (off, errors, slow, all) เป็น QueryLogMode (ว่าง = slow)
+func ParseQueryLogMode(name string) (QueryLogMode, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "off":
+		return QueryLogOff, nil
+	case "errors":
+		return QueryLogErrors, nil
+	case "", "slow":
+		return QueryLogSlow, nil
+	case "all":
+		return QueryLogAll, nil
+	}
+	return QueryLogSlow, fmt.Errorf("unknown sql log mode %q (off, errors, slow, all)", name)
+}
+
+// gormLoggerAdapter คือ "หัวแปลงปลั๊ก" ที่ทำให้ Logger ของเราคุยกับ GORM ได้
+// นอกจาก log แล้วยังเก็บเวลาของทุก query ลง histogram (db_query_duration_seconds) ด้วย
+type gormLoggerAdapter struct {
+	appLogger     logger.Logger
+	redactor      *logger.Redactor // ใช้หาค่าใน query ที่เป็นคอลัมน์ลับ (nil = ไม่ปิด)
+	database      string           // ชื่อ connection ใช้เป็น label ของ metrics
+	mode          QueryLogMode
+	slowThreshold time.Duration
+}
+
+func newGormLogger(appLogger logger.Logger, database string, cfg config.SQLLogConfig, redactor *logger.Redactor) (*gormLoggerAdapter, error) {
+	mode, err := ParseQueryLogMode(cfg.Mode)
+	if err != nil {
+		return nil, err
+	}
+	slowThreshold := cfg.SlowThreshold
+	if slowThreshold <= 0 {
+		slowThreshold = DefaultSlowThreshold
+	}
+	return &gormLoggerAdapter{
+		appLogger:     appLogger,
+		redactor:      redactor,
+		database:      database,
+		mode:          mode,
+		slowThreshold: slowThreshold,
+	}, nil
+}
+
+// Implement gormlogger.Interface
+
+// LogMode คืนสำเนาที่เปลี่ยนโหมดตามระดับของ GORM (เช่น db.Debug() = ทุก query เฉพาะ session นั้น)
+func (l *gormLoggerAdapter) LogMode(level gormlogger.LogLevel) gormlogger.Interface {
+	clone := *l
+	switch level {
+	case gormlogger.Silent:
+		clone.mode = QueryLogOff
+	case gormlogger.Error:
+		clone.mode = QueryLogErrors
+	case gormlogger.Warn:
+		clone.mode = QueryLogSlow
+	case gormlogger.Info:
+		clone.mode = QueryLogAll
+	}
+	return &clone
+}
+
+func (l *gormLoggerAdapter) Info(ctx context.Context, msg string, data ...interface{}) {
+	if l.mode >= QueryLogAll {
+		l.appLogger.WithContext(ctx).Info(fmt.Sprintf(msg, data...))
+	}
+}
+
+func (l *gormLoggerAdapter) Warn(ctx context.Context, msg string, data ...interface{}) {
+	if l.mode >= QueryLogSlow {
+		l.appLogger.WithContext(ctx).Warn(fmt.Sprintf(msg, data...))
+	}
+}
+
+func (l *gormLoggerAdapter) Error(ctx context.Context, msg string, data ...interface{}) {
+	if l.mode >= QueryLogErrors {
+		l.appLogger.WithContext(ctx).Error(fmt.Sprintf(msg, data...), nil)
+	}
+}
+
+// Trace ถูกเรียกหลังทุก query: เก็บ metrics เสมอ แล้วค่อย log ตามโหมด
+// ⭐️ ctx คือ ctx ที่ส่งผ่าน db.WithContext(ctx) ใน repository จึงได้ request_id ติดมากับ log ด้วย
+func (l *gormLoggerAdapter) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	elapsed := time.Since(begin)
+	sql, rows := fc()
+	observeQuery(l.database, sql, elapsed, err)
+
+	if l.mode == QueryLogOff {
+		return
+	}
+	args := []any{"sql", sql, "rows", rows, "duration_ms", float64(elapsed.Microseconds()) / 1000}
+	switch {
+	// record not found เป็นเรื่องปกติของ GetByID/GetByEmail ไม่ใช่ error ของระบบ
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound):
+		l.appLogger.WithContext(ctx).Error("SQL query failed", err, args...)
+	case elapsed >= l.slowThreshold && l.mode >= QueryLogSlow:
+		args = append(args, "threshold_ms", l.slowThreshold.Milliseconds())
+		l.appLogger.WithContext(ctx).Warn("Slow SQL query", args...)
+	case l.mode >= QueryLogAll:
+		l.appLogger.WithContext(ctx).Debug("SQL query", args...)
+	}
+}
+
+// ParamsFilter ถูก GORM เรียกก่อนนำค่าไปแทนลงใน SQL ที่ส่งให้ Trace (implement gorm.ParamsFilter)
+// ค่าที่ผูกกับคอลัมน์ลับ (เช่น password, token_hash) จะถูกแทนด้วย [REDACTED] ส่วนค่าที่ส่งไปหา DB จริงไม่ถูกแตะ
+func (l *gormLoggerAdapter) ParamsFilter(ctx context.Context, sql string, params ...interface{}) (string, []interface{}) {
+	if l.redactor == nil || len(params) == 0 {
+		return sql, params
+	}
+	sensitive := sensitivePlaceholders(sql, l.redactor)
+	if len(sensitive) == 0 {
+		return sql, params
+	}
+	filtered := make([]interface{}, len(params))
+	for i, param := range params {
+		if sensitive[i+1] {
+			filtered[i] = logger.RedactedValue
+			continue
+		}
+		filtered[i] = param
+	}
+	return sql, filtered
+}
+
+var (
+	// "col" = $1, "col" <> $2, col LIKE $3 ...
+	comparisonPattern = regexp.MustCompile(`(?i)"?([a-z_][a-z0-9_]*)"?\s*(?:=|<>|!=|<=|>=|<|>|\bLIKE\b|\bILIKE\b)\s*\$(\d+)`)
+	// "col" IN ($1,$2,...)
+	inPattern = regexp.MustCompile(`(?i)"?([a-z_][a-z0-9_]*)"?\s+IN\s*\(([^)]*)\)`)
+	// INSERT INTO "table" ("a","b") VALUES ($1,$2),($3,$4)
+	insertPattern   = regexp.MustCompile(`(?is)^\s*INSERT\s+INTO\s+\S+\s*\(([^)]*)\)\s*VALUES\s*(.*)$`)
+	valuesPattern   = regexp.MustCompile(`\(([^)]*)\)`)
+	placeholderExpr = regexp.MustCompile(`\$(\d+)`)
+)
+
+// sensitivePlaceholders หาว่า placeholder ($n) ตัวไหนผูกกับคอลัมน์ที่ชื่อตรงกับ key pattern ของ Redactor
+func sensitivePlaceholders(sql string, redactor *logger.Redactor) map[int]bool {
+	sensitive := make(map[int]bool)
+	mark := func(column, placeholders string) {
+		if !redactor.IsSensitiveKey(column) {
+			return
+		}
+		for _, match := range placeholderExpr.FindAllStringSubmatch(placeholders, -1) {
+			if n, err := strconv.Atoi(match[1]); err == nil {
+				sensitive[n] = true
+			}
+		}
+	}
+
+	if match := insertPattern.FindStringSubmatch(sql); match != nil {
+		columns := strings.Split(match[1], ",")
+		for _, row := range valuesPattern.FindAllStringSubmatch(match[2], -1) {
+			values := strings.Split(row[1], ",")
+			for i, value := range values {
+				if i < len(columns) {
+					mark(strings.Trim(strings.TrimSpace(columns[i]), `"`), value)
+				}
+			}
+		}
+	}
+	for _, match := range comparisonPattern.FindAllStringSubmatch(sql, -1) {
+		mark(match[1], "$"+match[2])
+	}
+	for _, match := range inPattern.FindAllStringSubmatch(sql, -1) {
+		mark(match[1], match[2])
+	}
+	return sensitive
+}
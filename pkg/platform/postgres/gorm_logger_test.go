@@ -0,0 +1,120 @@
+package postgres
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"go-template/pkg/config"
+	"go-template/pkg/logger"
+)
+
+func TestSensitivePlaceholders(t *testing.T) {
+	tests := []struct {
+		name string
+		sql  string
+		want map[int]bool
+	}{
+		{
+			name: "insert single row",
+			sql:  `INSERT INTO "example_users" ("name","email","password_hash","created_at") VALUES ($1,$2,$3,$4) RETURNING "id"`,
+			want: map[int]bool{3: true},
+		},
+		{
+			name: "insert multiple rows",
+			sql:  `INSERT INTO "example_refresh_tokens" ("user_id","token_hash","expires_at") VALUES ($1,$2,$3),($4,$5,$6)`,
+			want: map[int]bool{2: true, 5: true},
+		},
+		{
+			name: "insert with on conflict",
+			sql:  `INSERT INTO "example_refresh_tokens" ("user_id","token_hash") VALUES ($1,$2) ON CONFLICT ("id") DO UPDATE SET "token_hash"="excluded"."token_hash"`,
+			want: map[int]bool{2: true},
+		},
+		{
+			name: "update set and where",
+			sql:  `UPDATE "example_users" SET "password"=$1,"updated_at"=$2 WHERE "id" = $3 AND "example_users"."deleted_at" IS NULL`,
+			want: map[int]bool{1: true},
+		},
+		{
+			name: "qualified column in where",
+			sql:  `SELECT * FROM "example_refresh_tokens" WHERE "example_refresh_tokens"."token_hash" = $1 LIMIT $2`,
+			want: map[int]bool{1: true},
+		},
+		{
+			name: "in list",
+			sql:  `DELETE FROM "example_refresh_tokens" WHERE "token_hash" IN ($1,$2,$3) AND "user_id" IN ($4,$5)`,
+			want: map[int]bool{1: true, 2: true, 3: true},
+		},
+		{
+			name: "comparison operators",
+			sql:  `SELECT * FROM "sessions" WHERE secret <> $1 AND "api_key" != $2 AND token LIKE $3 AND cookie ILIKE $4 AND "id" >= $5`,
+			want: map[int]bool{1: true, 2: true, 3: true, 4: true},
+		},
+		{
+			name: "case insensitive keywords",
+			sql:  `select * from users where Password = $1 and email in ($2)`,
+			want: map[int]bool{1: true},
+		},
+		{
+			name: "no sensitive columns",
+			sql:  `SELECT * FROM "example_users" WHERE "email" = $1 AND "name" ILIKE $2 ORDER BY "id" LIMIT $3`,
+			want: map[int]bool{},
+		},
+		{
+			name: "no placeholders",
+			sql:  `SELECT count(*) FROM "example_users"`,
+			want: map[int]bool{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := sensitivePlaceholders(tt.sql, logger.DefaultRedactor())
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("sensitivePlaceholders() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParamsFilter(t *testing.T) {
+	const sql = `UPDATE "example_users" SET "email"=$1,"password_hash"=$2 WHERE "id" = $3`
+	params := []interface{}{"john@example.com", "$2a$10$hash", 7}
+
+	tests := []struct {
+		name     string
+		redactor *logger.Redactor
+		want     []interface{}
+	}{
+		{
+			name:     "sensitive params are replaced",
+			redactor: logger.DefaultRedactor(),
+			want:     []interface{}{"john@example.com", logger.RedactedValue, 7},
+		},
+		{
+			name:     "custom keys",
+			redactor: logger.NewRedactor(config.RedactionConfig{Keys: []string{"email"}}),
+			want:     []interface{}{logger.RedactedValue, "$2a$10$hash", 7},
+		},
+		{
+			name:     "redaction disabled",
+			redactor: logger.NewRedactor(config.RedactionConfig{Disabled: true}),
+			want:     params,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := &gormLoggerAdapter{redactor: tt.redactor}
+			gotSQL, got := l.ParamsFilter(context.Background(), sql, params...)
+			if gotSQL != sql {
+				t.Errorf("ParamsFilter() changed the SQL to %q", gotSQL)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParamsFilter() params = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if params[1] != "$2a$10$hash" {
+		t.Error("ParamsFilter() must not modify the caller's params")
+	}
+}
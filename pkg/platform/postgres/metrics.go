@@ -0,0 +1,63 @@
+package postgres
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"gorm.io/gorm"
+
+	"go-template/pkg/metrics"
+)
+
+// queryDuration คือ histogram เวลาของ SQL query แยกตาม database, ชนิดคำสั่ง และตาราง
+// ⭐️ ไม่ใช้ตัว SQL เป็น label เพราะค่าจะไม่ซ้ำกันเลย (Prometheus จะบวมจนล่ม)
+var queryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "db_query_duration_seconds",
+	Help:    "Duration of SQL statements executed through GORM.",
+	Buckets: []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5},
+}, []string{"database", "operation", "table", "status"})
+
+func init() {
+	metrics.Registry.MustRegister(queryDuration)
+}
+
+var tablePatterns = map[string]*regexp.Regexp{
+	"SELECT": regexp.MustCompile(`(?is)\bFROM\s+"?([a-z0-9_]+)"?`),
+	"DELETE": regexp.MustCompile(`(?is)\bFROM\s+"?([a-z0-9_]+)"?`),
+	"INSERT": regexp.MustCompile(`(?is)\bINTO\s+"?([a-z0-9_]+)"?`),
+	"UPDATE": regexp.MustCompile(`(?is)^\s*UPDATE\s+"?([a-z0-9_]+)"?`),
+}
+
+// observeQuery บันทึกเวลาของ query 1 ครั้งลง histogram
+func observeQuery(database, sql string, elapsed time.Duration, err error) {
+	operation, table := classifyStatement(sql)
+	status := "ok"
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		status = "not_found"
+	case err != nil:
+		status = "error"
+	}
+	queryDuration.WithLabelValues(database, operation, table, status).Observe(elapsed.Seconds())
+}
+
+// classifyStatement แยกชนิดคำสั่งและตารางหลักออกจาก SQL
+// คำสั่งอื่นนอกจาก SELECT/INSERT/UPDATE/DELETE (BEGIN, SAVEPOINT, ...) ถูกรวมไว้เป็น OTHER
+func classifyStatement(sql string) (operation, table string) {
+	fields := strings.Fields(sql)
+	if len(fields) == 0 {
+		return "OTHER", ""
+	}
+	operation = strings.ToUpper(fields[0])
+	pattern, ok := tablePatterns[operation]
+	if !ok {
+		return "OTHER", ""
+	}
+	if match := pattern.FindStringSubmatch(sql); match != nil {
+		table = match[1]
+	}
+	return operation, table
+}
@@ -1,7 +1,6 @@
 package postgres
 
 import (
-	"context"
 	"fmt"
 	"time"
 
@@ -13,55 +12,111 @@ import (
 	"go-template/pkg/logger"
 )
 
-// gormLoggerAdapter คือ "หัวแปลงปลั๊ก" ที่ทำให้ Logger ของเราคุยกับ GORM ได้
-type gormLoggerAdapter struct {
-	appLogger logger.Logger
-}
-
-// Implement gormlogger.Interface
-func (l *gormLoggerAdapter) LogMode(level gormlogger.LogLevel) gormlogger.Interface {
-	return l // เราจะจัดการ level เองข้างล่าง
-}
-func (l *gormLoggerAdapter) Info(ctx context.Context, msg string, data ...interface{}) {
-	l.appLogger.Info(fmt.Sprintf(msg, data...))
-}
-func (l *gormLoggerAdapter) Warn(ctx context.Context, msg string, data ...interface{}) {
-	l.appLogger.Warn(fmt.Sprintf(msg, data...))
-}
-func (l *gormLoggerAdapter) Error(ctx context.Context, msg string, data ...interface{}) {
-	l.appLogger.Error(fmt.Sprintf(msg, data...), nil)
-}
-func (l *gormLoggerAdapter) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
-	// เราสามารถเพิ่ม Logic การ log SQL query ที่นี่ได้ถ้าต้องการ
-}
+// maxConnectBackoff คือเวลารอสูงสุดระหว่างการลองเชื่อมต่อแต่ละครั้ง
+const maxConnectBackoff = 30 * time.Second
 
 // NewConnection คือ Public Function ของเรา
 // ✨ 2. แก้ไขให้รับ appLogger เข้ามาด้วย ✨
-func NewConnection(cfg config.PostgresConfig, appLogger logger.Logger) (*gorm.DB, error) {
-	dsn := cfg.BuildDSN()
+// name คือชื่อ connection ใน config (เช่น "primary") ใช้ใน log และเป็น label ของ metrics
+// logCfg ใช้ตั้งค่าการ log SQL (logCfg.SQL) และการปิดค่าลับใน query (logCfg.Redaction)
+// ถ้า DB ยังไม่พร้อมจะลองใหม่ตาม cfg.ConnectAttempts/ConnectBackoff ก่อนยอมแพ้
+// ปกติไม่ต้องเรียกเอง ให้ใช้ผ่าน platform.Registry (ถ้าเรียกเอง ต้องปิดด้วย Close ไม่ใช่ sql.DB.Close เพื่อปิด replica ด้วย)
+func NewConnection(name string, cfg config.PostgresConfig, logCfg config.LoggingConfig, appLogger logger.Logger) (*gorm.DB, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid postgres config for %q: %w", name, err)
+	}
+	cfg = cfg.WithDefaults()
+	dsn := cfg.BuildAppDSN()
 
 	// ✨ 3. สร้าง GORM Logger ที่ใช้ "หัวแปลงปลั๊ก" ของเรา ✨
-	newLogger := &gormLoggerAdapter{appLogger: appLogger}
+	newLogger, err := newGormLogger(appLogger, name, logCfg.SQL, logger.NewRedactor(logCfg.Redaction))
+	if err != nil {
+		return nil, err
+	}
 
 	gormConfig := &gorm.Config{
-		Logger: newLogger.LogMode(gormlogger.Info), // ตั้งค่าให้ GORM ใช้ Logger ใหม่ของเรา
+		Logger: newLogger, // ตั้งค่าให้ GORM ใช้ Logger ใหม่ของเรา (โหมดตาม logging.sql)
+		// ให้ GORM แปลง error ของ Postgres (เช่น unique/foreign key violation)
+		// เป็น gorm.ErrDuplicatedKey / gorm.ErrForeignKeyViolated ที่เช็คด้วย errors.Is ได้
+		TranslateError: true,
 	}
 
-	db, err := gorm.Open(postgres.Open(dsn), gormConfig)
+	// ระหว่างลองเชื่อมต่อให้ GORM เงียบไว้ก่อน (openWithRetry รายงานเองแล้ว ไม่งั้น error จะออกซ้ำทุกครั้งที่ลอง)
+	gormConfig.Logger = newLogger.LogMode(gormlogger.Silent)
+	db, err := openWithRetry(name, dsn, gormConfig, cfg, appLogger)
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to postgres: %w", err)
+		return nil, err
 	}
+	db.Logger = newLogger
 
 	sqlDB, err := db.DB()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get underlying sql.DB: %w", err)
 	}
 
-	sqlDB.SetMaxOpenConns(25)
-	sqlDB.SetMaxIdleConns(25)
-	sqlDB.SetConnMaxLifetime(5 * time.Minute)
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	sqlDB.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+	sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+
+	// Read replicas (ถ้ามี): query อ่านอย่างเดียวจะถูกส่งไปที่ replica ผ่าน GORM plugin
+	if len(cfg.Replicas) > 0 {
+		replicas, err := newReplicaSet(name, cfg, appLogger)
+		if err != nil {
+			_ = sqlDB.Close()
+			return nil, err
+		}
+		if err := db.Use(replicas); err != nil {
+			_ = replicas.Close()
+			_ = sqlDB.Close()
+			return nil, fmt.Errorf("failed to register read replicas for %q: %w", name, err)
+		}
+	}
 
-	appLogger.Info("Successfully connected to PostgreSQL", "host", cfg.Host, "dbName", cfg.DBName)
+	appLogger.Info("Successfully connected to PostgreSQL",
+		"name", name,
+		"host", cfg.Host,
+		"dbName", cfg.DBName,
+		"maxOpenConns", cfg.MaxOpenConns,
+		"maxIdleConns", cfg.MaxIdleConns,
+		"statementTimeout", cfg.StatementTimeout.String(),
+		"replicas", len(cfg.Replicas),
+	)
 
 	return db, nil
 }
+
+// openWithRetry เปิด connection (GORM จะ Ping ให้ด้วย) ถ้าไม่สำเร็จจะรอแล้วลองใหม่ โดยรอนานขึ้นเป็น 2 เท่าทุกครั้ง
+func openWithRetry(name, dsn string, gormConfig *gorm.Config, cfg config.PostgresConfig, appLogger logger.Logger) (*gorm.DB, error) {
+	backoff := cfg.ConnectBackoff
+	var lastErr error
+	for attempt := 1; attempt <= cfg.ConnectAttempts; attempt++ {
+		db, err := gorm.Open(postgres.Open(dsn), gormConfig)
+		if err == nil {
+			return db, nil
+		}
+		lastErr = err
+		// Ping ไม่ผ่านแต่ pool ถูกเปิดไว้แล้ว ต้องปิดทิ้งก่อนลองใหม่
+		if db != nil {
+			if sqlDB, dbErr := db.DB(); dbErr == nil {
+				_ = sqlDB.Close()
+			}
+		}
+		if attempt == cfg.ConnectAttempts {
+			break
+		}
+
+		appLogger.Warn("PostgreSQL is not ready, retrying",
+			"name", name,
+			"host", cfg.Host,
+			"dbName", cfg.DBName,
+			"attempt", attempt,
+			"maxAttempts", cfg.ConnectAttempts,
+			"retryIn", backoff.String(),
+			"error", err.Error(),
+		)
+		time.Sleep(backoff)
+		backoff = min(backoff*2, maxConnectBackoff)
+	}
+	return nil, fmt.Errorf("failed to connect to postgres %q after %d attempt(s): %w", name, cfg.ConnectAttempts, lastErr)
+}
@@ -0,0 +1,335 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+
+	"go-template/pkg/config"
+	"go-template/pkg/logger"
+	"go-template/pkg/metrics"
+)
+
+// replicaPluginName คือชื่อที่ ReplicaSet ลงทะเบียนไว้ใน gorm.Config.Plugins
+const replicaPluginName = "app:read_replicas"
+
+// สถานะของ replica ใน health check
+const (
+	ReplicaStatusOK      = "ok"
+	ReplicaStatusError   = "error"
+	ReplicaStatusLagging = "lagging"
+	ReplicaStatusPending = "pending" // ยังไม่ได้ตรวจครั้งแรก
+)
+
+// lagQuery วัดว่า replica ตามหลัง primary อยู่กี่วินาที
+// ถ้า replay ทัน WAL ที่ได้รับมาแล้วถือว่า lag = 0 (ไม่งั้นตอน primary ว่างๆ ไม่มีใครเขียน lag จะดูเหมือนเพิ่มขึ้นเรื่อยๆ)
+const lagQuery = `SELECT CASE
+	WHEN NOT pg_is_in_recovery() THEN 0
+	WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+	ELSE COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), 0)
+END`
+
+var replicaLag = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "db_replica_lag_seconds",
+	Help: "Replication lag of each read replica as seen by the last health check.",
+}, []string{"database", "replica"})
+
+func init() {
+	metrics.Registry.MustRegister(replicaLag)
+}
+
+// ReplicaStatus คือสถานะล่าสุดของ replica 1 ตัว (ใช้แสดงใน health check)
+type ReplicaStatus struct {
+	Name       string     `json:"name"`
+	Status     string     `json:"status"`
+	LagSeconds *float64   `json:"lag_seconds,omitempty"`
+	Error      string     `json:"error,omitempty"`
+	CheckedAt  *time.Time `json:"checked_at,omitempty"`
+}
+
+type replica struct {
+	name    string
+	db      *sql.DB
+	healthy atomic.Bool
+
+	mu     sync.RWMutex
+	status ReplicaStatus
+}
+
+// ReplicaSet คือ GORM plugin ที่ส่ง query อ่านอย่างเดียวไปที่ read replica
+// - SELECT ที่ไม่ได้อยู่ใน Transaction และไม่ได้ล็อกแถว (FOR UPDATE) จะไปที่ replica แบบ round-robin
+// - SQL ที่เขียนเองผ่าน db.Raw(...) ใช้ primary เสมอ เพราะดูไม่ออกว่าอ่านหรือเขียน
+// - replica ที่ ping ไม่ผ่านหรือ lag เกิน ReplicaMaxLag จะถูกข้าม ถ้าไม่เหลือสักตัวจะใช้ primary
+// - การเขียนและ Transaction ใช้ primary เสมอ
+// - ถ้า request เดียวกันเพิ่งเขียนไป การอ่านหลังจากนั้นจะใช้ primary (ดู WithSession) เพื่อให้อ่านเจอสิ่งที่เพิ่งเขียน
+type ReplicaSet struct {
+	database string
+	maxLag   time.Duration
+	interval time.Duration
+	log      logger.Logger
+
+	replicas []*replica
+	next     atomic.Uint64
+
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// newReplicaSet เปิด pool ของทุก replica (ไม่รอให้ต่อได้) แล้วเริ่มตรวจสุขภาพเป็นระยะ
+// replica ทุกตัวเริ่มต้นเป็น "ยังไม่พร้อม" การอ่านจะใช้ primary ไปก่อนจนกว่าการตรวจครั้งแรกจะผ่าน
+func newReplicaSet(name string, cfg config.PostgresConfig, appLogger logger.Logger) (*ReplicaSet, error) {
+	s := &ReplicaSet{
+		database: name,
+		maxLag:   cfg.ReplicaMaxLag,
+		interval: cfg.ReplicaCheckInterval,
+		log:      appLogger,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	for i := range cfg.Replicas {
+		dsn, label, err := cfg.BuildReplicaDSN(i)
+		if err != nil {
+			_ = s.closeReplicas()
+			return nil, fmt.Errorf("replica %d of %q: %w", i, name, err)
+		}
+		gormDB, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+			Logger:               gormlogger.Discard, // ใช้แค่ pool ส่วน query จริงจะถูก log ผ่าน Logger ของ primary
+			DisableAutomaticPing: true,
+		})
+		if err != nil {
+			_ = s.closeReplicas()
+			return nil, fmt.Errorf("replica %q of %q: %w", label, name, err)
+		}
+		sqlDB, err := gormDB.DB()
+		if err != nil {
+			_ = s.closeReplicas()
+			return nil, fmt.Errorf("replica %q of %q: %w", label, name, err)
+		}
+		sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+		sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+		sqlDB.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+		sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+
+		r := &replica{name: label, db: sqlDB}
+		r.status = ReplicaStatus{Name: label, Status: ReplicaStatusPending}
+		s.replicas = append(s.replicas, r)
+	}
+	go s.run()
+	return s, nil
+}
+
+// Name implements gorm.Plugin
+func (s *ReplicaSet) Name() string {
+	return replicaPluginName
+}
+
+// Initialize implements gorm.Plugin: ลงทะเบียน callback สำหรับเลือก connection และจำว่า request เขียนไปแล้ว
+func (s *ReplicaSet) Initialize(db *gorm.DB) error {
+	callbacks := db.Callback()
+	if err := callbacks.Query().Before("gorm:query").Register("app:replica_read", s.routeRead); err != nil {
+		return err
+	}
+	if err := callbacks.Row().Before("gorm:row").Register("app:replica_read", s.routeRead); err != nil {
+		return err
+	}
+	if err := callbacks.Create().After("gorm:create").Register("app:replica_mark_write", markWrite); err != nil {
+		return err
+	}
+	if err := callbacks.Update().After("gorm:update").Register("app:replica_mark_write", markWrite); err != nil {
+		return err
+	}
+	if err := callbacks.Delete().After("gorm:delete").Register("app:replica_mark_write", markWrite); err != nil {
+		return err
+	}
+	// db.Exec(...) เดาไม่ได้ว่าเขียนหรือเปล่า จึงถือว่าเขียนไว้ก่อน (และรันที่ primary เสมอ)
+	return callbacks.Raw().After("gorm:raw").Register("app:replica_mark_write", markWrite)
+}
+
+// Status คืนสถานะล่าสุดของทุก replica
+func (s *ReplicaSet) Status() []ReplicaStatus {
+	statuses := make([]ReplicaStatus, len(s.replicas))
+	for i, r := range s.replicas {
+		r.mu.RLock()
+		statuses[i] = r.status
+		r.mu.RUnlock()
+	}
+	return statuses
+}
+
+// Close หยุดการตรวจสุขภาพและปิด pool ของทุก replica
+func (s *ReplicaSet) Close() error {
+	var err error
+	s.closeOnce.Do(func() {
+		close(s.stop)
+		<-s.done
+		err = s.closeReplicas()
+	})
+	return err
+}
+
+// routeRead เลือก replica ให้ query นี้ (ถ้าเลือกได้)
+func (s *ReplicaSet) routeRead(db *gorm.DB) {
+	stmt := db.Statement
+	if db.Error != nil || stmt.ConnPool == nil {
+		return
+	}
+	// db.Raw(...) สร้าง SQL ไว้ก่อนถึง callback และอาจเป็นการเขียน (เช่น UPDATE ... RETURNING)
+	// จึงถือเหมือน db.Exec: ใช้ primary และนับว่า request นี้เขียนไปแล้ว
+	if stmt.SQL.Len() > 0 {
+		markWrite(db)
+		return
+	}
+	if _, inTransaction := stmt.ConnPool.(gorm.TxCommitter); inTransaction {
+		return
+	}
+	if _, locking := stmt.Clauses["FOR"]; locking {
+		return
+	}
+	if mustUsePrimary(stmt.Context) {
+		return
+	}
+	if r := s.pick(); r != nil {
+		stmt.ConnPool = r.db
+	}
+}
+
+// pick เลือก replica ตัวถัดไปแบบ round-robin โดยข้ามตัวที่ไม่พร้อม (nil = ไม่มีตัวไหนพร้อม)
+func (s *ReplicaSet) pick() *replica {
+	n := uint64(len(s.replicas))
+	if n == 0 {
+		return nil
+	}
+	start := s.next.Add(1)
+	for i := uint64(0); i < n; i++ {
+		r := s.replicas[(start+i)%n]
+		if r.healthy.Load() {
+			return r
+		}
+	}
+	return nil
+}
+
+// markWrite จำไว้ว่า request นี้เขียนลง primary แล้ว
+func markWrite(db *gorm.DB) {
+	if db.Error != nil || db.Statement.SQL.Len() == 0 {
+		return
+	}
+	if session := sessionFromContext(db.Statement.Context); session != nil {
+		session.wrote.Store(true)
+	}
+}
+
+// run ตรวจทุก replica ทันที แล้วตรวจซ้ำทุก interval จนกว่าจะถูก Close
+func (s *ReplicaSet) run() {
+	defer close(s.done)
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		s.checkAll()
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *ReplicaSet) checkAll() {
+	var wg sync.WaitGroup
+	for _, r := range s.replicas {
+		wg.Add(1)
+		go func(r *replica) {
+			defer wg.Done()
+			s.check(r)
+		}(r)
+	}
+	wg.Wait()
+}
+
+// check ping และวัด lag ของ replica 1 ตัว แล้วอัปเดตสถานะ (รายงานเฉพาะตอนที่สถานะเปลี่ยน)
+func (s *ReplicaSet) check(r *replica) {
+	timeout := s.interval
+	if timeout > 2*time.Second {
+		timeout = 2 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	now := time.Now()
+	status := ReplicaStatus{Name: r.name, CheckedAt: &now}
+	var lag float64
+	err := r.db.QueryRowContext(ctx, lagQuery).Scan(&lag)
+	switch {
+	case err != nil:
+		status.Status = ReplicaStatusError
+		status.Error = err.Error()
+	case s.maxLag > 0 && lag > s.maxLag.Seconds():
+		status.Status = ReplicaStatusLagging
+		status.LagSeconds = &lag
+		status.Error = fmt.Sprintf("lag %.3fs exceeds %s", lag, s.maxLag)
+	default:
+		status.Status = ReplicaStatusOK
+		status.LagSeconds = &lag
+	}
+	if status.LagSeconds != nil {
+		replicaLag.WithLabelValues(s.database, r.name).Set(lag)
+	}
+
+	r.mu.Lock()
+	firstCheck := r.status.Status == ReplicaStatusPending
+	r.status = status
+	r.mu.Unlock()
+
+	healthy := status.Status == ReplicaStatusOK
+	if was := r.healthy.Swap(healthy); was != healthy || (firstCheck && !healthy) {
+		if healthy {
+			s.log.Info("Read replica is available", "database", s.database, "replica", r.name, "lag_seconds", lag)
+		} else {
+			s.log.Warn("Read replica is unavailable, reads will skip it", "database", s.database, "replica", r.name, "status", status.Status, "error", status.Error)
+		}
+	}
+}
+
+func (s *ReplicaSet) closeReplicas() error {
+	var errs []error
+	for _, r := range s.replicas {
+		if err := r.db.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("close replica %q: %w", r.name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Replicas คืน ReplicaSet ของ connection นี้ (nil = ไม่ได้ตั้ง replica ไว้)
+func Replicas(db *gorm.DB) *ReplicaSet {
+	if db == nil {
+		return nil
+	}
+	set, _ := db.Config.Plugins[replicaPluginName].(*ReplicaSet)
+	return set
+}
+
+// Close ปิด connection ที่ได้จาก NewConnection ทั้งหมด (replica + primary)
+func Close(db *gorm.DB) error {
+	var errs []error
+	if set := Replicas(db); set != nil {
+		errs = append(errs, set.Close())
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		errs = append(errs, err)
+	} else {
+		errs = append(errs, sqlDB.Close())
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,220 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"go-template/pkg/logger"
+)
+
+type replicaTestRow struct {
+	ID   uint
+	Name string
+}
+
+// replicatedFakeDB คือ primary กับ replica ที่ต่อกับ fake driver แยกกัน เพื่อดูว่าแต่ละ query ไปลงที่ไหน
+type replicatedFakeDB struct {
+	db       *gorm.DB
+	set      *ReplicaSet
+	primary  *recorder
+	replicas []*recorder
+}
+
+func newReplicatedFakeDB(t *testing.T, replicas int) *replicatedFakeDB {
+	t.Helper()
+	db, primary := newFakeDB(t)
+	f := &replicatedFakeDB{
+		db:      db,
+		primary: primary,
+		set:     &ReplicaSet{database: "primary", log: logger.NewSlogLogger()},
+	}
+	for i := 0; i < replicas; i++ {
+		rec := &recorder{}
+		r := &replica{name: fmt.Sprintf("replica-%d", i+1), db: sql.OpenDB(fakeConnector{rec: rec})}
+		r.healthy.Store(true)
+		f.set.replicas = append(f.set.replicas, r)
+		f.replicas = append(f.replicas, rec)
+	}
+	if err := db.Use(f.set); err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+// servedBy รัน fn แล้วบอกว่าคำสั่งไปลงที่ connection ไหนบ้าง ("primary", "replica-1", ...)
+func (f *replicatedFakeDB) servedBy(t *testing.T, fn func(db *gorm.DB) error) string {
+	t.Helper()
+	recorders := append([]*recorder{f.primary}, f.replicas...)
+	before := make([]int, len(recorders))
+	for i, rec := range recorders {
+		before[i] = len(rec.log())
+	}
+	if err := fn(f.db); err != nil && !errors.Is(err, sql.ErrNoRows) {
+		t.Fatal(err)
+	}
+	var served []string
+	for i, rec := range recorders {
+		if len(rec.log()) > before[i] {
+			if i == 0 {
+				served = append(served, "primary")
+			} else {
+				served = append(served, fmt.Sprintf("replica-%d", i))
+			}
+		}
+	}
+	return strings.Join(served, ",")
+}
+
+func findRows(ctx context.Context) func(db *gorm.DB) error {
+	return func(db *gorm.DB) error {
+		var rows []replicaTestRow
+		return db.WithContext(ctx).Find(&rows).Error
+	}
+}
+
+func createRow(ctx context.Context) func(db *gorm.DB) error {
+	return func(db *gorm.DB) error {
+		return db.WithContext(ctx).Create(&replicaTestRow{Name: "a"}).Error
+	}
+}
+
+func TestReplicaSetRouting(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name string
+		run  func(db *gorm.DB) error
+		want string
+	}{
+		{name: "find", run: findRows(ctx), want: "replica"},
+		{name: "count", run: func(db *gorm.DB) error {
+			var n int64
+			return db.WithContext(ctx).Model(&replicaTestRow{}).Count(&n).Error
+		}, want: "replica"},
+		{name: "rows", run: func(db *gorm.DB) error {
+			rows, err := db.WithContext(ctx).Model(&replicaTestRow{}).Select("name").Rows()
+			if err != nil {
+				return err
+			}
+			return rows.Close()
+		}, want: "replica"},
+		{name: "create", run: createRow(ctx), want: "primary"},
+		{name: "exec", run: func(db *gorm.DB) error {
+			return db.WithContext(ctx).Exec("UPDATE replica_test_rows SET name = ?", "b").Error
+		}, want: "primary"},
+		{name: "locking read", run: func(db *gorm.DB) error {
+			var rows []replicaTestRow
+			return db.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).Find(&rows).Error
+		}, want: "primary"},
+		{name: "read in transaction", run: func(db *gorm.DB) error {
+			return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+				var rows []replicaTestRow
+				return tx.Find(&rows).Error
+			})
+		}, want: "primary"},
+		{name: "forced primary", run: findRows(WithPrimary(ctx)), want: "primary"},
+		{name: "raw scan", run: func(db *gorm.DB) error {
+			var ids []uint
+			return db.WithContext(ctx).Raw("UPDATE replica_test_rows SET name = ? RETURNING id", "b").Scan(&ids).Error
+		}, want: "primary"},
+		{name: "raw find", run: func(db *gorm.DB) error {
+			var rows []replicaTestRow
+			return db.WithContext(ctx).Raw("SELECT * FROM replica_test_rows").Find(&rows).Error
+		}, want: "primary"},
+		{name: "raw row", run: func(db *gorm.DB) error {
+			var id uint
+			return db.WithContext(ctx).Raw("DELETE FROM replica_test_rows RETURNING id").Row().Scan(&id)
+		}, want: "primary"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newReplicatedFakeDB(t, 1)
+			got := f.servedBy(t, tt.run)
+			if tt.want == "replica" {
+				tt.want = "replica-1"
+			}
+			if got != tt.want {
+				t.Errorf("served by %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReplicaSetRoundRobinSkipsUnhealthyReplicas(t *testing.T) {
+	ctx := context.Background()
+	f := newReplicatedFakeDB(t, 3)
+
+	seen := map[string]int{}
+	for i := 0; i < 6; i++ {
+		seen[f.servedBy(t, findRows(ctx))]++
+	}
+	if seen["replica-1"] != 2 || seen["replica-2"] != 2 || seen["replica-3"] != 2 {
+		t.Errorf("reads = %v, want 2 on each replica", seen)
+	}
+
+	f.set.replicas[1].healthy.Store(false)
+	for i := 0; i < 4; i++ {
+		if got := f.servedBy(t, findRows(ctx)); got == "replica-2" || got == "primary" {
+			t.Fatalf("read %d served by %q, want a healthy replica", i, got)
+		}
+	}
+
+	for _, r := range f.set.replicas {
+		r.healthy.Store(false)
+	}
+	if got := f.servedBy(t, findRows(ctx)); got != "primary" {
+		t.Errorf("no healthy replica: served by %q, want primary", got)
+	}
+}
+
+func TestReplicaSetReadYourWrites(t *testing.T) {
+	t.Run("reads after a write in the same session use primary", func(t *testing.T) {
+		f := newReplicatedFakeDB(t, 1)
+		ctx := WithSession(context.Background())
+
+		if got := f.servedBy(t, findRows(ctx)); got != "replica-1" {
+			t.Fatalf("read before write served by %q, want replica-1", got)
+		}
+		if got := f.servedBy(t, createRow(ctx)); got != "primary" {
+			t.Fatalf("write served by %q, want primary", got)
+		}
+		if got := f.servedBy(t, findRows(ctx)); got != "primary" {
+			t.Errorf("read after write served by %q, want primary", got)
+		}
+		if got := f.servedBy(t, findRows(WithSession(context.Background()))); got != "replica-1" {
+			t.Errorf("read in another session served by %q, want replica-1", got)
+		}
+	})
+
+	t.Run("raw statements count as writes", func(t *testing.T) {
+		f := newReplicatedFakeDB(t, 1)
+		ctx := WithSession(context.Background())
+
+		f.servedBy(t, func(db *gorm.DB) error {
+			var ids []uint
+			return db.WithContext(ctx).Raw("UPDATE replica_test_rows SET name = ? RETURNING id", "b").Scan(&ids).Error
+		})
+		if got := f.servedBy(t, findRows(ctx)); got != "primary" {
+			t.Errorf("read after raw statement served by %q, want primary", got)
+		}
+	})
+
+	t.Run("failed statements do not pin the session", func(t *testing.T) {
+		f := newReplicatedFakeDB(t, 1)
+		ctx := WithSession(context.Background())
+
+		tx := f.db.WithContext(ctx).Session(&gorm.Session{})
+		tx.Statement.SQL.WriteString("UPDATE replica_test_rows SET name = 'b'")
+		tx.Error = errors.New("boom")
+		markWrite(tx)
+		if got := f.servedBy(t, findRows(ctx)); got != "replica-1" {
+			t.Errorf("read after failed statement served by %q, want replica-1", got)
+		}
+	})
+}
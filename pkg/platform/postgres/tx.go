@@ -0,0 +1,155 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math/rand/v2"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+// ค่าเริ่มต้นของการลองใหม่เมื่อ Transaction ชนกัน
+const (
+	DefaultTxMaxAttempts = 3
+	DefaultTxRetryDelay  = 20 * time.Millisecond
+)
+
+// SQLSTATE ที่แปลว่า "ลองใหม่ทั้ง Transaction แล้วน่าจะผ่าน"
+const (
+	sqlStateSerializationFailure = "40001"
+	sqlStateDeadlockDetected     = "40P01"
+)
+
+// txContextKey คือ key แบบ private สำหรับเก็บ Transaction ที่กำลังทำงานอยู่ไว้ใน context
+type txContextKey struct{}
+
+// activeTx คือ Transaction ใน context พร้อมบอกว่าเป็นของ connection ไหน
+// (ใช้ *gorm.Config แยก เพราะทุก session ที่มาจาก gorm.Open ครั้งเดียวกันใช้ Config ตัวเดียวกัน)
+type activeTx struct {
+	config *gorm.Config
+	tx     *gorm.DB
+}
+
+// TxManager คือ "ผู้จัดการ Unit of Work" ที่ทำให้ Service เรียกหลาย Repository ใน Transaction เดียวกันได้
+// Transaction ถูกส่งต่อผ่าน ctx ที่ส่งให้ fn และ Repository ที่ใช้ DB(ctx, r.db) จะหยิบไปใช้เอง
+type TxManager interface {
+	// WithinTransaction รัน fn ใน Transaction (commit เมื่อ fn คืน nil, rollback เมื่อคืน error หรือ panic)
+	// - ถ้า ctx อยู่ใน Transaction อยู่แล้ว จะใช้ SAVEPOINT ซ้อนข้างใน (rollback แค่ส่วนของ fn นี้)
+	// - ถ้าเจอ serialization failure (40001) หรือ deadlock (40P01) จะรัน fn ใหม่ทั้งหมด (เฉพาะ Transaction นอกสุด)
+	// ⭐️ fn จึงอาจถูกเรียกมากกว่า 1 ครั้ง: ห้ามมีผลข้างเคียงนอก DB (เรียก API, ส่งอีเมล) และอย่าแก้ข้อมูลที่รับเข้ามาจนกว่าจะสำเร็จ
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error, opts ...TxOption) error
+}
+
+// TxOption ปรับการทำงานของ Transaction 1 ครั้ง
+type TxOption func(*txOptions)
+
+type txOptions struct {
+	sql         sql.TxOptions
+	maxAttempts int
+}
+
+// WithIsolation กำหนด isolation level (ค่าเริ่มต้นของ Postgres คือ READ COMMITTED)
+// ใช้ sql.LevelSerializable กับงานแบบ "ตรวจก่อนแล้วค่อยเขียน" เพื่อให้ Postgres ตรวจการชนกันให้ แล้วปล่อยให้ TxManager ลองใหม่
+func WithIsolation(level sql.IsolationLevel) TxOption {
+	return func(o *txOptions) { o.sql.Isolation = level }
+}
+
+// WithMaxAttempts กำหนดจำนวนครั้งสูงสุดที่จะรัน fn (1 = ไม่ลองใหม่)
+func WithMaxAttempts(attempts int) TxOption {
+	return func(o *txOptions) {
+		if attempts > 0 {
+			o.maxAttempts = attempts
+		}
+	}
+}
+
+type txManager struct {
+	db *gorm.DB
+}
+
+// NewTxManager คือโรงงานสร้าง TxManager ของ connection นี้ (ปกติคือ primary)
+func NewTxManager(db *gorm.DB) TxManager {
+	return &txManager{db: db}
+}
+
+func (m *txManager) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error, opts ...TxOption) error {
+	options := txOptions{maxAttempts: DefaultTxMaxAttempts}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	// ซ้อนอยู่ใน Transaction เดิม: GORM จะสร้าง SAVEPOINT ให้เอง
+	// ไม่ลองใหม่ตรงนี้ เพราะเมื่อเกิด serialization failure ทั้ง Transaction นอกสุดใช้ต่อไม่ได้แล้ว
+	if outer := txFromContext(ctx, m.db); outer != nil {
+		return outer.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			return fn(withTx(ctx, m.db, tx))
+		})
+	}
+
+	delay := DefaultTxRetryDelay
+	for attempt := 1; ; attempt++ {
+		err := m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			return fn(withTx(ctx, m.db, tx))
+		}, &options.sql)
+		if err == nil || attempt >= options.maxAttempts || !IsRetryableTxError(err) {
+			return err
+		}
+
+		// รอแบบสุ่มเล็กน้อยก่อนลองใหม่ เพื่อไม่ให้ Transaction ที่ชนกันกลับมาชนกันซ้ำพร้อมๆ กัน
+		wait := delay/2 + rand.N(delay)
+		m.db.Logger.Warn(ctx, "transaction conflict (%v), retrying in %s (attempt %d/%d)", err, wait, attempt+1, options.maxAttempts)
+		select {
+		case <-ctx.Done():
+			return errors.Join(err, ctx.Err())
+		case <-time.After(wait):
+		}
+		delay *= 2
+	}
+}
+
+// IsRetryableTxError บอกว่า error นี้เกิดจาก Transaction ชนกัน (ลองใหม่ทั้ง Transaction แล้วน่าจะผ่าน)
+func IsRetryableTxError(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	return pgErr.Code == sqlStateSerializationFailure || pgErr.Code == sqlStateDeadlockDetected
+}
+
+// DB คืน *gorm.DB ที่ Repository ควรใช้สำหรับ ctx นี้
+// ถ้า ctx อยู่ใน WithinTransaction ของ connection เดียวกันจะได้ Transaction นั้น ไม่งั้นได้ db.WithContext(ctx) ตามปกติ
+// (ใช้แทน r.db.WithContext(ctx) ได้ทุกที่)
+func DB(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx := txFromContext(ctx, db); tx != nil {
+		return tx.WithContext(ctx)
+	}
+	return db.WithContext(ctx)
+}
+
+// InTransaction บอกว่า ctx นี้อยู่ใน WithinTransaction หรือไม่ (ของ connection ใดก็ได้)
+func InTransaction(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	_, ok := ctx.Value(txContextKey{}).(*activeTx)
+	return ok
+}
+
+func withTx(ctx context.Context, db, tx *gorm.DB) context.Context {
+	return context.WithValue(ctx, txContextKey{}, &activeTx{config: db.Config, tx: tx})
+}
+
+// txFromContext คืน Transaction ใน ctx ถ้าเป็นของ connection เดียวกับ db (ไม่งั้นคืน nil)
+func txFromContext(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if ctx == nil {
+		return nil
+	}
+	active, ok := ctx.Value(txContextKey{}).(*activeTx)
+	if !ok || active.config != db.Config {
+		return nil
+	}
+	return active.tx
+}
@@ -0,0 +1,350 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	gormpostgres "gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// --- Fake driver: จำทุกคำสั่งที่ส่งมา และจำลอง error ตอน COMMIT ได้ ---
+
+type recorder struct {
+	mu         sync.Mutex
+	statements []string
+	commitErrs []error // error ที่ COMMIT แต่ละครั้งจะคืน (หมดแล้ว = สำเร็จ)
+}
+
+func (r *recorder) record(s string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.statements = append(r.statements, s)
+}
+
+func (r *recorder) log() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	// ชื่อ SAVEPOINT ของ GORM เป็น pointer ที่เปลี่ยนทุกครั้ง ตัดออกให้เทียบกันได้
+	out := make([]string, len(r.statements))
+	for i, s := range r.statements {
+		if strings.Contains(s, "SAVEPOINT") {
+			s = s[:strings.LastIndex(s, " ")]
+		}
+		out[i] = s
+	}
+	return out
+}
+
+type fakeConnector struct{ rec *recorder }
+type fakeDriver struct{ rec *recorder }
+type fakeConn struct{ rec *recorder }
+type fakeTx struct{ rec *recorder }
+
+func (c fakeConnector) Connect(context.Context) (driver.Conn, error) { return fakeConn(c), nil }
+func (c fakeConnector) Driver() driver.Driver                        { return fakeDriver(c) }
+func (d fakeDriver) Open(string) (driver.Conn, error)                { return fakeConn(d), nil }
+func (fakeConn) Prepare(string) (driver.Stmt, error)                 { return nil, errors.New("not supported") }
+func (fakeConn) Close() error                                        { return nil }
+func (c fakeConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c fakeConn) BeginTx(_ context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if sql.IsolationLevel(opts.Isolation) == sql.LevelSerializable {
+		c.rec.record("BEGIN SERIALIZABLE")
+	} else {
+		c.rec.record("BEGIN")
+	}
+	return fakeTx(c), nil
+}
+
+func (c fakeConn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	c.rec.record(query)
+	return driver.RowsAffected(1), nil
+}
+
+func (c fakeConn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	c.rec.record(query)
+	return fakeRows{}, nil
+}
+
+// fakeRows คือผลลัพธ์ว่างของทุก SELECT
+type fakeRows struct{}
+
+func (fakeRows) Columns() []string         { return nil }
+func (fakeRows) Close() error              { return nil }
+func (fakeRows) Next([]driver.Value) error { return io.EOF }
+
+func (t fakeTx) Commit() error {
+	t.rec.mu.Lock()
+	defer t.rec.mu.Unlock()
+	if len(t.rec.commitErrs) > 0 {
+		err := t.rec.commitErrs[0]
+		t.rec.commitErrs = t.rec.commitErrs[1:]
+		t.rec.statements = append(t.rec.statements, "COMMIT (failed)")
+		return err
+	}
+	t.rec.statements = append(t.rec.statements, "COMMIT")
+	return nil
+}
+
+func (t fakeTx) Rollback() error {
+	t.rec.record("ROLLBACK")
+	return nil
+}
+
+func newFakeDB(t *testing.T, commitErrs ...error) (*gorm.DB, *recorder) {
+	t.Helper()
+	rec := &recorder{commitErrs: commitErrs}
+	db, err := gorm.Open(gormpostgres.New(gormpostgres.Config{Conn: sql.OpenDB(fakeConnector{rec: rec})}), &gorm.Config{
+		Logger:               gormlogger.Discard,
+		DisableAutomaticPing: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db, rec
+}
+
+func pgError(code string) error {
+	return &pgconn.PgError{Code: code, Message: "simulated " + code}
+}
+
+func assertStatements(t *testing.T, rec *recorder, want ...string) {
+	t.Helper()
+	got := rec.log()
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("statements =\n  %s\nwant\n  %s", strings.Join(got, "\n  "), strings.Join(want, "\n  "))
+	}
+}
+
+// --- Tests ---
+
+func TestIsRetryableTxError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "serialization failure", err: pgError("40001"), want: true},
+		{name: "deadlock", err: pgError("40P01"), want: true},
+		{name: "wrapped serialization failure", err: fmt.Errorf("check email: %w", pgError("40001")), want: true},
+		{name: "joined deadlock", err: errors.Join(errors.New("other"), pgError("40P01")), want: true},
+		{name: "unique violation", err: pgError("23505"), want: false},
+		{name: "statement timeout", err: pgError("57014"), want: false},
+		{name: "other class 40 error", err: pgError("40002"), want: false},
+		{name: "plain error", err: errors.New("40001"), want: false},
+		{name: "gorm not found", err: gorm.ErrRecordNotFound, want: false},
+		{name: "nil", err: nil, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRetryableTxError(tt.err); got != tt.want {
+				t.Errorf("IsRetryableTxError(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithinTransactionCommitsAndRollsBack(t *testing.T) {
+	t.Run("commit on success", func(t *testing.T) {
+		db, rec := newFakeDB(t)
+		err := NewTxManager(db).WithinTransaction(context.Background(), func(ctx context.Context) error {
+			if !InTransaction(ctx) {
+				t.Error("InTransaction(ctx) = false inside WithinTransaction")
+			}
+			return DB(ctx, db).Exec("UPDATE a").Error
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertStatements(t, rec, "BEGIN", "UPDATE a", "COMMIT")
+	})
+
+	t.Run("rollback on error", func(t *testing.T) {
+		db, rec := newFakeDB(t)
+		boom := errors.New("boom")
+		err := NewTxManager(db).WithinTransaction(context.Background(), func(ctx context.Context) error {
+			DB(ctx, db).Exec("UPDATE a")
+			return boom
+		})
+		if !errors.Is(err, boom) {
+			t.Fatalf("error = %v, want %v", err, boom)
+		}
+		assertStatements(t, rec, "BEGIN", "UPDATE a", "ROLLBACK")
+	})
+
+	t.Run("isolation level is passed to BEGIN", func(t *testing.T) {
+		db, rec := newFakeDB(t)
+		err := NewTxManager(db).WithinTransaction(context.Background(), func(ctx context.Context) error {
+			return nil
+		}, WithIsolation(sql.LevelSerializable))
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertStatements(t, rec, "BEGIN SERIALIZABLE", "COMMIT")
+	})
+
+	t.Run("queries outside the transaction do not use it", func(t *testing.T) {
+		db, rec := newFakeDB(t)
+		if InTransaction(context.Background()) {
+			t.Error("InTransaction(background) = true")
+		}
+		DB(context.Background(), db).Exec("SELECT outside")
+		assertStatements(t, rec, "SELECT outside")
+	})
+}
+
+func TestWithinTransactionNestedUsesSavepoint(t *testing.T) {
+	db, rec := newFakeDB(t)
+	m := NewTxManager(db)
+	innerErr := errors.New("inner failed")
+
+	err := m.WithinTransaction(context.Background(), func(ctx context.Context) error {
+		DB(ctx, db).Exec("UPDATE outer")
+		err := m.WithinTransaction(ctx, func(ctx context.Context) error {
+			DB(ctx, db).Exec("UPDATE inner")
+			return innerErr
+		})
+		if !errors.Is(err, innerErr) {
+			t.Errorf("inner error = %v, want %v", err, innerErr)
+		}
+		return m.WithinTransaction(ctx, func(ctx context.Context) error {
+			return DB(ctx, db).Exec("UPDATE inner ok").Error
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertStatements(t, rec,
+		"BEGIN",
+		"UPDATE outer",
+		"SAVEPOINT",
+		"UPDATE inner",
+		"ROLLBACK TO SAVEPOINT",
+		"SAVEPOINT",
+		"UPDATE inner ok",
+		"COMMIT",
+	)
+}
+
+func TestWithinTransactionIgnoresOtherConnections(t *testing.T) {
+	db, rec := newFakeDB(t)
+	other, otherRec := newFakeDB(t)
+
+	err := NewTxManager(db).WithinTransaction(context.Background(), func(ctx context.Context) error {
+		// ctx มี Transaction ของ db แต่ไม่ใช่ของ other จึงต้องได้ connection ปกติของ other
+		return DB(ctx, other).Exec("SELECT other").Error
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertStatements(t, rec, "BEGIN", "COMMIT")
+	assertStatements(t, otherRec, "SELECT other")
+}
+
+func TestWithinTransactionRetries(t *testing.T) {
+	tests := []struct {
+		name       string
+		commitErrs []error
+		fnErrs     []error // error ที่ fn คืนในแต่ละรอบ (หมดแล้ว = nil)
+		opts       []TxOption
+		wantCalls  int
+		wantCode   string // SQLSTATE ของ error ที่คืนออกมา (ว่าง = สำเร็จ)
+	}{
+		{name: "serialization failure on commit is retried", commitErrs: []error{pgError("40001")}, wantCalls: 2},
+		{name: "deadlock inside fn is retried", fnErrs: []error{pgError("40P01")}, wantCalls: 2},
+		{name: "other errors are not retried", fnErrs: []error{pgError("23505")}, wantCalls: 1, wantCode: "23505"},
+		{name: "gives up after the default attempts", commitErrs: []error{pgError("40001"), pgError("40001"), pgError("40001"), pgError("40001")}, wantCalls: DefaultTxMaxAttempts, wantCode: "40001"},
+		{name: "custom attempt cap", fnErrs: []error{pgError("40001"), pgError("40001")}, opts: []TxOption{WithMaxAttempts(2)}, wantCalls: 2, wantCode: "40001"},
+		{name: "max attempts 1 disables retries", fnErrs: []error{pgError("40001")}, opts: []TxOption{WithMaxAttempts(1)}, wantCalls: 1, wantCode: "40001"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, _ := newFakeDB(t, tt.commitErrs...)
+			calls := 0
+			err := NewTxManager(db).WithinTransaction(context.Background(), func(ctx context.Context) error {
+				calls++
+				if calls <= len(tt.fnErrs) {
+					return tt.fnErrs[calls-1]
+				}
+				return nil
+			}, tt.opts...)
+
+			if calls != tt.wantCalls {
+				t.Errorf("fn called %d times, want %d", calls, tt.wantCalls)
+			}
+			if tt.wantCode == "" {
+				if err != nil {
+					t.Errorf("error = %v, want nil", err)
+				}
+				return
+			}
+			var pgErr *pgconn.PgError
+			if !errors.As(err, &pgErr) || pgErr.Code != tt.wantCode {
+				t.Errorf("error = %v, want SQLSTATE %s", err, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestWithinTransactionNestedDoesNotRetry(t *testing.T) {
+	db, _ := newFakeDB(t)
+	m := NewTxManager(db)
+
+	outerCalls, innerCalls := 0, 0
+	err := m.WithinTransaction(context.Background(), func(ctx context.Context) error {
+		outerCalls++
+		return m.WithinTransaction(ctx, func(ctx context.Context) error {
+			innerCalls++
+			if outerCalls == 1 {
+				return pgError("40001")
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// savepoint ไม่ลองใหม่เอง error ต้องลอยขึ้นไปให้ Transaction นอกสุดรันใหม่ทั้งก้อน
+	if outerCalls != 2 || innerCalls != 2 {
+		t.Errorf("outer = %d, inner = %d calls, want 2 and 2", outerCalls, innerCalls)
+	}
+}
+
+func TestWithinTransactionStopsRetryingWhenContextIsDone(t *testing.T) {
+	db, _ := newFakeDB(t)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	calls := 0
+	start := time.Now()
+	err := NewTxManager(db).WithinTransaction(ctx, func(context.Context) error {
+		calls++
+		cancel()
+		return pgError("40001")
+	}, WithMaxAttempts(10))
+
+	if calls != 1 {
+		t.Errorf("fn called %d times, want 1", calls)
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("error = %v, want context.Canceled", err)
+	}
+	if !IsRetryableTxError(err) {
+		t.Errorf("error = %v, want the original serialization failure to be kept", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("took %s, should stop waiting as soon as ctx is done", elapsed)
+	}
+}
@@ -0,0 +1,206 @@
+package platform
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"go-template/pkg/config"
+	"go-template/pkg/logger"
+	"go-template/pkg/platform/postgres"
+)
+
+// สถานะของ connection ใน health check
+const (
+	StatusOK           = "ok"
+	StatusError        = "error"
+	StatusNotConnected = "not_connected" // ยังไม่มีใครเรียก Get จึงยังไม่ได้เปิด
+)
+
+var (
+	// ErrUnknownDatabase ถูกคืนเมื่อขอ connection ที่ไม่มีอยู่ใน config (หรือ host ว่าง)
+	ErrUnknownDatabase = errors.New("database is not configured")
+	// ErrRegistryClosed ถูกคืนเมื่อขอ connection หลังจากเรียก Close ไปแล้ว
+	ErrRegistryClosed = errors.New("database registry is closed")
+)
+
+// DatabaseHealth คือสถานะของ connection 1 ตัว
+// Replicas แสดงสถานะและ lag ล่าสุดของแต่ละ read replica (replica ล่มไม่ทำให้ Status เป็น error เพราะยังอ่านจาก primary ได้)
+type DatabaseHealth struct {
+	Name     string                   `json:"name"`
+	Status   string                   `json:"status"`
+	Error    string                   `json:"error,omitempty"`
+	Replicas []postgres.ReplicaStatus `json:"replicas,omitempty"`
+}
+
+// Registry คือ "ตู้กุญแจ" ที่เก็บ connection ของทุก database ใน config ตามชื่อ
+// connection จะถูกเปิดตอนเรียก Get ครั้งแรก (lazy) และใช้ตัวเดิมตลอด
+// ถ้าเปิดไม่สำเร็จจะไม่จำ error ไว้ การเรียก Get ครั้งถัดไปจะลองเปิดใหม่
+type Registry struct {
+	configs config.PostgresDbs
+	// open เปิด connection จริง (เทสต์แทนที่ได้โดยไม่ต้องมี Postgres)
+	open func(name string, cfg config.PostgresConfig) (*gorm.DB, error)
+
+	mu      sync.Mutex
+	entries map[string]*registryEntry
+	closed  bool
+}
+
+// registryEntry มี lock ของตัวเอง เพื่อไม่ให้การเปิด DB ตัวหนึ่ง (ที่อาจ retry นาน) ไปขวาง Get ของตัวอื่น
+type registryEntry struct {
+	mu sync.Mutex
+	db *gorm.DB
+}
+
+// NewRegistry คือโรงงานสร้าง Registry (ยังไม่เปิด connection ใดๆ)
+// ชื่อ connection ไม่สนตัวพิมพ์ และ connection ที่ host ว่างจะถือว่าไม่ได้ตั้งค่า
+func NewRegistry(configs config.PostgresDbs, logCfg config.LoggingConfig, log logger.Logger) *Registry {
+	normalized := make(config.PostgresDbs, len(configs))
+	for name, cfg := range configs {
+		if cfg.IsConfigured() {
+			normalized[strings.ToLower(name)] = cfg
+		}
+	}
+	return &Registry{
+		configs: normalized,
+		open: func(name string, cfg config.PostgresConfig) (*gorm.DB, error) {
+			return postgres.NewConnection(name, cfg, logCfg, log)
+		},
+		entries: make(map[string]*registryEntry),
+	}
+}
+
+// Names คืนชื่อ connection ทั้งหมดที่ตั้งค่าไว้ เรียงตามตัวอักษร
+func (r *Registry) Names() []string {
+	return r.configs.Configured()
+}
+
+// Has บอกว่ามี connection ชื่อนี้ใน config หรือไม่
+func (r *Registry) Has(name string) bool {
+	_, ok := r.configs[strings.ToLower(name)]
+	return ok
+}
+
+// Get คืน connection ตามชื่อ (เปิดให้ตอนเรียกครั้งแรก)
+func (r *Registry) Get(name string) (*gorm.DB, error) {
+	name = strings.ToLower(name)
+	cfg, ok := r.configs[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q (configured: %s)", ErrUnknownDatabase, name, strings.Join(r.Names(), ", "))
+	}
+
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return nil, ErrRegistryClosed
+	}
+	entry, ok := r.entries[name]
+	if !ok {
+		entry = &registryEntry{}
+		r.entries[name] = entry
+	}
+	r.mu.Unlock()
+
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+	if entry.db != nil {
+		return entry.db, nil
+	}
+	db, err := r.open(name, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// ถ้าระหว่างที่กำลังเปิดอยู่มีคนเรียก Close ไปแล้ว ต้องปิดตัวที่เพิ่งเปิดทิ้งด้วย
+	r.mu.Lock()
+	closed := r.closed
+	r.mu.Unlock()
+	if closed {
+		_ = postgres.Close(db)
+		return nil, ErrRegistryClosed
+	}
+	entry.db = db
+	return db, nil
+}
+
+// Health ตรวจทุก connection ที่ตั้งค่าไว้ (เรียงตามชื่อ)
+// ตัวที่เปิดแล้วจะถูก Ping ส่วนตัวที่ยังไม่เคยถูกใช้จะรายงานเป็น not_connected โดยไม่เปิดให้
+func (r *Registry) Health(ctx context.Context) []DatabaseHealth {
+	names := r.Names()
+	results := make([]DatabaseHealth, len(names))
+	var wg sync.WaitGroup
+	for i, name := range names {
+		results[i] = DatabaseHealth{Name: name, Status: StatusNotConnected}
+		db := r.connected(name)
+		if db == nil {
+			continue
+		}
+		wg.Add(1)
+		go func(result *DatabaseHealth, db *gorm.DB) {
+			defer wg.Done()
+			if replicas := postgres.Replicas(db); replicas != nil {
+				result.Replicas = replicas.Status()
+			}
+			if err := ping(ctx, db); err != nil {
+				result.Status = StatusError
+				result.Error = err.Error()
+				return
+			}
+			result.Status = StatusOK
+		}(&results[i], db)
+	}
+	wg.Wait()
+	return results
+}
+
+// Close ปิดทุก connection ที่เปิดอยู่ (เรียกตอน shutdown) หลังจากนี้ Get จะคืน ErrRegistryClosed
+func (r *Registry) Close() error {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return nil
+	}
+	r.closed = true
+	entries := r.entries
+	r.mu.Unlock()
+
+	var errs []error
+	for name, entry := range entries {
+		entry.mu.Lock()
+		if entry.db != nil {
+			if err := postgres.Close(entry.db); err != nil {
+				errs = append(errs, fmt.Errorf("close %q: %w", name, err))
+			}
+			entry.db = nil
+		}
+		entry.mu.Unlock()
+	}
+	return errors.Join(errs...)
+}
+
+// connected คืน connection ที่เปิดไว้แล้ว (nil = ยังไม่เปิด) โดยไม่รอให้ตัวที่กำลังเปิดอยู่เสร็จ
+func (r *Registry) connected(name string) *gorm.DB {
+	r.mu.Lock()
+	entry, ok := r.entries[name]
+	r.mu.Unlock()
+	if !ok || !entry.mu.TryLock() {
+		return nil
+	}
+	defer entry.mu.Unlock()
+	return entry.db
+}
+
+func ping(ctx context.Context, db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	return sqlDB.PingContext(ctx)
+}
@@ -0,0 +1,200 @@
+package platform
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	gormpostgres "gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+
+	"go-template/pkg/config"
+)
+
+// --- Fake driver: ต่อได้เสมอ หรือคืน error ตอนต่อ (ใช้จำลอง DB ที่ ping ไม่ผ่าน) ---
+
+type fakeConnector struct{ err error }
+type fakeConn struct{}
+
+func (c fakeConnector) Connect(context.Context) (driver.Conn, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	return fakeConn{}, nil
+}
+func (fakeConnector) Driver() driver.Driver          { return nil }
+func (fakeConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (fakeConn) Close() error                        { return nil }
+func (fakeConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+func newFakeDB(t *testing.T, connectErr error) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(gormpostgres.New(gormpostgres.Config{Conn: sql.OpenDB(fakeConnector{err: connectErr})}), &gorm.Config{
+		Logger:               gormlogger.Discard,
+		DisableAutomaticPing: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func newTestRegistry(names ...string) *Registry {
+	configs := make(config.PostgresDbs, len(names))
+	for _, name := range names {
+		configs[name] = config.PostgresConfig{Host: "localhost"}
+	}
+	return NewRegistry(configs, config.LoggingConfig{}, nil)
+}
+
+func TestRegistryGetIsLazy(t *testing.T) {
+	r := NewRegistry(config.PostgresDbs{
+		"Primary": {Host: "localhost"},
+		"logs":    {}, // ไม่มี host = ไม่ได้ตั้งค่า
+	}, config.LoggingConfig{}, nil)
+
+	var opened atomic.Int32
+	db := newFakeDB(t, nil)
+	r.open = func(name string, cfg config.PostgresConfig) (*gorm.DB, error) {
+		opened.Add(1)
+		time.Sleep(10 * time.Millisecond) // ให้ Get ที่เรียกพร้อมกันมารอ
+		return db, nil
+	}
+	if opened.Load() != 0 {
+		t.Fatal("NewRegistry must not open any connection")
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			got, err := r.Get("PRIMARY") // ชื่อไม่สนตัวพิมพ์
+			if err != nil || got != db {
+				t.Errorf("Get() = %v, %v", got, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if got := opened.Load(); got != 1 {
+		t.Errorf("opened %d times, want 1", got)
+	}
+
+	for _, name := range []string{"logs", "analytics"} {
+		if _, err := r.Get(name); !errors.Is(err, ErrUnknownDatabase) {
+			t.Errorf("Get(%q) = %v, want ErrUnknownDatabase", name, err)
+		}
+	}
+}
+
+func TestRegistryGetRetriesAfterFailedOpen(t *testing.T) {
+	r := newTestRegistry("primary")
+	db := newFakeDB(t, nil)
+	openErr := errors.New("connection refused")
+	attempts := 0
+	r.open = func(name string, cfg config.PostgresConfig) (*gorm.DB, error) {
+		attempts++
+		if attempts == 1 {
+			return nil, openErr
+		}
+		return db, nil
+	}
+
+	if _, err := r.Get("primary"); !errors.Is(err, openErr) {
+		t.Fatalf("first Get() = %v, want %v", err, openErr)
+	}
+	got, err := r.Get("primary")
+	if err != nil || got != db {
+		t.Fatalf("second Get() = %v, %v, want the opened connection", got, err)
+	}
+	if attempts != 2 {
+		t.Errorf("opened %d times, want 2", attempts)
+	}
+}
+
+func TestRegistryCloseDuringOpen(t *testing.T) {
+	r := newTestRegistry("primary")
+	db := newFakeDB(t, nil)
+	opening := make(chan struct{})
+	release := make(chan struct{})
+	r.open = func(name string, cfg config.PostgresConfig) (*gorm.DB, error) {
+		close(opening)
+		<-release
+		return db, nil
+	}
+
+	getErr := make(chan error, 1)
+	go func() {
+		_, err := r.Get("primary")
+		getErr <- err
+	}()
+	<-opening
+
+	closeErr := make(chan error, 1)
+	go func() { closeErr <- r.Close() }()
+	// รอให้ Close ตั้งสถานะปิดก่อนค่อยปล่อยให้เปิดเสร็จ
+	for {
+		r.mu.Lock()
+		closed := r.closed
+		r.mu.Unlock()
+		if closed {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+
+	if err := <-getErr; !errors.Is(err, ErrRegistryClosed) {
+		t.Errorf("Get() = %v, want ErrRegistryClosed", err)
+	}
+	if err := <-closeErr; err != nil {
+		t.Errorf("Close() = %v", err)
+	}
+	sqlDB, _ := db.DB()
+	if err := sqlDB.Ping(); err == nil || err.Error() != "sql: database is closed" {
+		t.Errorf("connection opened during Close was not closed (ping = %v)", err)
+	}
+	if _, err := r.Get("primary"); !errors.Is(err, ErrRegistryClosed) {
+		t.Errorf("Get() after Close = %v, want ErrRegistryClosed", err)
+	}
+}
+
+func TestRegistryHealth(t *testing.T) {
+	r := newTestRegistry("primary", "logs", "analytics")
+	dbs := map[string]*gorm.DB{
+		"primary":   newFakeDB(t, nil),
+		"analytics": newFakeDB(t, errors.New("connection refused")),
+	}
+	r.open = func(name string, cfg config.PostgresConfig) (*gorm.DB, error) {
+		return dbs[name], nil
+	}
+	for _, name := range []string{"primary", "analytics"} {
+		if _, err := r.Get(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := []DatabaseHealth{
+		{Name: "analytics", Status: StatusError, Error: "connection refused"},
+		{Name: "logs", Status: StatusNotConnected},
+		{Name: "primary", Status: StatusOK},
+	}
+	got := r.Health(context.Background())
+	if len(got) != len(want) {
+		t.Fatalf("Health() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i].Name != want[i].Name || got[i].Status != want[i].Status || got[i].Error != want[i].Error {
+			t.Errorf("Health()[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+	if _, opened := r.entries["logs"]; opened {
+		t.Error("Health() must not open connections that were never used")
+	}
+}
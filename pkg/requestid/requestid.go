@@ -0,0 +1,57 @@
+package requestid
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// Header คือชื่อ header ที่ใช้รับ/ส่ง Request ID ระหว่าง client, เรา และบริการปลายทาง
+const Header = "X-Request-ID"
+
+// MaxLength คือความยาวสูงสุดของ Request ID ที่ยอมรับจาก client
+const MaxLength = 128
+
+// requestIDContextKey คือ key แบบ private สำหรับเก็บ Request ID ไว้ใน context
+type requestIDContextKey struct{}
+
+// ContextKey คือ key ที่ Middleware ใช้ฝาก Request ID ไว้กับ request
+// (fiber.Ctx เป็น context.Context และ Value() จะอ่านจาก Locals ให้เรา)
+var ContextKey = requestIDContextKey{}
+
+// New สร้าง Request ID ใหม่ (UUID v4)
+func New() string {
+	return uuid.NewString()
+}
+
+// IsValid ตรวจว่า Request ID ที่ client ส่งมาปลอดภัยพอจะใช้ต่อหรือไม่
+// อนุญาตเฉพาะตัวอักษร/ตัวเลขและ - _ . : เพื่อกันการยัดขึ้นบรรทัดใหม่หรืออักขระแปลกๆ ลงใน log
+func IsValid(id string) bool {
+	if id == "" || len(id) > MaxLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		ch := id[i]
+		switch {
+		case ch >= 'a' && ch <= 'z', ch >= 'A' && ch <= 'Z', ch >= '0' && ch <= '9':
+		case ch == '-', ch == '_', ch == '.', ch == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// WithRequestID คืน context ใหม่ที่แนบ Request ID ไว้ (ใช้กับงานที่ไม่ได้มาจาก HTTP request เช่น background job)
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, ContextKey, id)
+}
+
+// FromContext ดึง Request ID ออกจาก context (คืน "" ถ้าไม่มี)
+func FromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(ContextKey).(string)
+	return id
+}
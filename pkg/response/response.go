@@ -5,6 +5,7 @@ import (
 	"math"
 
 	"go-template/pkg/custom_errors"
+	"go-template/pkg/requestid"
 
 	"github.com/gofiber/fiber/v3"
 )
@@ -24,8 +25,10 @@ type Pagination struct {
 	CurrentPage  *int `json:"current_page,omitempty"`
 
 	// --- Cursor-based fields ---
-	NextCursor *string `json:"next_cursor,omitempty"`
-	HasMore    *bool   `json:"has_more,omitempty"`
+	NextCursor  *string `json:"next_cursor,omitempty"`
+	PrevCursor  *string `json:"prev_cursor,omitempty"`
+	HasMore     *bool   `json:"has_more,omitempty"`
+	HasPrevious *bool   `json:"has_previous,omitempty"`
 }
 
 // ====================================================================================
@@ -53,11 +56,19 @@ func NewPagePagination(totalRecords, limit, offset int) *Pagination {
 
 // ⭐️⭐️⭐️ เพิ่ม "โรงงาน" ใหม่สำหรับ Cursor-based! ⭐️⭐️⭐️
 // NewCursorPagination คือโรงงานสำหรับสร้าง Cursor-based Pagination object
-func NewCursorPagination(nextCursor string, hasMore bool) *Pagination {
-	return &Pagination{
-		NextCursor: &nextCursor,
-		HasMore:    &hasMore,
+// cursor ที่เป็น string ว่างจะไม่ถูกส่งออกไป (หมายถึงไม่มีหน้านั้นแล้ว)
+func NewCursorPagination(nextCursor, prevCursor string, hasMore, hasPrevious bool) *Pagination {
+	p := &Pagination{
+		HasMore:     &hasMore,
+		HasPrevious: &hasPrevious,
+	}
+	if nextCursor != "" {
+		p.NextCursor = &nextCursor
+	}
+	if prevCursor != "" {
+		p.PrevCursor = &prevCursor
 	}
+	return p
 }
 
 // Success คือ "ผู้ช่วย" หลักสำหรับส่ง Response เมื่อทำงานสำเร็จ
@@ -74,14 +85,20 @@ func Success(c fiber.Ctx, httpStatus int, message string, data interface{}, pagi
 }
 
 // Error คือ "ผู้ช่วย" หลักสำหรับส่ง Error Response
+// ถ้า request มี Request ID (จาก middleware.RequestID) จะแนบไปใน error.request_id
+// ให้ client ใช้อ้างอิงตอนแจ้งปัญหา แล้วเราค้น log ที่ตรงกันได้ทันที
 func Error(c fiber.Ctx, err *custom_errors.AppError) error {
+	errorBody := fiber.Map{
+		"code":    err.Code,
+		"details": err.Details,
+	}
+	if id := requestid.FromContext(c); id != "" {
+		errorBody["request_id"] = id
+	}
 	return c.Status(err.HTTPStatus).JSON(fiber.Map{
 		"success": false,
 		"message": err.Message,
-		"error": fiber.Map{
-			"code":    err.Code,
-			"details": err.Details,
-		},
+		"error":   errorBody,
 	})
 }
 
@@ -34,7 +34,8 @@ type ValidationErrorDetail struct {
 // New creates and configures a new validator instance.
 // เราเปลี่ยนจาก var global มาเป็นฟังก์ชัน New() เพื่อให้เรา "ลงทะเบียน" กฎใหม่ๆ ได้
 func New() *validator.Validate {
-	v := validator.New()
+	// WithRequiredStructEnabled ทำให้ "required" ใช้กับ field ที่เป็น struct ได้ (เช่น decimal.Decimal ที่ไม่ได้ส่งมา)
+	v := validator.New(validator.WithRequiredStructEnabled())
 
 	// ⭐️ ลงทะเบียน "กฎ" ใหม่ที่เราสร้างขึ้นเองที่นี่! ⭐️
 	v.RegisterValidation("sort_format", validateSortFormat)
@@ -0,0 +1,68 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ชื่อ Header มาตรฐานที่ผู้ส่ง webhook ต้องแนบมา
+const (
+	HeaderTimestamp = "X-Webhook-Timestamp" // Unix seconds ตอนที่ผู้ส่งเซ็น
+	HeaderSignature = "X-Webhook-Signature" // "sha256=" + hex(HMAC-SHA256(secret, timestamp + "." + body))
+)
+
+// DefaultTolerance คือช่วงเวลาที่ยอมให้ timestamp คลาดจากเวลาเรา (กัน replay attack)
+const DefaultTolerance = 5 * time.Minute
+
+const signaturePrefix = "sha256="
+
+var (
+	ErrMissingSignature    = errors.New("webhook signature or timestamp is missing")
+	ErrInvalidTimestamp    = errors.New("webhook timestamp is not a valid unix time")
+	ErrTimestampOutOfRange = errors.New("webhook timestamp is outside the allowed tolerance")
+	ErrSignatureMismatch   = errors.New("webhook signature does not match")
+)
+
+// Sign สร้างลายเซ็นของ body (ใช้ฝั่งผู้ส่ง เช่นตอนทดสอบ หรือเมื่อเราเป็นคนส่ง webhook เอง)
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify ตรวจทั้งลายเซ็นและความสดของ timestamp
+// ⭐️ timestamp ถูกรวมอยู่ในสิ่งที่เซ็น ผู้ไม่หวังดีจึงเอา request เก่ามายิงซ้ำโดยแก้แค่ timestamp ไม่ได้
+func Verify(secret, timestampHeader, signatureHeader string, body []byte, tolerance time.Duration, now time.Time) error {
+	if timestampHeader == "" || signatureHeader == "" {
+		return ErrMissingSignature
+	}
+
+	timestamp, err := strconv.ParseInt(timestampHeader, 10, 64)
+	if err != nil {
+		return ErrInvalidTimestamp
+	}
+	if tolerance <= 0 {
+		tolerance = DefaultTolerance
+	}
+	if diff := now.Sub(time.Unix(timestamp, 0)); diff > tolerance || diff < -tolerance {
+		return ErrTimestampOutOfRange
+	}
+
+	expected := Sign(secret, timestamp, body)
+	// ผู้ส่งบางเจ้าส่งเป็นตัวพิมพ์ใหญ่ หรือส่งมาแค่ hex โดยไม่มี "sha256="
+	signature := strings.ToLower(strings.TrimSpace(signatureHeader))
+	if !strings.HasPrefix(signature, signaturePrefix) {
+		signature = signaturePrefix + signature
+	}
+	if !hmac.Equal([]byte(signature), []byte(expected)) {
+		return ErrSignatureMismatch
+	}
+	return nil
+}
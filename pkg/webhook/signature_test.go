@@ -0,0 +1,79 @@
+package webhook
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVerify(t *testing.T) {
+	const secret = "whsec_test"
+	now := time.Date(2025, 10, 21, 7, 28, 0, 0, time.UTC)
+	body := []byte(`{"event_id":"evt_1","status":"delivered"}`)
+	timestamp := now.Unix()
+	header := strconv.FormatInt(timestamp, 10)
+	valid := Sign(secret, timestamp, body)
+
+	tests := []struct {
+		name      string
+		secret    string
+		timestamp string
+		signature string
+		body      []byte
+		tolerance time.Duration
+		wantErr   error
+	}{
+		{name: "valid signature", secret: secret, timestamp: header, signature: valid, body: body},
+		{name: "upper-case hex", secret: secret, timestamp: header, signature: strings.ToUpper(valid), body: body},
+		{name: "surrounding whitespace", secret: secret, timestamp: header, signature: "  " + valid + " ", body: body},
+		{name: "missing sha256= prefix is accepted", secret: secret, timestamp: header, signature: strings.TrimPrefix(valid, "sha256="), body: body},
+		{name: "other algorithm prefix", secret: secret, timestamp: header, signature: "sha1=" + strings.TrimPrefix(valid, "sha256="), body: body, wantErr: ErrSignatureMismatch},
+		{name: "tampered body", secret: secret, timestamp: header, signature: valid, body: []byte(`{"event_id":"evt_1","status":"returned"}`), wantErr: ErrSignatureMismatch},
+		{name: "wrong secret", secret: "whsec_other", timestamp: header, signature: valid, body: body, wantErr: ErrSignatureMismatch},
+		{name: "timestamp changed after signing", secret: secret, timestamp: strconv.FormatInt(timestamp+1, 10), signature: valid, body: body, wantErr: ErrSignatureMismatch},
+		{name: "missing signature", secret: secret, timestamp: header, signature: "", body: body, wantErr: ErrMissingSignature},
+		{name: "missing timestamp", secret: secret, timestamp: "", signature: valid, body: body, wantErr: ErrMissingSignature},
+		{name: "non-numeric timestamp", secret: secret, timestamp: "yesterday", signature: valid, body: body, wantErr: ErrInvalidTimestamp},
+		{name: "fractional timestamp", secret: secret, timestamp: header + ".5", signature: valid, body: body, wantErr: ErrInvalidTimestamp},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Verify(tt.secret, tt.timestamp, tt.signature, tt.body, tt.tolerance, now)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Verify() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVerifyTolerance(t *testing.T) {
+	const secret = "whsec_test"
+	now := time.Date(2025, 10, 21, 7, 28, 0, 0, time.UTC)
+	body := []byte(`{}`)
+
+	tests := []struct {
+		name      string
+		offset    time.Duration // เวลาที่ผู้ส่งเซ็น เทียบกับเวลาของเรา
+		tolerance time.Duration
+		wantErr   error
+	}{
+		{name: "just inside the past limit", offset: -DefaultTolerance, wantErr: nil},
+		{name: "just inside the future limit", offset: DefaultTolerance, wantErr: nil},
+		{name: "too old", offset: -DefaultTolerance - time.Second, wantErr: ErrTimestampOutOfRange},
+		{name: "too far in the future", offset: DefaultTolerance + time.Second, wantErr: ErrTimestampOutOfRange},
+		{name: "zero tolerance falls back to the default", offset: -4 * time.Minute, tolerance: 0, wantErr: nil},
+		{name: "custom tolerance", offset: -2 * time.Minute, tolerance: time.Minute, wantErr: ErrTimestampOutOfRange},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			timestamp := now.Add(tt.offset).Unix()
+			signature := Sign(secret, timestamp, body)
+			err := Verify(secret, strconv.FormatInt(timestamp, 10), signature, body, tt.tolerance, now)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Verify() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}